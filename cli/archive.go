@@ -0,0 +1,150 @@
+/*
+File: archive.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 14:02:37
+
+Description: 参数 '--list-archive' 和 '--extract' 的实现
+*/
+
+package cli
+
+import (
+	"io"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// ListVolumeArchives 输出 volume 存档中的文件树，无需连接 docker service
+//
+// 参数：
+//   - files: 存档文件，允许一次列出多个
+func ListVolumeArchives(files []string) {
+	if len(files) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "volume archive file", "list")
+		return
+	}
+
+	for _, file := range files {
+		entries, err := general.ListArchive(file)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			continue
+		}
+
+		color.Printf("%s %s\n", general.InspectFlag, general.FgBlueText(file))
+		tableHeader := []string{"Mode", "Size", "Modified", "Path"} // 表头
+		tableData := [][]string{}                                   // 表数据
+		for _, entry := range entries {
+			name := entry.Path
+			if entry.Linkname != "" {
+				name = color.Sprintf("%s -> %s", entry.Path, entry.Linkname)
+			}
+			size := ""
+			if !entry.Mode.IsDir() {
+				size = general.HumanSize(entry.Size)
+			}
+			tableData = append(tableData, []string{entry.Mode.String(), size, entry.ModTime.Local().Format("2006-01-02 15:04:05"), name})
+		}
+		color.Println(general.NewTable(tableHeader, tableData))
+	}
+}
+
+// ExtractVolumeArchive 从 volume 存档中提取指定路径到本地文件夹或已存在的 volume
+//
+//   - 指定路径时同时提取其下的所有内容，未指定路径时提取全部
+//   - 提取到 volume 时保留所有者和权限，同名文件会被覆盖
+//
+// 参数：
+//   - files: 存档文件，允许一次提取多个
+//   - paths: 存档中的路径
+//   - toDir: 目标文件夹
+//   - toVolume: 目标 volume，与 toDir 二选一
+func ExtractVolumeArchive(files []string, paths []string, toDir string, toVolume string) {
+	if len(files) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "volume archive file", "extract")
+		return
+	}
+	if (toDir == "") == (toVolume == "") {
+		color.Printf(general.DangerText(general.SpecifyMessage), "either a target folder (--to-dir) or a target volume (--to-volume)", "extract to")
+		return
+	}
+
+	target := toDir
+	if toVolume != "" {
+		target = toVolume
+		volumes, err := general.ListVolumes()
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		exist := false
+		for _, volume := range volumes.Volumes {
+			if volume.Name == toVolume {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			color.Printf("%s Extract -> %s: %s\n", general.LoadFlag, general.FgBlueText(toVolume), general.DangerText(general.NoSuchVolumeMessage))
+			return
+		}
+	}
+
+	selector := general.PathSelector(paths)
+	for _, file := range files {
+		// 统计提取的条目数
+		count := 0
+		keep := func(name string) bool {
+			if name != "" && selector(name) {
+				count++
+				return true
+			}
+			return false
+		}
+
+		if err := extractArchive(file, keep, toDir, toVolume); err != nil {
+			color.Printf("%s Extract %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(err))
+			continue
+		}
+		if count == 0 {
+			color.Printf("%s Extract %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(general.NoSuchPathMessage))
+			continue
+		}
+		color.Printf("%s Extract %s -> %s (%d entries)\n", general.LoadFlag, general.FgBlueText(file), general.FgMagentaText(target), count)
+	}
+}
+
+// extractArchive 将存档中符合条件的条目解包到文件夹或 volume
+//
+// 参数：
+//   - file: 存档文件
+//   - keep: 判断条目是否需要解包
+//   - toDir: 目标文件夹
+//   - toVolume: 目标 volume，不为空时优先
+//
+// 返回：
+//   - 错误信息
+func extractArchive(file string, keep func(name string) bool, toDir string, toVolume string) error {
+	reader, err := general.OpenArchive(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if toVolume == "" {
+		return general.ExtractTarFiltered(reader, toDir, keep)
+	}
+
+	// 边过滤边传给 docker service
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(general.FilterTar(reader, pipeWriter, keep))
+	}()
+	err = general.CopyToVolume(toVolume, pipeReader)
+	pipeReader.CloseWithError(err)
+	return err
+}
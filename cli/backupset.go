@@ -0,0 +1,89 @@
+/*
+File: backupset.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 20:03:26
+
+Description: 保存 image 和 volume 时使用的备份集
+*/
+
+package cli
+
+import (
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// 备份集类型
+const (
+	backupSetImage  = "image"  // image 备份集
+	backupSetVolume = "volume" // volume 备份集
+)
+
+// SetOption 备份集选项
+type SetOption struct {
+	Enabled   bool                    // 是否将本次运行的存档保存到带时间戳的备份集中
+	Retention general.RetentionPolicy // 本次运行成功后应用的保留策略
+
+	RequireMatch bool // 每个名称或模式都必须选中对象，否则视为失败，不完成备份集也不执行保留策略
+}
+
+// startBackupSet 返回本次运行保存存档的文件夹在存储中的路径
+//
+//   - 启用备份集时，在存储中创建未完成的备份集文件夹
+//   - 未启用时为空，即直接保存到存储中
+//
+// 参数：
+//   - storage: 保存存档的存储
+//   - kind: 备份集类型
+//   - option: 备份集选项
+//
+// 返回：
+//   - 保存存档的文件夹在存储中的路径
+//   - 错误信息
+func startBackupSet(storage general.Storage, kind string, option SetOption) (string, error) {
+	if !option.Enabled {
+		return "", nil
+	}
+	return general.NewBackupSet(storage, kind)
+}
+
+// finishBackupSet 在本次运行成功后完成备份集，并按保留策略删除旧的备份集
+//
+// 参数：
+//   - storage: 保存存档的存储
+//   - setName: 未完成的备份集文件夹在存储中的路径
+//   - kind: 备份集类型
+//   - option: 备份集选项
+//
+// 返回：
+//   - 是否成功完成备份集，删除旧备份集失败不影响结果
+func finishBackupSet(storage general.Storage, setName string, kind string, option SetOption) bool {
+	if !option.Enabled {
+		return true
+	}
+
+	completePath, err := general.CompleteBackupSet(storage, setName)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+	color.Printf("%s Set -> %s\n", general.PackFlag, general.FgMagentaText(completePath))
+
+	sets, err := general.ListBackupSets(storage, kind)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return true
+	}
+	for _, set := range general.ExpiredBackupSets(sets, option.Retention) {
+		if err := storage.Remove(set.Name); err != nil {
+			color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(set.Name), general.DangerText(err))
+			continue
+		}
+		color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(set.Name), general.FgMagentaText(general.RemovedMessage))
+	}
+
+	return true
+}
@@ -0,0 +1,237 @@
+/*
+File: catalog.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 22:31:08
+
+Description: 子命令 'catalog' 的实现
+*/
+
+package cli
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// recordCatalog 将记录写入目录，写入失败不影响保存和加载的结果
+//
+// 参数：
+//   - records: 记录
+func recordCatalog(records []general.CatalogRecord) {
+	if err := general.AddCatalogRecords(records); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+	}
+}
+
+// catalogSaves 将本次运行保存的存档写入目录
+//
+//   - 备份集已完成时，存档位置改为完成后的备份集文件夹中的位置
+//
+// 参数：
+//   - records: 记录
+//   - storage: 保存存档的存储
+//   - saveDir: 本次运行保存存档的文件夹在存储中的路径
+//   - setOption: 备份集选项
+//   - completed: 备份集是否已完成
+func catalogSaves(records []general.CatalogRecord, storage general.Storage, saveDir string, setOption SetOption, completed bool) {
+	if setOption.Enabled && completed {
+		partialPath, completePath := storage.Location(saveDir), storage.Location(general.CompletedSetPath(saveDir))
+		for index := range records {
+			records[index].Archive = completePath + strings.TrimPrefix(records[index].Archive, partialPath)
+		}
+	}
+	recordCatalog(records)
+}
+
+// setArchiveSize 设置存档的大小，分块保存的存档和分卷存档为总大小
+//
+// 参数：
+//   - record: 记录
+//   - storage: 保存存档的存储
+//   - name: 存档在存储中的路径
+func setArchiveSize(record *general.CatalogRecord, storage general.Storage, name string) {
+	if size, err := general.ArchiveSize(storage, name); err == nil {
+		record.Size = size
+	}
+}
+
+// shortDigest 返回用于显示的摘要
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > idMinViewLength {
+		return digest[:idMinViewLength]
+	}
+	return digest
+}
+
+// ListCatalog 输出目录中满足条件的记录
+//
+// 参数：
+//   - filter: 查询条件
+//   - limit: 最多输出的记录数，为 0 时输出全部
+func ListCatalog(filter general.CatalogFilter, limit int) {
+	records, err := general.QueryCatalog(filter)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if len(records) == 0 {
+		color.Printf("%s\n", general.SecondaryText(general.NoCatalogRecordMessage))
+		return
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	tableHeader := []string{"ID", "Time", "Operation", "Kind", "Item", "Digest", "Size", "Duration", "Host", "Result", "Archive"} // 表头
+	tableData := [][]string{}                                                                                                     // 表数据
+	for _, record := range records {
+		result := record.Result
+		if record.Message != "" {
+			result += ": " + record.Message
+		}
+		tableData = append(tableData, []string{
+			color.Sprint(record.ID),
+			record.Time.Format(time.DateTime),
+			record.Operation,
+			record.Kind,
+			record.Item,
+			shortDigest(record.Digest),
+			general.HumanSize(record.Size),
+			record.Duration.Round(time.Millisecond).String(),
+			record.Origin(),
+			result,
+			record.Archive,
+		})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// LatestBackups 输出每个 image 或 volume 最新的可用备份
+//
+//   - 可用备份即保存成功且存档仍在磁盘或远程存储上
+//   - 同一条目在不同 docker service 上的备份分别列出
+//
+// 参数：
+//   - filter: 查询条件
+func LatestBackups(filter general.CatalogFilter) {
+	filter.Operation, filter.Result = general.CatalogSave, general.CatalogSucceeded
+	records, err := general.QueryCatalog(filter)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	checker := general.NewArchiveChecker()
+	defer checker.Close()
+	latest := latestRecords(records, checker.Exists)
+	if len(latest) == 0 {
+		color.Printf("%s\n", general.SecondaryText(general.NoCatalogRecordMessage))
+		return
+	}
+
+	tableHeader := []string{"Kind", "Item", "Time", "Digest", "Size", "Host", "Archive"} // 表头
+	tableData := [][]string{}                                                            // 表数据
+	for _, record := range latest {
+		tableData = append(tableData, []string{
+			record.Kind,
+			record.Item,
+			record.Time.Format(time.DateTime),
+			shortDigest(record.Digest),
+			general.HumanSize(record.Size),
+			record.Origin(),
+			record.Archive,
+		})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// latestRecords 取每个 docker service 上每个条目最新的存档仍存在的记录
+//
+// 参数：
+//   - records: 记录，按时间从新到旧排序
+//   - exists: 判断存档是否仍存在的函数
+//
+// 返回：
+//   - 按类型、条目和 docker service 排序的记录
+func latestRecords(records []general.CatalogRecord, exists func(string) bool) []general.CatalogRecord {
+	var (
+		latest []general.CatalogRecord
+		seen   = make(map[string]bool)
+	)
+	for _, record := range records {
+		key := record.Kind + "\x00" + record.Origin() + "\x00" + record.Item
+		if seen[key] || !exists(record.Archive) {
+			continue
+		}
+		seen[key] = true
+		latest = append(latest, record)
+	}
+	sort.SliceStable(latest, func(i, j int) bool {
+		if latest[i].Kind != latest[j].Kind {
+			return latest[i].Kind < latest[j].Kind
+		}
+		if latest[i].Item != latest[j].Item {
+			return latest[i].Item < latest[j].Item
+		}
+		return latest[i].Origin() < latest[j].Origin()
+	})
+	return latest
+}
+
+// MissingArchives 输出目录中存档已不在磁盘或远程存储上的记录
+//
+// 参数：
+//   - filter: 查询条件
+//   - forget: 是否从目录中删除这些存档的保存记录
+func MissingArchives(filter general.CatalogFilter, forget bool) {
+	records, err := general.QueryCatalog(filter)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	missing := general.MissingArchives(records)
+	if len(missing) == 0 {
+		color.Printf("%s\n", general.SuccessText(general.NoMissingArchiveMessage))
+		return
+	}
+
+	if !forget {
+		tableHeader := []string{"ID", "Time", "Kind", "Item", "Host", "Archive"} // 表头
+		tableData := [][]string{}                                                // 表数据
+		for _, record := range missing {
+			tableData = append(tableData, []string{color.Sprint(record.ID), record.Time.Format(time.DateTime), record.Kind, record.Item, record.Origin(), record.Archive})
+		}
+		color.Println(general.NewTable(tableHeader, tableData))
+		return
+	}
+
+	// 删除指向已缺失存档的所有保存记录
+	missingArchives := make(map[string]bool)
+	for _, record := range missing {
+		missingArchives[record.Archive] = true
+	}
+	var ids []uint64
+	for _, record := range records {
+		if record.Operation == general.CatalogSave && missingArchives[record.Archive] {
+			ids = append(ids, record.ID)
+		}
+	}
+	if err := general.RemoveCatalogRecords(ids); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	for _, record := range missing {
+		color.Printf("%s Forget %s -> %s\n", general.RemoveFlag, general.FgBlueText(record.Archive), general.FgMagentaText(general.RemovedMessage))
+	}
+}
@@ -0,0 +1,44 @@
+/*
+File: catalog_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 13:52:17
+
+Description: 目录查询测试
+*/
+
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yhyj/wocker/general"
+)
+
+func TestLatestRecords(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2026, 10, 20, hour, 0, 0, 0, time.Local)
+	}
+	const socket = "unix:///var/run/docker.sock"
+	// 按时间从新到旧排序，两台主机共享同一目录
+	records := []general.CatalogRecord{
+		{Time: at(12), Kind: "volume", Item: "data", Host: socket, Machine: "web", Archive: "/backup/web/data-3"},
+		{Time: at(11), Kind: "volume", Item: "data", Host: socket, Machine: "db", Archive: "/backup/db/data-2"},
+		{Time: at(10), Kind: "volume", Item: "data", Host: socket, Machine: "web", Archive: "/backup/web/data-1"},
+		{Time: at(9), Kind: "volume", Item: "data", Host: "tcp://10.0.0.5:2376", Machine: "web", Archive: "/backup/remote/data-0"},
+		{Time: at(8), Kind: "image", Item: "app:1.0", Host: socket, Machine: "db", Archive: "/backup/db/app-missing"},
+	}
+	exists := func(archive string) bool { return archive != "/backup/db/app-missing" }
+
+	latest := latestRecords(records, exists)
+	want := []string{"/backup/db/data-2", "/backup/remote/data-0", "/backup/web/data-3"}
+	if len(latest) != len(want) {
+		t.Fatalf("latestRecords returned %d records, want %d: %+v", len(latest), len(want), latest)
+	}
+	for index, record := range latest {
+		if record.Archive != want[index] {
+			t.Errorf("latestRecords[%d] = %s, want %s", index, record.Archive, want[index])
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+File: clone.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 14:41:26
+
+Description: 参数 '--clone' 和 '--rename' 的实现
+*/
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// CloneVolume 将 volume 复制到新 volume，保留驱动、标签和选项
+//
+//   - 复制完成后校验两者内容一致
+//   - 重命名时，源 volume 被任何容器使用则拒绝操作，校验通过后删除源 volume
+//
+// 参数：
+//   - args: 源 volume 名和新 volume 名
+//   - rename: 是否为重命名
+func CloneVolume(args []string, rename bool) {
+	action := "Clone"
+	if rename {
+		action = "Rename"
+	}
+	if len(args) != 2 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "source volume and new volume", strings.ToLower(action))
+		return
+	}
+	sourceVolume, targetVolume := args[0], args[1]
+
+	source, err := general.InspectVolume(sourceVolume)
+	if err != nil {
+		color.Printf("%s %s %s -> %s\n", general.CloneFlag, action, general.FgBlueText(sourceVolume), general.DangerText(general.NoSuchVolumeMessage))
+		return
+	}
+	if _, err := general.InspectVolume(targetVolume); err == nil {
+		color.Printf("%s %s %s -> %s\n", general.CloneFlag, action, general.FgBlueText(targetVolume), general.DangerText(general.VolumeExistMessage))
+		return
+	}
+
+	// 重命名会删除源 volume，必须确保没有容器在使用
+	if rename {
+		users, err := general.VolumeUsers()
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		if containers := users[sourceVolume]; len(containers) > 0 {
+			color.Printf("%s %s %s -> %s\n", general.CloneFlag, action, general.FgBlueText(sourceVolume), general.DangerText(color.Sprintf(general.VolumeInUseMessage, strings.Join(containers, ", "))))
+			return
+		}
+	}
+
+	if err := general.CreateVolumeLike(targetVolume, source); err != nil {
+		color.Printf("%s %s %s -> %s\n", general.CloneFlag, action, general.FgBlueText(sourceVolume), general.DangerText(err))
+		return
+	}
+
+	// 复制并校验，失败时删除新建的 volume，源 volume 保持不变
+	err = general.CopyVolume(sourceVolume, targetVolume)
+	if err == nil {
+		err = general.VerifyVolumeCopy(sourceVolume, targetVolume)
+	}
+	if err != nil {
+		color.Printf("%s %s %s -> %s\n", general.CloneFlag, action, general.FgBlueText(sourceVolume), general.DangerText(err))
+		if err := general.RemoveVolume(targetVolume); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		}
+		return
+	}
+
+	if rename {
+		if err := general.RemoveVolume(sourceVolume); err != nil {
+			color.Printf("%s %s %s -> %s\n", general.CloneFlag, action, general.FgBlueText(sourceVolume), general.DangerText(err))
+			return
+		}
+	}
+
+	color.Printf("%s %s %s -> %s\n", general.CloneFlag, action, general.FgBlueText(sourceVolume), general.FgMagentaText(targetVolume))
+}
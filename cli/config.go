@@ -0,0 +1,120 @@
+/*
+File: config.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 18:32:40
+
+Description: 子命令 'config' 的实现
+*/
+
+package cli
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// ApplySettings 将当前生效的配置应用到显示和命名规则
+func ApplySettings() {
+	idMinViewLength = general.CurrentSettings.IDLength
+	identity = general.CurrentSettings.VolumeIdentity
+	archiveFileExtension = general.CurrentSettings.VolumeExtension
+}
+
+// outputStorage 打开保存存档的存储，输出位置是本地文件夹时不存在则创建
+//
+// 返回：
+//   - 存储，使用完毕后需要关闭
+//   - 错误信息
+func outputStorage() (general.Storage, error) {
+	return general.OpenStorage(general.CurrentSettings.OutputDir)
+}
+
+// reportNameCollisions 输出会写入同一存档文件的条目
+//
+// 参数：
+//   - items: 条目名称
+//   - files: 条目将要写入的存档文件，与 items 按顺序一一对应
+//
+// 返回：
+//   - 存在重复时返回 true
+func reportNameCollisions(items []string, files []string) bool {
+	collisions := general.NameCollisions(items, files)
+	if len(collisions) == 0 {
+		return false
+	}
+	var collidedFiles []string
+	for file := range collisions {
+		collidedFiles = append(collidedFiles, file)
+	}
+	sort.Strings(collidedFiles)
+	for _, file := range collidedFiles {
+		color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(strings.Join(collisions[file], ", ")), general.DangerText(color.Sprintf(general.NameCollisionMessage, file)))
+	}
+	return true
+}
+
+// CreateConfig 创建带有默认 profile 的配置文件
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - force: 配置文件已存在时是否覆盖
+func CreateConfig(configFile string, force bool) {
+	if err := general.CreateConfig(configFile, force); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("Create %s: %s\n", general.FgBlueText(configFile), general.SuccessText("created"))
+}
+
+// PrintConfig 输出配置文件内容和生效的配置项及其来源
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - profileName: profile 名，为空时按优先级选择
+func PrintConfig(configFile string, profileName string) {
+	content, err := os.ReadFile(configFile)
+	if err == nil {
+		color.Printf("%s\n%s\n", general.FgBlueText(configFile), string(content))
+	} else {
+		color.Printf("%s: %s\n", general.FgBlueText(configFile), general.SecondaryText("not found, using defaults"))
+	}
+
+	items, err := general.LoadSettings(configFile, profileName)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	tableHeader := []string{"Key", "Value", "Source"} // 表头
+	tableData := [][]string{}                         // 表数据
+	for _, item := range items {
+		tableData = append(tableData, []string{item.Key, item.Value, item.Source})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// CheckConfig 检查配置文件并输出发现的问题
+//
+// 参数：
+//   - configFile: 配置文件路径
+func CheckConfig(configFile string) {
+	if !general.FileExist(configFile) {
+		color.Printf("%s: %s\n", general.FgBlueText(configFile), general.SecondaryText("not found, using defaults"))
+		return
+	}
+
+	problems := general.CheckConfig(configFile)
+	if len(problems) == 0 {
+		color.Printf("%s: %s\n", general.FgBlueText(configFile), general.SuccessText(general.ConfigValidMessage))
+		return
+	}
+	for _, problem := range problems {
+		color.Printf("%s %s\n", general.DangerText(general.ErrorInfoFlag), problem)
+	}
+}
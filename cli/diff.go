@@ -0,0 +1,275 @@
+/*
+File: diff.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:31:48
+
+Description: 子命令 'diff' 的实现
+*/
+
+package cli
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/opencontainers/go-digest"
+	"github.com/yhyj/wocker/general"
+)
+
+// DiffImages 比较两个 image 的层、配置和文件系统
+//
+// 参数：
+//   - args: 原 image 和新 image，均允许是本地 image 的引用、ID 或 docker save 存档文件
+//   - summary: 只输出文件变化的统计，不列出每个文件
+func DiffImages(args []string, summary bool) {
+	if len(args) != 2 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "two images or image archives", "compare")
+		return
+	}
+
+	var snapshots [2]general.ImageSnapshot
+	for index, source := range args {
+		snapshot, err := general.SnapshotImage(source)
+		if err != nil {
+			color.Printf("%s Diff %s -> %s\n", general.InspectFlag, general.FgBlueText(source), general.DangerText(err))
+			return
+		}
+		snapshots[index] = snapshot
+	}
+	oldImage, newImage := snapshots[0], snapshots[1]
+
+	color.Printf("%s Diff %s -> %s\n", general.InspectFlag, general.FgBlueText(oldImage.Name), general.FgMagentaText(newImage.Name))
+
+	printLayerDiff(oldImage.Layers, newImage.Layers)
+	printConfigDiff(oldImage, newImage)
+
+	changes := general.DiffFiles(oldImage.Files, newImage.Files)
+	printFileChanges(changes, summary)
+}
+
+// shortDiffID 返回 diff ID 的短格式
+func shortDiffID(diffID string) string {
+	if diffID == "" {
+		return ""
+	}
+	return digest.Digest(diffID).Encoded()[:idMinViewLength]
+}
+
+// printLayerDiff 按位置对齐并输出两个 image 的层
+//
+// 参数：
+//   - oldLayers: 原 image 的层
+//   - newLayers: 新 image 的层
+func printLayerDiff(oldLayers []general.ArchiveLayer, newLayers []general.ArchiveLayer) {
+	tableHeader := []string{"#", "Old Layer", "New Layer", "Status", "Size", "Created By"} // 表头
+	tableData := [][]string{}                                                              // 表数据
+	for index := 0; index < len(oldLayers) || index < len(newLayers); index++ {
+		var oldLayer, newLayer general.ArchiveLayer
+		if index < len(oldLayers) {
+			oldLayer = oldLayers[index]
+		}
+		if index < len(newLayers) {
+			newLayer = newLayers[index]
+		}
+
+		status, layer := "Same", newLayer
+		switch {
+		case index >= len(oldLayers):
+			status = general.ChangeAdded
+		case index >= len(newLayers):
+			status, layer = general.ChangeRemoved, oldLayer
+		case oldLayer.DiffID != newLayer.DiffID:
+			status = "Changed"
+		}
+		tableData = append(tableData, []string{color.Sprint(index + 1), shortDiffID(oldLayer.DiffID), shortDiffID(newLayer.DiffID), status, general.HumanSize(layer.Size), truncateText(layer.CreatedBy, 60)})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// diffStrings 比较两个字符串集合
+//
+// 参数：
+//   - oldItems: 原集合
+//   - newItems: 新集合
+//
+// 返回：
+//   - 只存在于原集合的元素，已排序
+//   - 只存在于新集合的元素，已排序
+func diffStrings(oldItems []string, newItems []string) ([]string, []string) {
+	var removed, added []string
+	for _, item := range oldItems {
+		if !general.SliceContains(newItems, item) {
+			removed = append(removed, item)
+		}
+	}
+	for _, item := range newItems {
+		if !general.SliceContains(oldItems, item) {
+			added = append(added, item)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return removed, added
+}
+
+// printConfigDiff 输出两个 image 配置中不同的字段
+//
+//   - 列表类字段只输出不同的元素
+//
+// 参数：
+//   - oldImage: 原 image
+//   - newImage: 新 image
+func printConfigDiff(oldImage general.ImageSnapshot, newImage general.ImageSnapshot) {
+	platform := func(snapshot general.ImageSnapshot) string {
+		value := snapshot.Config.OS + "/" + snapshot.Config.Architecture
+		if snapshot.Config.Variant != "" {
+			value += "/" + snapshot.Config.Variant
+		}
+		return value
+	}
+	labels := func(snapshot general.ImageSnapshot) []string {
+		var items []string
+		for key, value := range snapshot.Config.Config.Labels {
+			items = append(items, key+"="+value)
+		}
+		return items
+	}
+	ports := func(snapshot general.ImageSnapshot) []string {
+		var items []string
+		for port := range snapshot.Config.Config.ExposedPorts {
+			items = append(items, port)
+		}
+		return items
+	}
+
+	// 单值字段
+	fields := [][3]string{
+		{"Platform", platform(oldImage), platform(newImage)},
+		{"User", oldImage.Config.Config.User, newImage.Config.Config.User},
+		{"Working Dir", oldImage.Config.Config.WorkingDir, newImage.Config.Config.WorkingDir},
+		{"Entrypoint", strings.Join(oldImage.Config.Config.Entrypoint, " "), strings.Join(newImage.Config.Config.Entrypoint, " ")},
+		{"Cmd", strings.Join(oldImage.Config.Config.Cmd, " "), strings.Join(newImage.Config.Config.Cmd, " ")},
+	}
+	// 列表字段
+	for _, field := range []struct {
+		name     string
+		oldItems []string
+		newItems []string
+	}{
+		{"Env", oldImage.Config.Config.Env, newImage.Config.Config.Env},
+		{"Labels", labels(oldImage), labels(newImage)},
+		{"Exposed Ports", ports(oldImage), ports(newImage)},
+	} {
+		removed, added := diffStrings(field.oldItems, field.newItems)
+		if len(removed) > 0 || len(added) > 0 {
+			fields = append(fields, [3]string{field.name, strings.Join(removed, "\n"), strings.Join(added, "\n")})
+		}
+	}
+
+	tableHeader := []string{"Config", "Old", "New"} // 表头
+	tableData := [][]string{}                       // 表数据
+	for _, field := range fields {
+		if field[1] != field[2] {
+			tableData = append(tableData, []string{field[0], field[1], field[2]})
+		}
+	}
+	if len(tableData) == 0 {
+		color.Printf("%s\n", general.SecondaryText(color.Sprintf(general.IdenticalMessage, "config")))
+		return
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// signedSize 返回带符号的大小变化
+func signedSize(delta int64) string {
+	if delta == 0 {
+		return "0 B"
+	}
+	if delta < 0 {
+		return "-" + strings.TrimSpace(general.HumanSize(-delta))
+	}
+	return "+" + strings.TrimSpace(general.HumanSize(delta))
+}
+
+// printFileChanges 输出文件变化列表及按变化类型汇总的表格
+//
+// 参数：
+//   - changes: 文件变化
+//   - summary: 只输出汇总
+func printFileChanges(changes []general.FileChange, summary bool) {
+	if len(changes) == 0 {
+		color.Printf("%s\n", general.SecondaryText(color.Sprintf(general.IdenticalMessage, "files")))
+		return
+	}
+
+	var (
+		counts = make(map[string]int)   // 各变化类型的文件数
+		deltas = make(map[string]int64) // 各变化类型的大小变化
+	)
+	tableHeader := []string{"Change", "Path", "Old Size", "New Size", "Delta"} // 表头
+	tableData := [][]string{}                                                  // 表数据
+	for _, change := range changes {
+		delta := change.NewSize - change.OldSize
+		counts[change.Change]++
+		deltas[change.Change] += delta
+
+		oldSize, newSize := general.HumanSize(change.OldSize), general.HumanSize(change.NewSize)
+		switch change.Change {
+		case general.ChangeAdded:
+			oldSize = ""
+		case general.ChangeRemoved:
+			newSize = ""
+		}
+		tableData = append(tableData, []string{change.Change, change.Path, oldSize, newSize, signedSize(delta)})
+	}
+	if !summary {
+		color.Println(general.NewTable(tableHeader, tableData))
+	}
+
+	var totalDelta int64
+	tableHeader = []string{"Change", "Files", "Delta"}
+	tableData = [][]string{}
+	for _, change := range []string{general.ChangeAdded, general.ChangeRemoved, general.ChangeModified} {
+		totalDelta += deltas[change]
+		tableData = append(tableData, []string{change, color.Sprint(counts[change]), signedSize(deltas[change])})
+	}
+	tableData = append(tableData, []string{"Total", color.Sprint(len(changes)), signedSize(totalDelta)})
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// DiffVolume 比较 volume 与 volume 存档的内容，即恢复该存档会带来的变化
+//
+//   - 在临时容器中遍历 volume，按路径、类型、大小、权限和内容的 sha256 比较
+//   - Added 表示只存在于存档中，Removed 表示只存在于 volume 中
+//
+// 参数：
+//   - args: volume 名和 volume 存档文件
+//   - summary: 只输出汇总，不列出每个文件
+func DiffVolume(args []string, summary bool) {
+	if len(args) != 2 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "a volume and a volume archive", "compare")
+		return
+	}
+	volumeName, archiveFile := args[0], args[1]
+
+	if _, err := general.InspectVolume(volumeName); err != nil {
+		color.Printf("%s Diff %s -> %s\n", general.InspectFlag, general.FgBlueText(volumeName), general.DangerText(general.NoSuchVolumeMessage))
+		return
+	}
+
+	volumeFiles, err := general.VolumeManifest(volumeName)
+	if err != nil {
+		color.Printf("%s Diff %s -> %s\n", general.InspectFlag, general.FgBlueText(volumeName), general.DangerText(err))
+		return
+	}
+	archiveFiles, err := general.ArchiveFiles(archiveFile)
+	if err != nil {
+		color.Printf("%s Diff %s -> %s\n", general.InspectFlag, general.FgBlueText(archiveFile), general.DangerText(err))
+		return
+	}
+
+	color.Printf("%s Diff %s -> %s\n", general.InspectFlag, general.FgBlueText(volumeName), general.FgMagentaText(archiveFile))
+	printFileChanges(general.DiffFiles(volumeFiles, archiveFiles), summary)
+}
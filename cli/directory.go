@@ -0,0 +1,124 @@
+/*
+File: directory.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:07:52
+
+Description: 参数 '--export' 和 '--import' 的实现
+*/
+
+package cli
+
+import (
+	"io"
+	"path/filepath"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// ExportVolumes 将指定 volumes 的内容导出到本地文件夹，每个 volume 对应 toDir 下的同名文件夹
+//
+//   - 数据经由 docker API 传输，同样适用于远程 docker service
+//
+// 参数：
+//   - names: volume name 或模式，允许一次导出多个
+//   - option: 匹配选项
+//   - toDir: 目标文件夹
+//   - keep: 判断 volume 中的路径是否导出
+func ExportVolumes(names []string, option general.MatchOption, toDir string, keep func(name string) bool) {
+	if len(names) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "volume", "export")
+		return
+	}
+	if toDir == "" {
+		color.Printf(general.DangerText(general.SpecifyMessage), "target folder (--to-dir)", "export to")
+		return
+	}
+
+	// 获取 volume 列表
+	volumes, err := general.ListVolumes()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 获取当前所有 volume 名称
+	var volumeNames []string
+	for _, volume := range volumes.Volumes {
+		volumeNames = append(volumeNames, volume.Name)
+	}
+
+	selectedVolumes, err := selectVolumes(volumeNames, names, option, "Export")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	for _, volumeName := range selectedVolumes {
+		folderPath := filepath.Join(toDir, volumeName)
+		if err := exportVolume(volumeName, folderPath, keep); err != nil {
+			color.Printf("%s Export %s -> %s\n", general.PackFlag, general.FgBlueText(volumeName), general.DangerText(err))
+			continue
+		}
+		color.Printf("%s Export %s -> %s\n", general.PackFlag, general.FgBlueText(volumeName), general.FgMagentaText(folderPath))
+	}
+}
+
+// exportVolume 将 volume 中符合条件的内容解包到本地文件夹
+//
+// 参数：
+//   - volumeName: volume 名
+//   - folderPath: 目标文件夹
+//   - keep: 判断 volume 中的路径是否导出
+//
+// 返回：
+//   - 错误信息
+func exportVolume(volumeName string, folderPath string, keep func(name string) bool) error {
+	content, err := general.CopyFromVolume(volumeName)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	if err := general.CreateFolder(folderPath); err != nil {
+		return err
+	}
+	return general.ExtractTarFiltered(content, folderPath, keep)
+}
+
+// ImportVolume 将本地文件夹的内容导入 volume，volume 不存在时自动创建
+//
+//   - 数据经由 docker API 传输，同样适用于远程 docker service
+//   - 已存在的同名文件会被覆盖
+//
+// 参数：
+//   - args: volume 名
+//   - fromDir: 源文件夹
+//   - keep: 判断文件夹中的路径是否导入
+func ImportVolume(args []string, fromDir string, keep func(name string) bool) {
+	if len(args) != 1 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "one volume", "import into")
+		return
+	}
+	if fromDir == "" {
+		color.Printf(general.DangerText(general.SpecifyMessage), "source folder (--from-dir)", "import from")
+		return
+	}
+	volumeName := args[0]
+
+	// 边打包边传给 docker service
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(general.TarDirectory(fromDir, pipeWriter, keep))
+	}()
+	err := general.CopyToVolume(volumeName, pipeReader)
+	pipeReader.CloseWithError(err)
+	if err != nil {
+		color.Printf("%s Import %s -> %s\n", general.LoadFlag, general.FgBlueText(fromDir), general.DangerText(err))
+		return
+	}
+	color.Printf("%s Import %s -> %s\n", general.LoadFlag, general.FgBlueText(fromDir), general.FgMagentaText(volumeName))
+}
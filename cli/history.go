@@ -0,0 +1,210 @@
+/*
+File: history.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:10:23
+
+Description: 参数 '--inspect' 的实现
+*/
+
+package cli
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/gookit/color"
+	"github.com/opencontainers/go-digest"
+	"github.com/yhyj/wocker/general"
+)
+
+const (
+	largeLayerSize      = 100 * 1000 * 1000 // 超过该大小的层视为大层
+	largeLayerRatio     = 0.5               // 占 image 大小超过该比例的层视为大层
+	largeLayerRatioSize = 10 * 1000 * 1000  // 按比例判断大层时的最小大小，避免小 image 的每层都被标记
+)
+
+// layerRule 可能浪费空间的构建命令
+type layerRule struct {
+	pattern *regexp.Regexp // 匹配构建命令
+	cleanup *regexp.Regexp // 同一命令中出现则不提示，为 nil 时总是提示
+	hint    string         // 提示信息
+}
+
+// layerRules 检查构建命令的规则
+var layerRules = []layerRule{
+	{regexp.MustCompile(`apt-get\s+(-\S+\s+)*install`), regexp.MustCompile(`rm -rf /var/lib/apt/lists`), "apt lists not removed in the same RUN"},
+	{regexp.MustCompile(`apk\s+(-\S+\s+)*add`), regexp.MustCompile(`--no-cache|rm -rf /var/cache/apk`), "apk cache kept, use 'apk add --no-cache'"},
+	{regexp.MustCompile(`(yum|dnf|microdnf)\s+(-\S+\s+)*install`), regexp.MustCompile(`(yum|dnf|microdnf) clean all|rm -rf /var/cache/(yum|dnf)`), "yum/dnf cache not cleaned in the same RUN"},
+	{regexp.MustCompile(`pip3?\s+install`), regexp.MustCompile(`--no-cache-dir|PIP_NO_CACHE_DIR`), "pip cache kept, use 'pip install --no-cache-dir'"},
+	{regexp.MustCompile(`npm\s+(install|ci)`), regexp.MustCompile(`npm cache clean`), "npm cache not cleaned in the same RUN"},
+	{regexp.MustCompile(`(chown|chmod)\s+-R`), nil, "recursive chown/chmod copies files of lower layers, use 'COPY --chown'"},
+	{regexp.MustCompile(`ADD\s+(--\S+\s+)*https?://`), nil, "remote ADD keeps the download in the layer, fetch and clean up in one RUN"},
+}
+
+// layerHints 检查层是否过大或可能浪费空间
+//
+// 参数：
+//   - item: 层的构建历史
+//   - imageSize: image 大小
+//
+// 返回：
+//   - 提示信息
+func layerHints(item image.HistoryResponseItem, imageSize int64) []string {
+	var hints []string
+	if item.Size <= 0 {
+		return hints
+	}
+
+	if item.Size >= largeLayerSize || (item.Size >= largeLayerRatioSize && float64(item.Size) >= float64(imageSize)*largeLayerRatio) {
+		hints = append(hints, color.Sprintf("large layer (%.0f%% of image)", float64(item.Size)*100/float64(imageSize)))
+	}
+	for _, rule := range layerRules {
+		if rule.pattern.MatchString(item.CreatedBy) && (rule.cleanup == nil || !rule.cleanup.MatchString(item.CreatedBy)) {
+			hints = append(hints, rule.hint)
+		}
+	}
+	return hints
+}
+
+// ShowImageDetails 输出 image 的详细信息、构建历史和层，并标记过大或可能浪费空间的层
+//
+// 参数：
+//   - names: image 的 Repository(:Tag)、ID 或模式，允许一次查看多个
+//   - option: 匹配选项
+func ShowImageDetails(names []string, option general.MatchOption) {
+	if len(names) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "image", "inspect")
+		return
+	}
+
+	// 获取 image 列表
+	images, err := general.ListImages()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	selectedImages, err := selectImages(images, names, option, "Inspect")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	for _, selected := range selectedImages {
+		info, err := general.InspectImage(selected.ID)
+		if err != nil {
+			color.Printf("%s Inspect %s -> %s\n", general.InspectFlag, general.FgBlueText(selected.Reference()), general.DangerText(err))
+			continue
+		}
+		history, err := general.ImageHistory(selected.ID)
+		if err != nil {
+			color.Printf("%s Inspect %s -> %s\n", general.InspectFlag, general.FgBlueText(selected.Reference()), general.DangerText(err))
+			continue
+		}
+
+		color.Printf("%s %s\n", general.InspectFlag, general.FgBlueText(selected.Reference()))
+		printImageDetails(info)
+		printImageHistory(history, info.Size)
+		printRootFSLayers(info.RootFS.Layers)
+	}
+}
+
+// printImageDetails 输出 image 的配置
+//
+// 参数：
+//   - info: image 信息
+func printImageDetails(info types.ImageInspect) {
+	platform := info.Os + "/" + info.Architecture
+	if info.Variant != "" {
+		platform += "/" + info.Variant
+	}
+
+	var entrypoint, cmd, env, labels, ports []string
+	var workingDir, user string
+	if config := info.Config; config != nil {
+		entrypoint, cmd, env = config.Entrypoint, config.Cmd, config.Env
+		workingDir, user = config.WorkingDir, config.User
+		for key, value := range config.Labels {
+			labels = append(labels, key+"="+value)
+		}
+		for port := range config.ExposedPorts {
+			ports = append(ports, string(port))
+		}
+	}
+	sort.Strings(labels)
+	sort.Strings(ports)
+
+	created := info.Created
+	if createdTime, err := time.Parse(time.RFC3339Nano, info.Created); err == nil {
+		created = createdTime.Local().Format("2006-01-02 15:04:05")
+	}
+
+	tableHeader := []string{"Field", "Value"} // 表头
+	tableData := [][]string{                  // 表数据
+		{"Tags", strings.Join(info.RepoTags, "\n")},
+		{"Digests", strings.Join(info.RepoDigests, "\n")},
+		{"ID", digest.Digest(info.ID).Encoded()[:idMinViewLength]},
+		{"Platform", platform},
+		{"Created", created},
+		{"Size", strings.TrimSpace(general.HumanSize(info.Size))},
+		{"Entrypoint", strings.Join(entrypoint, " ")},
+		{"Cmd", strings.Join(cmd, " ")},
+		{"Working Dir", workingDir},
+		{"User", user},
+		{"Env", strings.Join(env, "\n")},
+		{"Exposed Ports", strings.Join(ports, ", ")},
+		{"Labels", strings.Join(labels, "\n")},
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// printImageHistory 按构建顺序输出 image 的构建历史，并标记过大或可能浪费空间的层
+//
+// 参数：
+//   - history: 构建历史，从顶层到底层
+//   - imageSize: image 大小
+func printImageHistory(history []image.HistoryResponseItem, imageSize int64) {
+	flagged := 0
+	tableHeader := []string{"#", "Created", "Size", "Created By", "Comment", "Hints"} // 表头
+	tableData := [][]string{}                                                         // 表数据
+	for index := len(history) - 1; index >= 0; index-- {
+		item := history[index]
+		hints := layerHints(item, imageSize)
+		if len(hints) > 0 {
+			flagged++
+		}
+		tableData = append(tableData, []string{
+			color.Sprint(len(history) - index),
+			general.UnixTime2TimeString(item.Created),
+			general.HumanSize(item.Size),
+			truncateText(item.CreatedBy, 60),
+			truncateText(item.Comment, 20),
+			strings.Join(hints, "\n"),
+		})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+
+	if flagged > 0 {
+		color.Printf("%s\n", general.WarnText(color.Sprintf(general.FlaggedLayersMessage, flagged)))
+	}
+}
+
+// printRootFSLayers 输出 image 的层
+//
+// 参数：
+//   - layers: 各层的 diff ID，从底层到顶层
+func printRootFSLayers(layers []string) {
+	tableHeader := []string{"#", "Diff ID"} // 表头
+	tableData := [][]string{}               // 表数据
+	for index, layer := range layers {
+		tableData = append(tableData, []string{color.Sprint(index + 1), digest.Digest(layer).String()})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
@@ -10,39 +10,100 @@ Description: 子命令 'image' 的实现
 package cli
 
 import (
+	"errors"
+	"path"
+	"path/filepath"
 	"strings"
 
-	"github.com/charmbracelet/lipgloss"
-	"github.com/charmbracelet/lipgloss/table"
+	"github.com/docker/docker/api/types/image"
 	"github.com/gookit/color"
 	"github.com/yhyj/wocker/general"
 )
 
+// idMinViewLength 用于显示 image ID 的字符串的最小长度，由配置项 id_length 设置
+var idMinViewLength = 12
+
+const (
+	imageArchiveExtension     = ".dockerimage" // docker save 格式存档文件扩展名
+	ociLayoutExtension        = ".oci"         // OCI image layout 文件夹扩展名
+	ociLayoutArchiveExtension = ".oci.tar"     // OCI image layout 存档文件扩展名
+)
+
+// image 存档格式
 const (
-	idMinViewLength = 12 // 用于显示 image ID 的字符串的最小长度
+	ImageFormatDocker     = "docker"      // docker save 格式
+	ImageFormatOCI        = "oci"         // OCI image layout 文件夹
+	ImageFormatOCIArchive = "oci-archive" // OCI image layout tar 存档
 )
 
 // ListImages 输出所有 image 的信息
-func ListImages() {
+//
+//   - 指定多个 context 时汇总输出，并增加 Host 列
+//
+// 参数：
+//   - contexts: docker context 名，为空时使用当前的 docker service
+func ListImages(contexts []string) {
+	tableHeader := []string{"Repository", "Tag", "ID", "Platform", "Created", "Size"} // 表头
+	tableData := [][]string{}                                                         // 表数据
+
+	if len(contexts) == 0 {
+		rows, err := imageRows()
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		tableData = rows
+	} else {
+		tableHeader = append([]string{"Host"}, tableHeader...)
+		for _, contextName := range contexts {
+			err := general.WithContext(contextName, func() error {
+				rows, err := imageRows()
+				for _, row := range rows {
+					tableData = append(tableData, append([]string{contextName}, row...))
+				}
+				return err
+			})
+			if err != nil {
+				color.Printf("%s %s: %s\n", general.DangerText(general.ErrorInfoFlag), general.FgBlueText(contextName), err)
+			}
+		}
+	}
+
+	dataTable := general.NewTable(tableHeader, tableData) // 创建一个表格
+
+	color.Println(dataTable)
+}
+
+// imageRows 获取当前 docker service 中所有 image 的表格行数据
+//
+// 返回：
+//   - 行数据切片，列为 Repository、Tag、ID、Platform、Created、Size
+//   - 错误信息
+func imageRows() ([][]string, error) {
 	// 获取 image 列表
 	images, err := general.ListImages()
 	if err != nil {
-		fileName, lineNo := general.GetCallerInfo()
-		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-		return
+		return nil, err
+	}
+
+	// 获取每个 image 包含的平台
+	platforms, err := general.ListImagePlatforms()
+	if err != nil {
+		return nil, err
 	}
 
 	var (
-		imageRepo    string
-		imageTag     string
-		imageID      string
-		imageCreated string
-		imageSize    string
+		imageRepo     string
+		imageTag      string
+		imageID       string
+		imagePlatform string
+		imageCreated  string
+		imageSize     string
 	)
 
-	tableHeader := []string{"Repository", "Tag", "ID", "Created", "Size"} // 表头
-	tableData := [][]string{}                                             // 表数据
-	rowData := []string{}                                                 // 行数据
+	tableData := [][]string{} // 表数据
+	rowData := []string{}     // 行数据
 	for _, image := range images {
 		// 处理原始数据
 		imageRepoTag := func() []string { // image Repository and Tag
@@ -57,30 +118,15 @@ func ListImages() {
 		imageRepo = imageRepoTag[0]
 		imageTag = imageRepoTag[1]
 		imageID = id[1][:idMinViewLength]
+		imagePlatform = strings.Join(platforms[image.ID], "\n")
 		imageCreated = general.UnixTime2TimeString(image.Created)
 		imageSize = color.Sprintf("%6.1f %s", originalSize, sizeUnit)
 		// 组装行数据
-		rowData = []string{imageRepo, imageTag, imageID, imageCreated, imageSize}
+		rowData = []string{imageRepo, imageTag, imageID, imagePlatform, imageCreated, imageSize}
 		tableData = append(tableData, rowData)
 	}
 
-	dataTable := table.New()                                // 创建一个表格
-	dataTable.Border(lipgloss.RoundedBorder())              // 设置表格边框
-	dataTable.BorderStyle(general.BorderStyle)              // 设置表格边框样式
-	dataTable.StyleFunc(func(row, col int) lipgloss.Style { // 按位置设置单元格样式
-		var style lipgloss.Style
-
-		if row == 0 {
-			return general.HeaderStyle // 第一行为表头
-		}
-
-		return style
-	})
-
-	dataTable.Headers(tableHeader...) // 设置表头
-	dataTable.Rows(tableData...)      // 设置单元格
-
-	color.Println(dataTable)
+	return tableData, nil
 }
 
 // image 信息
@@ -93,177 +139,451 @@ type ImageInfo struct {
 // image 保存信息
 type SaveInfo struct {
 	Name string
+	ID   string
 	File string
 }
 
-// SaveImages 将指定 images 保存到各自存档文件
+// newImageInfo 从 image 摘要中提取 Repository、Tag 和 ID
 //
 // 参数：
-//   - names: image 的 Repository 或 ID，允许一次保存多个
-func SaveImages(names []string) {
-	if len(names) == 0 {
-		color.Printf(general.DangerText(general.SpecifyMessage), "image", "save")
-		return
+//   - summary: image 摘要
+//
+// 返回：
+//   - image 信息
+func newImageInfo(summary image.Summary) ImageInfo {
+	var imageInfo ImageInfo
+	if len(summary.RepoTags) != 0 && summary.RepoTags[0] != "<none>:<none>" {
+		imageInfo.Repo, imageInfo.Tag = general.SplitRepoTag(summary.RepoTags[0])
 	}
+	imageInfo.ID = strings.TrimPrefix(summary.ID, "sha256:") // image ID without 'sha256' prefix
+	return imageInfo
+}
 
-	// 获取 image 列表
-	images, err := general.ListImages()
+// Reference 返回 image 的引用，没有 Repository 时返回 ID 的前 idMinViewLength 位
+func (info ImageInfo) Reference() string {
+	if info.Repo == "" {
+		return info.ID[:idMinViewLength]
+	}
+	return color.Sprintf("%s:%s", info.Repo, info.Tag)
+}
+
+// archiveFile 返回 image 的存档文件名
+func (info ImageInfo) archiveFile() string {
+	if info.Repo == "" {
+		// 将 ID 前 idMinViewLength 位做为存储文件名
+		return color.Sprintf("%s%s", info.ID[:idMinViewLength], imageArchiveExtension)
+	}
+	// 将 image Repository 中的 '/' 替换为 '-'，再与 Tag 以及 ID 前 idMinViewLength 位以 '_' 拼接做为存储文件名
+	return color.Sprintf("%s_%s_%s%s", strings.ReplaceAll(info.Repo, "/", "-"), info.Tag, info.ID[:idMinViewLength], imageArchiveExtension)
+}
+
+// archiveName 按配置项 image_name_template 返回 image 的存档文件名
+//
+//   - 未设置模板时使用 archiveFile 的命名方式
+//   - 没有 Repository 和 Tag 的 image 以 'none' 代替，未指定平台时 Platform 为 'default'，避免生成以 '/' 开头的路径
+//   - 模板生成的文件名不以 extension 结尾时追加 extension
+//
+// 参数：
+//   - platform: 保存的平台，未指定时为空
+//   - extension: 存档格式对应的扩展名
+//
+// 返回：
+//   - 相对于输出文件夹的存档文件路径
+//   - 错误信息
+func (info ImageInfo) archiveName(platform string, extension string) (string, error) {
+	nameTemplate := general.CurrentSettings.ImageTemplate
+	if nameTemplate == "" {
+		return strings.TrimSuffix(info.archiveFile(), imageArchiveExtension) + extension, nil
+	}
+
+	data := general.ImageNameData{
+		Repo:     general.SanitizeFileName(general.NameField(info.Repo, "none")),
+		Tag:      general.SanitizeFileName(general.NameField(info.Tag, "none")),
+		ID:       info.ID[:idMinViewLength],
+		Platform: general.SanitizeFileName(general.NameField(platform, "default")),
+		Date:     general.GetCurrentTimestamp("20060102"),
+		Time:     general.GetCurrentTimestamp("150405"),
+	}
+	name, err := general.RenderName(nameTemplate, data)
 	if err != nil {
-		fileName, lineNo := general.GetCallerInfo()
-		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-		return
+		return "", err
+	}
+	if !strings.HasSuffix(name, extension) {
+		name += extension
+	}
+	return name, nil
+}
+
+// matchImage 判断 image 是否匹配指定名称
+//
+//   - 正则表达式模式下仅匹配 'Repository:Tag'，不匹配 ID，以免短模式误中任意 ID
+//   - 通配符模式下，名称包含 ':' 时匹配 'Repository:Tag'，否则匹配 Repository
+//   - 其他情况下，名称包含 ':' 时严格匹配 'Repository:Tag'，否则匹配 Repository 或 ID 前缀
+//
+// 参数：
+//   - info: image 信息
+//   - name: 名称或模式
+//   - regex: 是否将 name 视为正则表达式
+//
+// 返回：
+//   - 是否匹配
+//   - 是否仅通过 ID 前缀匹配
+//   - 错误信息
+func matchImage(info ImageInfo, name string, regex bool) (bool, bool, error) {
+	repoTag := color.Sprintf("%s:%s", info.Repo, info.Tag)
+
+	if regex {
+		if info.Repo == "" {
+			return false, false, nil
+		}
+		matched, err := general.MatchName(name, repoTag, true)
+		return matched, false, err
+	}
+
+	if general.IsGlobPattern(name) {
+		if info.Repo == "" {
+			return false, false, nil
+		}
+		target := info.Repo
+		if strings.Contains(name, ":") {
+			target = repoTag
+		}
+		matched, err := general.MatchName(name, target, false)
+		return matched, false, err
 	}
 
-	// 存档文件名及其组成部分
+	if nameRepo, nameTag := general.SplitRepoTag(name); nameTag != "" { // name 是 image Repository:Tag，严格匹配 Repository 和 Tag 都符合的 image
+		return info.Repo == nameRepo && info.Tag == nameTag, false, nil
+	}
+	if info.Repo != "" && info.Repo == name { // 匹配到一致的 Repository
+		return true, false, nil
+	}
+	if strings.HasPrefix(info.ID, name) { // 匹配到 ID 前缀
+		return true, true, nil
+	}
+	return false, false, nil
+}
+
+// excludeImage 判断 image 是否被排除
+//
+// 参数：
+//   - info: image 信息
+//   - option: 匹配选项
+//
+// 返回：
+//   - 是否被排除
+//   - 错误信息
+func excludeImage(info ImageInfo, option general.MatchOption) (bool, error) {
+	for _, pattern := range option.Exclude {
+		matched, _, err := matchImage(info, pattern, option.Regex)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// selectImages 根据名称或模式从 image 列表中选出匹配的 image
+//
+// 参数：
+//   - images: image 列表
+//   - names: image 的 Repository(:Tag)、ID、模式或 'all'
+//   - option: 匹配选项
+//   - action: 当前操作名，用于输出信息
+//
+// 返回：
+//   - 匹配成功且未被排除的 image 信息切片，已去重
+//   - 错误信息
+func selectImages(images []image.Summary, names []string, option general.MatchOption, action string) ([]ImageInfo, error) {
+	selected, _, err := selectImagesByName(images, names, option, action)
+	return selected, err
+}
+
+// selectImagesByName 根据名称或模式从 image 列表中选出匹配的 image，同时返回没有选中 image 的名称
+//
+// 参数：
+//   - images: image 列表
+//   - names: image 的 Repository(:Tag)、ID、模式或 'all'
+//   - option: 匹配选项
+//   - action: 当前操作名，用于输出信息
+//
+// 返回：
+//   - 匹配成功且未被排除的 image 信息切片，已去重
+//   - 没有匹配到 image 或 ID 前缀有歧义的名称
+//   - 错误信息
+func selectImagesByName(images []image.Summary, names []string, option general.MatchOption, action string) ([]ImageInfo, []string, error) {
 	var (
-		imageRepo        string
-		imageTag         string
-		imageID          string
-		imageArchiveFile string
+		selected  []ImageInfo                // 选中的 image 信息切片
+		unmatched []string                   // 没有选中 image 的名称
+		seen      = make(map[ImageInfo]bool) // 已选中的 image 信息
 	)
 
-	// 参数 names 允许是 image 的 Repository(:Tag), ID 或 'all'
-	if general.SliceContains(names, "all") { // 参数中包含 'all'，将所有 image 保存到各自存档文件
+	// 参数中包含 'all'，选中所有 image
+	if general.SliceContains(names, "all") {
+		names = []string{"*"}
+		option.Regex = false
+	}
+
+	for _, name := range names {
+		var (
+			matchingImages []ImageInfo // 匹配成功的 image 信息切片
+			prefixOnly     = true      // 是否都是仅通过 ID 前缀匹配的
+		)
+
+		// 遍历 image 列表，查找与 name 对应的 image
 		for _, image := range images {
-			imageSplit := func() []string { // image Repository and Tag
-				if len(image.RepoTags) == 0 {
-					return []string{"", ""}
-				}
-				return strings.Split(image.RepoTags[0], ":")
-			}()
-			imageRepo = imageSplit[0]                 // image Repository
-			imageTag = imageSplit[1]                  // image Tag
-			imageID = strings.Split(image.ID, ":")[1] // image ID without 'sha256' prefix
-			// 将 image Repository 中的 '/' 替换为 '-'，再与 Tag 以及 ID 前 idMinViewLength 位以 '_' 拼接做为存储文件名
-			imageArchiveFile = color.Sprintf("%s_%s_%s.dockerimage", strings.ReplaceAll(imageRepo, "/", "-"), imageTag, imageID[:idMinViewLength])
-
-			// 保存 image
-			err = general.SaveImage(imageID, imageArchiveFile)
+			imageInfo := newImageInfo(image)
+			if name == "*" { // 'all' 包含没有 Repository 的 image
+				matchingImages = append(matchingImages, imageInfo)
+				prefixOnly = false
+				continue
+			}
+			matched, byPrefix, err := matchImage(imageInfo, name, option.Regex)
 			if err != nil {
-				fileName, lineNo := general.GetCallerInfo()
-				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-				return
+				return nil, nil, err
 			}
-
-			// 输出信息
-			color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(imageRepo), general.FgMagentaText(imageArchiveFile))
-		}
-	} else { // 参数为 image 的 Repository(:Tag) 或 ID
-		var saveImages []SaveInfo // 需要保存的 image 信息切片
-
-		for _, name := range names {
-			var (
-				imageInfo      ImageInfo   // 匹配成功的 image 信息
-				matchingImages []ImageInfo // 匹配成功的 image 信息切片
-
-				saveImage SaveInfo // 需要保存的 image 信息
-			)
-
-			nameSplit := strings.Split(name, ":")
-			if len(nameSplit) == 2 { // name 是 image Repository:Tag，严格匹配 Repository 和 Tag 都符合的 image
-				var status bool = false  // 是否匹配成功
-				nameRepo := nameSplit[0] // 期望的 image Repository
-				nameTag := nameSplit[1]  // 期望的 image Tag
-				// 遍历 image 列表，查找与 name 对应的 image
-				for _, image := range images {
-					imageSplit := func() []string { // image Repository and Tag
-						if len(image.RepoTags) == 0 {
-							return []string{"", ""}
-						}
-						return strings.Split(image.RepoTags[0], ":")
-					}()
-					imageID = strings.Split(image.ID, ":")[1]                  // image ID without 'sha256' prefix
-					if imageSplit[0] == nameRepo && imageSplit[1] == nameTag { // 匹配到一致的 Repository 和 Tag
-						imageInfo.Repo = imageSplit[0] // image Repository
-						imageInfo.Tag = imageSplit[1]  // image Tag
-						imageInfo.ID = imageID
-						matchingImages = append(matchingImages, imageInfo)
-						status = true
-					}
-				}
-				// 没有匹配到一致的 Repository 和 Tag
-				if !status {
-					color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(name), general.DangerText(general.ReferenceNotExistMessage))
-					continue
-				}
-			} else { // name 是 image ID 或 image Repository （这种情况可能因为 Tag 不同匹配到多个）两种情况
-				// 遍历 image 列表，查找与 name 对应的 image
-				for _, image := range images {
-					imageSplit := func() []string { // image Repository and Tag
-						if len(image.RepoTags) == 0 {
-							return []string{"", ""}
-						}
-						return strings.Split(image.RepoTags[0], ":")
-					}()
-					imageID = strings.Split(image.ID, ":")[1]                      // image ID without 'sha256' prefix
-					if imageSplit[0] == name || strings.HasPrefix(imageID, name) { // 匹配到一致的 Repository 或 ID
-						imageInfo.Repo = imageSplit[0] // image Repository
-						imageInfo.Tag = imageSplit[1]  // image Tag
-						imageInfo.ID = imageID
-						matchingImages = append(matchingImages, imageInfo)
-					}
-				}
+			if matched {
+				matchingImages = append(matchingImages, imageInfo)
+				prefixOnly = prefixOnly && byPrefix
 			}
+		}
 
-			if len(matchingImages) == 0 {
-				// 没有匹配到 Repository
-				color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(name), general.DangerText(general.NoSuchImageMessage))
-				continue
-			} else {
-				for _, image := range matchingImages {
-					if image.Repo == "" {
-						// 将 ID 前 idMinViewLength 位做为存储文件名
-						imageArchiveFile = color.Sprintf("%s.dockerimage", image.ID[:idMinViewLength])
-						saveImage.Name = image.ID[:idMinViewLength]
-						saveImage.File = imageArchiveFile
-					} else {
-						// 将 image Repository 中的 '/' 替换为 '-'，再与 Tag 以及 ID 前 idMinViewLength 位以 '_' 拼接做为存储文件名
-						imageArchiveFile = color.Sprintf("%s_%s_%s.dockerimage", strings.ReplaceAll(image.Repo, "/", "-"), image.Tag, image.ID[:idMinViewLength])
-						saveImage.Name = color.Sprintf("%s:%s", image.Repo, image.Tag)
-						saveImage.File = imageArchiveFile
-					}
-					saveImages = append(saveImages, saveImage)
-				}
+		if len(matchingImages) == 0 {
+			message := general.NoSuchImageMessage // 没有匹配到 image
+			if _, nameTag := general.SplitRepoTag(name); nameTag != "" && !option.Regex && !general.IsGlobPattern(name) {
+				message = general.ReferenceNotExistMessage // 没有匹配到一致的 Repository 和 Tag
 			}
+			color.Printf("%s %s %s -> %s\n", general.PackFlag, action, general.FgBlueText(name), general.DangerText(message))
+			unmatched = append(unmatched, name)
+			continue
 		}
 
-		// 保存 image
-		for _, image := range saveImages {
-			err = general.SaveImage(image.Name, image.File)
+		// 仅通过 ID 前缀匹配到多个 image，不做猜测
+		if prefixOnly && len(matchingImages) > 1 {
+			color.Printf("%s %s %s -> %s\n", general.PackFlag, action, general.FgBlueText(name), general.WarnText(color.Sprintf(general.AmbiguousIDMessage, len(matchingImages))))
+			unmatched = append(unmatched, name)
+			continue
+		}
+
+		for _, imageInfo := range matchingImages {
+			excluded, err := excludeImage(imageInfo, option)
 			if err != nil {
-				fileName, lineNo := general.GetCallerInfo()
-				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-				return
+				return nil, nil, err
 			}
-			// 输出信息
-			color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(image.Name), general.FgMagentaText(image.File))
+			if excluded || seen[imageInfo] {
+				continue
+			}
+			seen[imageInfo] = true
+			selected = append(selected, imageInfo)
+		}
+	}
+
+	return selected, unmatched, nil
+}
+
+// SaveImages 将指定 images 保存到各自存档文件
+//
+// 参数：
+//   - names: image 的 Repository(:Tag)、ID 或模式，允许一次保存多个
+//   - option: 匹配选项
+//   - format: 存档格式，'docker'、'oci' 或 'oci-archive'
+//   - platform: 只保留指定平台，为空时保留全部平台
+//   - setOption: 备份集选项
+//
+// 返回：
+//   - 是否全部保存成功
+func SaveImages(names []string, option general.MatchOption, format string, platform string, setOption SetOption) bool {
+	if format != ImageFormatDocker && format != ImageFormatOCI && format != ImageFormatOCIArchive {
+		color.Printf("%s %s\n", general.DangerText(general.ErrorInfoFlag), color.Sprintf(general.UnsupportedFormatMessage, format))
+		return false
+	}
+
+	if len(names) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "image", "save")
+		return false
+	}
+
+	// 获取 image 列表
+	images, err := general.ListImages()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+
+	// 参数 names 允许是 image 的 Repository(:Tag), ID, 模式或 'all'
+	selectedImages, unmatched, err := selectImagesByName(images, names, option, "Save")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+	// 没有选中任何 image 时不创建备份集，避免空备份集参与保留策略
+	if len(selectedImages) == 0 {
+		return false
+	}
+
+	// 打开保存存档的存储
+	storage, err := outputStorage()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+	defer storage.Close()
+
+	// OCI image layout 需要写入本地文件系统
+	if _, local := general.LocalPath(storage, ""); !local && format != ImageFormatDocker {
+		color.Printf("%s\n", general.DangerText(color.Sprintf(general.LocalOutputOnlyMessage, format)))
+		return false
+	}
+	if general.CurrentSettings.SplitSize != "" && format != ImageFormatDocker {
+		color.Printf("%s\n", general.DangerText(color.Sprintf(general.SplitUnsupportedMessage, format)))
+		return false
+	}
+
+	extension := imageArchiveExtension
+	switch format {
+	case ImageFormatOCI:
+		extension = ociLayoutExtension
+	case ImageFormatOCIArchive:
+		extension = ociLayoutArchiveExtension
+	}
+
+	var (
+		saveImages []SaveInfo                                     // 需要保存的 image 信息切片
+		skipped    = setOption.RequireMatch && len(unmatched) > 0 // 是否有名称没有选中 image，或有 image 因无法生成存档文件名而跳过
+	)
+	for _, image := range selectedImages {
+		archiveFile, err := image.archiveName(platform, extension)
+		if err != nil {
+			color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(image.Reference()), general.DangerText(err))
+			skipped = true
+			continue
+		}
+		saveImages = append(saveImages, SaveInfo{Name: image.Reference(), ID: image.ID, File: archiveFile})
+	}
+	if len(saveImages) == 0 {
+		return false
+	}
+
+	// 写入前检查存档文件名是否重复
+	var items, files []string
+	for _, image := range saveImages {
+		items, files = append(items, image.Name), append(files, image.File)
+	}
+	if reportNameCollisions(items, files) {
+		return false
+	}
+
+	// 启用备份集时保存到本次运行的备份集文件夹
+	saveDir, err := startBackupSet(storage, backupSetImage, setOption)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+
+	// 结束时将本次运行的保存记录写入目录
+	var (
+		records   []general.CatalogRecord
+		completed bool
+	)
+	defer func() { catalogSaves(records, storage, saveDir, setOption, completed) }()
+
+	// 保存 image
+	for _, image := range saveImages {
+		// 拆分为分卷时记录第一个分卷
+		archiveName := path.Join(saveDir, filepath.ToSlash(image.File))
+		storedName := archiveName
+		if format == ImageFormatDocker {
+			storedName = general.StoredArchiveName(archiveName)
 		}
+		image.File = storage.Location(storedName)
+		record := general.NewCatalogRecord(general.CatalogSave, backupSetImage, image.Name, image.File)
+		record.Digest = "sha256:" + image.ID
+		if format == ImageFormatDocker {
+			err = general.SaveImageToStorage(image.Name, storage, archiveName, platform)
+		} else {
+			err = general.SaveImageOCI(image.Name, image.File, format == ImageFormatOCIArchive, platform)
+		}
+		setArchiveSize(&record, storage, storedName)
+		record.Finish(err)
+		records = append(records, record)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return false
+		}
+		// 输出信息
+		color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(image.Name), general.FgMagentaText(image.File))
 	}
+
+	// 有名称或 image 被跳过时备份集不完整，不完成备份集也不执行保留策略
+	if skipped {
+		return false
+	}
+	completed = finishBackupSet(storage, saveDir, backupSetImage, setOption)
+	return completed
 }
 
 // LoadImages 从存档文件加载 image
 //
 // 参数：
-//   - files: 存档文件名，允许一次加载多个
+//   - files: 存档文件名或远程存档的 URL，允许一次加载多个
 func LoadImages(files []string) {
 	if len(files) == 0 {
 		color.Printf(general.DangerText(general.SpecifyMessage), "image archive file", "load")
 		return
 	}
 
+	// 结束时将加载记录写入目录
+	var records []general.CatalogRecord
+	defer func() { recordCatalog(records) }()
+
 	for _, file := range files {
-		result, message, err := general.LoadImage(file)
+		var (
+			result  bool
+			message []string
+			err     error
+		)
+		record := general.NewCatalogRecord(general.CatalogLoad, backupSetImage, "", file)
+		// OCI image layout 需要转换后加载，docker save 存档直接加载
+		if general.IsOCILayout(file) {
+			result, message, err = general.LoadImageOCI(file)
+		} else {
+			result, message, err = general.LoadImage(file)
+		}
 		if err != nil {
+			record.Finish(err)
+			records = append(records, record)
 			fileName, lineNo := general.GetCallerInfo()
 			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
 			return
 		}
 
+		// 成功时每个加载的 image 一条记录，失败时记录 docker 返回的信息
 		if result {
 			for _, msg := range message {
-				color.Printf("%s Load %s -> %s", general.LoadFlag, general.FgBlueText(file), general.FgMagentaText(strings.Split(msg, ": ")[1]))
+				loaded := strings.TrimSpace(strings.Split(msg, ": ")[1])
+				loadedRecord := record
+				loadedRecord.Item = loaded
+				if inspect, err := general.InspectImage(loaded); err == nil {
+					loadedRecord.Digest = inspect.ID
+				}
+				loadedRecord.Finish(nil)
+				records = append(records, loadedRecord)
+				// 输出已恢复的平台
+				platforms, err := general.ImagePlatforms(loaded)
+				if err != nil {
+					color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.FgMagentaText(loaded))
+					continue
+				}
+				color.Printf("%s Load %s -> %s (%s)\n", general.LoadFlag, general.FgBlueText(file), general.FgMagentaText(loaded), strings.Join(platforms, ", "))
 			}
 		} else {
+			record.Finish(errors.New(strings.Join(message, "; ")))
+			records = append(records, record)
 			for _, msg := range message {
 				color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(msg))
 			}
@@ -0,0 +1,85 @@
+/*
+File: image_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 10:12:40
+
+Description: image 名称匹配和存档命名测试
+*/
+
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yhyj/wocker/general"
+)
+
+func TestMatchImage(t *testing.T) {
+	var (
+		tagged   = ImageInfo{Repo: "nginx", Tag: "1.25", ID: "a1b2c3d4e5f6a7b8"}
+		dangling = ImageInfo{ID: "0f0e0d0c0b0a0908"}
+	)
+	tests := []struct {
+		name       string
+		info       ImageInfo
+		pattern    string
+		regex      bool
+		matched    bool
+		prefixOnly bool
+	}{
+		{"regex repo", tagged, "^nginx:", true, true, false},
+		{"regex tag", tagged, `:1\.25$`, true, true, false},
+		{"regex does not match ID", tagged, "b2c3", true, false, false},
+		{"regex does not match dangling ID", dangling, "0d0c", true, false, false},
+		{"glob repo", tagged, "ngi*", false, true, false},
+		{"glob repo and tag", tagged, "nginx:1.*", false, true, false},
+		{"glob skips dangling", dangling, "*", false, false, false},
+		{"exact repo", tagged, "nginx", false, true, false},
+		{"exact repo and tag", tagged, "nginx:1.25", false, true, false},
+		{"other tag", tagged, "nginx:latest", false, false, false},
+		{"ID prefix", tagged, "a1b2", false, true, true},
+		{"ID substring", tagged, "b2c3", false, false, false},
+		{"dangling ID prefix", dangling, "0f0e", false, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, prefixOnly, err := matchImage(test.info, test.pattern, test.regex)
+			if err != nil {
+				t.Fatalf("matchImage(%q) error: %v", test.pattern, err)
+			}
+			if matched != test.matched || prefixOnly != test.prefixOnly {
+				t.Errorf("matchImage(%q) = %v, %v, want %v, %v", test.pattern, matched, prefixOnly, test.matched, test.prefixOnly)
+			}
+		})
+	}
+
+	if _, _, err := matchImage(tagged, "(", true); err == nil {
+		t.Error("matchImage with an invalid regex should return an error")
+	}
+}
+
+func TestImageArchiveNameEmptyFields(t *testing.T) {
+	previous := general.CurrentSettings.ImageTemplate
+	defer func() { general.CurrentSettings.ImageTemplate = previous }()
+	general.CurrentSettings.ImageTemplate = "{{.Repo}}/{{.Tag}}-{{.Platform}}"
+
+	tests := []struct {
+		info     ImageInfo
+		platform string
+		want     string
+	}{
+		{ImageInfo{Repo: "library/nginx", Tag: "1.25", ID: "a1b2c3d4e5f6a7b8"}, "linux/arm64", "library-nginx/1.25-linux-arm64.dockerimage"},
+		{ImageInfo{ID: "0f0e0d0c0b0a0908"}, "", "none/none-default.dockerimage"},
+	}
+	for _, test := range tests {
+		got, err := test.info.archiveName(test.platform, imageArchiveExtension)
+		if err != nil {
+			t.Fatalf("archiveName(%+v) error: %v", test.info, err)
+		}
+		if filepath.ToSlash(got) != test.want {
+			t.Errorf("archiveName(%+v) = %q, want %q", test.info, got, test.want)
+		}
+	}
+}
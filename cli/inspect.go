@@ -0,0 +1,142 @@
+/*
+File: inspect.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 13:15:02
+
+Description: 子命令 'inspect-archive' 的实现
+*/
+
+package cli
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/opencontainers/go-digest"
+	"github.com/yhyj/wocker/general"
+)
+
+const topDirectoriesLimit = 10 // volume 存档中显示的顶层文件夹数量
+
+// InspectArchives 离线解析并输出存档文件的内容，无需连接 docker service
+//
+// 参数：
+//   - files: 存档文件，允许一次解析多个
+func InspectArchives(files []string) {
+	if len(files) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "archive file", "inspect")
+		return
+	}
+
+	for _, file := range files {
+		info, err := general.InspectArchive(file)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			continue
+		}
+
+		color.Printf("%s %s (%s)\n", general.InspectFlag, general.FgBlueText(file), general.SecondaryText(info.Kind))
+		switch info.Kind {
+		case general.ArchiveKindImage:
+			for _, image := range info.Images {
+				printImageArchiveInfo(image)
+			}
+		case general.ArchiveKindVolume:
+			printVolumeArchiveInfo(info.Volume)
+		}
+	}
+}
+
+// printImageArchiveInfo 输出存档中单个 image 的信息
+//
+// 参数：
+//   - image: image 信息
+func printImageArchiveInfo(image general.ImageArchiveInfo) {
+	created := ""
+	if image.Created != nil {
+		created = image.Created.Local().Format("2006-01-02 15:04:05")
+	}
+	platform := image.OS + "/" + image.Architecture
+	if image.Variant != "" {
+		platform += "/" + image.Variant
+	}
+
+	var totalSize int64
+	for _, layer := range image.Layers {
+		totalSize += layer.Size
+	}
+
+	var labels []string
+	for key, value := range image.Labels {
+		labels = append(labels, key+"="+value)
+	}
+	sort.Strings(labels)
+
+	tableHeader := []string{"Field", "Value"} // 表头
+	tableData := [][]string{                  // 表数据
+		{"Tags", strings.Join(image.RepoTags, "\n")},
+		{"ID", digest.Digest(image.ID).Encoded()[:idMinViewLength]},
+		{"Platform", platform},
+		{"Created", created},
+		{"Entrypoint", strings.Join(image.Entrypoint, " ")},
+		{"Cmd", strings.Join(image.Cmd, " ")},
+		{"Env", strings.Join(image.Env, "\n")},
+		{"Exposed Ports", strings.Join(image.ExposedPorts, ", ")},
+		{"Labels", strings.Join(labels, "\n")},
+		{"Layers", color.Sprintf("%d (%s)", len(image.Layers), strings.TrimSpace(general.HumanSize(totalSize)))},
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+
+	tableHeader = []string{"#", "Diff ID", "Size", "Created By"}
+	tableData = [][]string{}
+	for index, layer := range image.Layers {
+		diffID := ""
+		if layer.DiffID != "" {
+			diffID = digest.Digest(layer.DiffID).Encoded()[:idMinViewLength]
+		}
+		tableData = append(tableData, []string{color.Sprint(index + 1), diffID, general.HumanSize(layer.Size), truncateText(layer.CreatedBy, 80)})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// printVolumeArchiveInfo 输出 volume 存档的信息
+//
+// 参数：
+//   - volume: volume 存档信息
+func printVolumeArchiveInfo(volume general.VolumeArchiveInfo) {
+	tableHeader := []string{"Files", "Directories", "Symlinks", "Total Size"} // 表头
+	tableData := [][]string{                                                  // 表数据
+		{color.Sprint(volume.Files), color.Sprint(volume.Directories), color.Sprint(volume.Symlinks), general.HumanSize(volume.TotalSize)},
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+
+	tableHeader = []string{"Top Directory", "Files", "Size"}
+	tableData = [][]string{}
+	for index, directory := range volume.TopDirectories {
+		if index >= topDirectoriesLimit {
+			break
+		}
+		tableData = append(tableData, []string{directory.Name, color.Sprint(directory.Files), general.HumanSize(directory.Size)})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// truncateText 截断过长的文本
+//
+// 参数：
+//   - text: 文本
+//   - length: 最大长度
+//
+// 返回：
+//   - 截断后的文本
+func truncateText(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-3]) + "..."
+}
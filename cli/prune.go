@@ -0,0 +1,336 @@
+/*
+File: prune.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 10:21:47
+
+Description: 参数 '--prune' 的实现
+*/
+
+package cli
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// 清理选项
+type PruneOption struct {
+	Keep      int           // 每个 Repository 保留最新的 Tag 数量，0 表示不按数量清理
+	Dangling  bool          // 是否清理悬空 image
+	Unused    bool          // 是否清理未被任何容器使用的 volume
+	OlderThan time.Duration // 仅清理早于该时长创建的对象，0 表示不限制
+	DryRun    bool          // 只预览，不删除
+	Backup    bool          // 删除前先保存到存档文件
+	Force     bool          // 删除前不请求确认
+}
+
+// 待清理的 image
+type pruneImage struct {
+	ImageInfo
+	Created int64  // 创建时间
+	Size    int64  // 大小
+	Reason  string // 清理原因
+}
+
+// PruneImages 按保留规则清理 image
+//
+//   - 被 --keep 规则保留的 image 不会被清理
+//   - 同时指定 --keep 和 --older-than 时，仅清理同时满足两者的 Tag
+//   - 悬空 image 只受 --older-than 和清理范围限制，未指定清理范围时清理所有悬空 image
+//
+// 参数：
+//   - names: 限定清理范围的 image 名称或模式，为空时不限定
+//   - matchOption: 匹配选项
+//   - option: 清理选项
+func PruneImages(names []string, matchOption general.MatchOption, option PruneOption) {
+	if option.Keep <= 0 && !option.Dangling && option.OlderThan <= 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "retention rule (--keep, --dangling or --older-than)", "prune images")
+		return
+	}
+
+	// 获取 image 列表
+	images, err := general.ListImages()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 限定清理范围
+	scope := make(map[string]bool) // 在清理范围内的 image 引用
+	scoped := len(names) > 0       // 是否指定了清理范围
+	if !scoped {
+		names = []string{"all"}
+	}
+	selectedImages, err := selectImages(images, names, matchOption, "Prune")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	for _, image := range selectedImages {
+		scope[image.ID] = true
+	}
+
+	deadline := time.Now().Add(-option.OlderThan) // 早于该时间创建的对象视为过期
+	isExpired := func(created int64) bool {
+		return option.OlderThan <= 0 || time.Unix(created, 0).Before(deadline)
+	}
+
+	var candidates []pruneImage // 待清理的 image
+
+	// 按 Repository 分组所有 Tag，同一 image 的多个 Tag 分别计算
+	repositories := make(map[string][]pruneImage)
+	for _, image := range images {
+		if !scope[newImageInfo(image).ID] {
+			continue
+		}
+		for _, repoTag := range image.RepoTags {
+			if repoTag == "<none>:<none>" {
+				continue
+			}
+			info := newImageInfo(image)
+			info.Repo, info.Tag = general.SplitRepoTag(repoTag)
+			excluded, err := excludeImage(info, matchOption)
+			if err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				return
+			}
+			if excluded {
+				continue
+			}
+			repositories[info.Repo] = append(repositories[info.Repo], pruneImage{ImageInfo: info, Created: image.Created, Size: image.Size})
+		}
+	}
+	if option.Keep > 0 || option.OlderThan > 0 {
+		for _, tags := range repositories {
+			// 按创建时间从新到旧排序
+			sort.SliceStable(tags, func(i, j int) bool { return tags[i].Created > tags[j].Created })
+			for index, tag := range tags {
+				if option.Keep > 0 && index < option.Keep {
+					continue
+				}
+				if !isExpired(tag.Created) {
+					continue
+				}
+				tag.Reason = "older than " + option.OlderThan.String()
+				if option.Keep > 0 {
+					tag.Reason = color.Sprintf("beyond newest %d", option.Keep)
+				}
+				candidates = append(candidates, tag)
+			}
+		}
+	}
+
+	// 悬空 image
+	if option.Dangling {
+		danglingImages, err := general.ListDanglingImages()
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		for _, image := range danglingImages {
+			info := newImageInfo(image)
+			info.Repo, info.Tag = "", ""
+			// 指定了清理范围时，只清理范围内的悬空 image（悬空 image 只能通过 ID 前缀指定）
+			if scoped && !scope[info.ID] {
+				continue
+			}
+			excluded, err := excludeImage(info, matchOption)
+			if err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				return
+			}
+			if excluded || !isExpired(image.Created) {
+				continue
+			}
+			candidates = append(candidates, pruneImage{ImageInfo: info, Created: image.Created, Size: image.Size, Reason: "dangling"})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Repo != candidates[j].Repo {
+			return candidates[i].Repo < candidates[j].Repo
+		}
+		return candidates[i].Created > candidates[j].Created
+	})
+
+	// 预览
+	tableHeader := []string{"Repository", "Tag", "ID", "Created", "Size", "Reason"} // 表头
+	tableData := [][]string{}                                                       // 表数据
+	for _, image := range candidates {
+		tableData = append(tableData, []string{image.Repo, image.Tag, image.ID[:idMinViewLength], general.UnixTime2TimeString(image.Created), general.HumanSize(image.Size), image.Reason})
+	}
+	if !confirmPrune(tableHeader, tableData, "images", option) {
+		return
+	}
+
+	// 打开保存存档的存储，用于保存备份
+	storage, err := outputStorage()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer storage.Close()
+
+	for _, image := range candidates {
+		reference := image.Reference()
+		if image.Repo == "" {
+			reference = image.ID
+		}
+		if option.Backup {
+			archiveFile := image.archiveFile()
+			if err := general.SaveImageToStorage(reference, storage, archiveFile, ""); err != nil {
+				color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(image.Reference()), general.DangerText(err))
+				continue
+			}
+			color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(image.Reference()), general.FgMagentaText(storage.Location(general.StoredArchiveName(archiveFile))))
+		}
+		if err := general.RemoveImage(reference); err != nil {
+			color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(image.Reference()), general.DangerText(err))
+			continue
+		}
+		color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(image.Reference()), general.FgMagentaText(general.RemovedMessage))
+	}
+}
+
+// PruneVolumes 按保留规则清理 volume
+//
+//   - 正在被容器使用的 volume 永远不会被清理
+//   - 同时指定 --unused 和 --older-than 时，仅清理同时满足两者的 volume
+//
+// 参数：
+//   - names: 限定清理范围的 volume 名称或模式，为空时不限定
+//   - matchOption: 匹配选项
+//   - option: 清理选项
+func PruneVolumes(names []string, matchOption general.MatchOption, option PruneOption) {
+	if !option.Unused && option.OlderThan <= 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "retention rule (--unused or --older-than)", "prune volumes")
+		return
+	}
+
+	// 获取 volume 列表
+	volumes, err := general.ListVolumes()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 获取 volume 的使用者
+	volumeUsers, err := general.VolumeUsers()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 限定清理范围
+	var volumeNames []string
+	for _, volume := range volumes.Volumes {
+		volumeNames = append(volumeNames, volume.Name)
+	}
+	if len(names) == 0 {
+		names = []string{"all"}
+	}
+	selectedVolumes, err := selectVolumes(volumeNames, names, matchOption, "Prune")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	deadline := time.Now().Add(-option.OlderThan) // 早于该时间创建的对象视为过期
+
+	var candidates []string                                        // 待清理的 volume
+	tableHeader := []string{"Name", "Driver", "Created", "Reason"} // 表头
+	tableData := [][]string{}                                      // 表数据
+	for _, volume := range volumes.Volumes {
+		if !general.SliceContains(selectedVolumes, volume.Name) || len(volumeUsers[volume.Name]) != 0 {
+			continue
+		}
+		reason := "unused"
+		if option.OlderThan > 0 {
+			created, err := time.Parse(time.RFC3339, volume.CreatedAt)
+			if err != nil || !created.Before(deadline) {
+				continue
+			}
+			reason = "older than " + option.OlderThan.String()
+		}
+		candidates = append(candidates, volume.Name)
+		tableData = append(tableData, []string{volume.Name, volume.Driver, volume.CreatedAt, reason})
+	}
+
+	if !confirmPrune(tableHeader, tableData, "volumes", option) {
+		return
+	}
+
+	// 打开保存存档的存储，用于保存备份
+	storage, err := outputStorage()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer storage.Close()
+
+	for _, volumeName := range candidates {
+		if option.Backup {
+			record, err := saveVolumeArchive(storage, volumeName, volumeArchiveFile(volumeName))
+			if err != nil {
+				color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(volumeName), general.DangerText(err))
+				continue
+			}
+			color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(volumeName), general.FgMagentaText(record.Archive))
+		}
+		if err := general.RemoveVolume(volumeName); err != nil {
+			color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(volumeName), general.DangerText(err))
+			continue
+		}
+		color.Printf("%s Prune %s -> %s\n", general.RemoveFlag, general.FgBlueText(volumeName), general.FgMagentaText(general.RemovedMessage))
+	}
+}
+
+// confirmPrune 输出待清理对象的预览表格，并确认是否继续
+//
+// 参数：
+//   - tableHeader: 表头
+//   - tableData: 表数据
+//   - kind: 对象类型，用于输出信息
+//   - option: 清理选项
+//
+// 返回：
+//   - 是否继续清理
+func confirmPrune(tableHeader []string, tableData [][]string, kind string, option PruneOption) bool {
+	if len(tableData) == 0 {
+		color.Printf("%s\n", general.SecondaryText(color.Sprintf(general.NothingToPruneMessage, kind)))
+		return false
+	}
+
+	color.Println(general.NewTable(tableHeader, tableData))
+
+	if option.DryRun {
+		color.Printf("%s\n", general.SecondaryText(color.Sprintf(general.DryRunMessage, len(tableData), kind)))
+		return false
+	}
+
+	if option.Force {
+		return true
+	}
+
+	confirmed, err := general.Confirm(color.Sprintf("Remove %d %s?", len(tableData), kind))
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+	return confirmed
+}
@@ -0,0 +1,174 @@
+/*
+File: registry.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:31:09
+
+Description: 参数 '--push' 和 '--pull' 的实现
+*/
+
+package cli
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// PushImages 将指定 images 重新打标签后推送到 registry
+//
+//   - 选择 image 的规则与 SaveImages 一致
+//   - 推送完成后删除本次添加的标签，目标标签原本就存在时恢复为原来的 image
+//
+// 参数：
+//   - names: image 的 Repository(:Tag)、ID 或模式，允许一次推送多个
+//   - option: 匹配选项
+//   - prefix: 推送目标的 registry 前缀，例如 'localhost:5000/backup'
+func PushImages(names []string, option general.MatchOption, prefix string) {
+	if len(names) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "image", "push")
+		return
+	}
+	if prefix == "" {
+		color.Printf(general.DangerText(general.SpecifyMessage), "registry prefix (--registry)", "push")
+		return
+	}
+
+	// 获取 image 列表
+	images, err := general.ListImages()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	selectedImages, err := selectImages(images, names, option, "Push")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	for _, image := range selectedImages {
+		// 没有 Repository 的 image 无法推送
+		if image.Repo == "" {
+			color.Printf("%s Push %s -> %s\n", general.PushFlag, general.FgBlueText(image.Reference()), general.DangerText(general.UntaggedImageMessage))
+			continue
+		}
+
+		target, err := general.RetargetReference(image.Reference(), prefix)
+		if err != nil {
+			color.Printf("%s Push %s -> %s\n", general.PushFlag, general.FgBlueText(image.Reference()), general.DangerText(err))
+			continue
+		}
+
+		// 记录目标标签原本指向的 image，推送后恢复
+		previousID, err := general.LocalImageID(target)
+		if err != nil {
+			color.Printf("%s Push %s -> %s\n", general.PushFlag, general.FgBlueText(image.Reference()), general.DangerText(err))
+			continue
+		}
+
+		if err := general.PushImage(image.Reference(), target); err != nil {
+			color.Printf("%s Push %s -> %s\n", general.PushFlag, general.FgBlueText(image.Reference()), general.DangerText(err))
+		} else {
+			color.Printf("%s Push %s -> %s\n", general.PushFlag, general.FgBlueText(image.Reference()), general.FgMagentaText(target))
+		}
+
+		// 删除本次添加的临时标签，目标标签原本就存在时恢复为原来的 image
+		if previousID == "" {
+			err = general.RemoveImage(target)
+		} else if strings.TrimPrefix(previousID, "sha256:") != image.ID {
+			err = general.TagImage(previousID, target)
+		}
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		}
+	}
+}
+
+// readReferenceList 读取 image 引用清单
+//
+//   - 每行一个引用，忽略空行和以 '#' 开头的注释
+//
+// 参数：
+//   - listFile: 清单文件
+//
+// 返回：
+//   - image 引用切片
+//   - 错误信息
+func readReferenceList(listFile string) ([]string, error) {
+	file, err := os.Open(listFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var references []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		references = append(references, line)
+	}
+
+	return references, scanner.Err()
+}
+
+// PullImages 按清单从 registry 拉取 image
+//
+//   - 指定 registry 前缀时，从该前缀下拉取（与 PushImages 的目标一致），并恢复为清单中的原始引用
+//
+// 参数：
+//   - files: 清单文件，允许一次读取多个
+//   - prefix: registry 前缀，为空时直接拉取清单中的引用
+func PullImages(files []string, prefix string) {
+	if len(files) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "image reference list file", "pull")
+		return
+	}
+
+	for _, file := range files {
+		references, err := readReferenceList(file)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		for _, reference := range references {
+			source := reference
+			if prefix != "" {
+				if source, err = general.RetargetReference(reference, prefix); err != nil {
+					color.Printf("%s Pull %s -> %s\n", general.LoadFlag, general.FgBlueText(reference), general.DangerText(err))
+					continue
+				}
+			}
+
+			if err := general.PullImage(source); err != nil {
+				color.Printf("%s Pull %s -> %s\n", general.LoadFlag, general.FgBlueText(source), general.DangerText(err))
+				continue
+			}
+
+			// 恢复原始引用并删除临时标签，包含 digest 的引用无法添加标签，保留拉取时的引用
+			if source != reference && !general.IsDigestReference(reference) {
+				if err := general.TagImage(source, reference); err != nil {
+					color.Printf("%s Pull %s -> %s\n", general.LoadFlag, general.FgBlueText(source), general.DangerText(err))
+					continue
+				}
+				if err := general.RemoveImage(source); err != nil {
+					fileName, lineNo := general.GetCallerInfo()
+					color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				}
+			}
+
+			color.Printf("%s Pull %s -> %s\n", general.LoadFlag, general.FgBlueText(source), general.FgMagentaText(reference))
+		}
+	}
+}
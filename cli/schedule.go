@@ -0,0 +1,290 @@
+/*
+File: schedule.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 21:18:36
+
+Description: 子命令 'schedule' 的实现
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// scheduleTimeFormat 定时任务时间的显示格式
+const scheduleTimeFormat = "2006-01-02 15:04"
+
+// loadJobs 读取配置文件中的定时任务并检查
+//
+// 参数：
+//   - configFile: 配置文件路径
+//
+// 返回：
+//   - 任务名对应的任务定义
+//   - 按名称排序的任务名
+//   - 错误信息
+func loadJobs(configFile string) (map[string]general.Job, []string, error) {
+	config, err := general.LoadConfig(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var jobNames []string
+	for name, job := range config.Jobs {
+		if err := job.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("job %s: %w", name, err)
+		}
+		jobNames = append(jobNames, name)
+	}
+	sort.Strings(jobNames)
+	return config.Jobs, jobNames, nil
+}
+
+// ListJobs 输出配置文件中的定时任务、下次运行时间和最近一次运行结果
+//
+// 参数：
+//   - configFile: 配置文件路径
+func ListJobs(configFile string) {
+	jobs, jobNames, err := loadJobs(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if len(jobNames) == 0 {
+		color.Printf("%s\n", general.SecondaryText(color.Sprintf(general.NoJobsMessage, configFile)))
+		return
+	}
+
+	statuses, err := general.ReadJobStatus()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	tableHeader := []string{"Job", "Kind", "Names", "Schedule", "Next Run", "Last Run", "Result"} // 表头
+	tableData := [][]string{}                                                                     // 表数据
+	now := time.Now()
+	for _, name := range jobNames {
+		job := jobs[name]
+		schedule, _ := general.ParseSchedule(job.Schedule)
+		nextRun := "-"
+		if next := schedule.Next(now); !next.IsZero() {
+			nextRun = next.Format(scheduleTimeFormat)
+		}
+		lastRun, result := "-", "-"
+		if status, ok := statuses[name]; ok {
+			lastRun, result = status.Start.Format(scheduleTimeFormat), status.Result
+			if status.Message != "" {
+				result += ": " + status.Message
+			}
+		}
+		tableData = append(tableData, []string{name, job.Kind, strings.Join(job.Names, ", "), job.Schedule, nextRun, lastRun, result})
+	}
+	color.Println(general.NewTable(tableHeader, tableData))
+}
+
+// RunJobs 立即运行指定的定时任务
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - jobNames: 任务名
+//
+// 返回：
+//   - 是否全部运行成功
+func RunJobs(configFile string, jobNames []string) bool {
+	jobs, _, err := loadJobs(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+
+	succeeded := true
+	for _, name := range jobNames {
+		job, ok := jobs[name]
+		if !ok {
+			color.Printf("%s %s\n", general.DangerText(general.ErrorInfoFlag), color.Sprintf(general.NoSuchJobMessage, name))
+			succeeded = false
+			continue
+		}
+		if runJob(name, job) != general.JobSucceeded {
+			succeeded = false
+		}
+	}
+	return succeeded
+}
+
+// ServeJobs 常驻运行，按计划依次运行配置文件中的定时任务，收到 SIGINT 或 SIGTERM 后退出
+//
+//   - 任务依次运行，到期时上一个任务尚未结束的，在其结束后立即运行
+//   - 收到信号时等待正在运行的任务结束后再退出
+//   - 每个任务持有锁运行，其他进程正在运行同一任务时跳过本次运行
+//
+// 参数：
+//   - configFile: 配置文件路径
+func ServeJobs(configFile string) {
+	jobs, jobNames, err := loadJobs(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if len(jobNames) == 0 {
+		color.Printf("%s\n", general.SecondaryText(color.Sprintf(general.NoJobsMessage, configFile)))
+		return
+	}
+
+	// 计算每个任务的下次运行时间
+	schedules := make(map[string]general.Schedule)
+	nextRuns := make(map[string]time.Time)
+	now := time.Now()
+	for _, name := range jobNames {
+		schedules[name], _ = general.ParseSchedule(jobs[name].Schedule)
+		nextRuns[name] = schedules[name].Next(now)
+		color.Printf("%s Job %s -> %s\n", general.ClockFlag, general.FgBlueText(name), general.FgMagentaText(nextRuns[name].Format(scheduleTimeFormat)))
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	for {
+		// 找出最早到期的时间，没有可运行的任务时退出
+		var earliest time.Time
+		for _, name := range jobNames {
+			if next := nextRuns[name]; !next.IsZero() && (earliest.IsZero() || next.Before(earliest)) {
+				earliest = next
+			}
+		}
+		if earliest.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-signals:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, name := range jobNames {
+			if next := nextRuns[name]; next.IsZero() || time.Now().Before(next) {
+				continue
+			}
+			runJob(name, jobs[name])
+			nextRuns[name] = schedules[name].Next(time.Now())
+			color.Printf("%s Job %s -> %s\n", general.ClockFlag, general.FgBlueText(name), general.FgMagentaText(nextRuns[name].Format(scheduleTimeFormat)))
+
+			// 任务之间检查是否需要退出
+			select {
+			case <-signals:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// runJob 持有锁运行单个定时任务并记录运行结果
+//
+// 参数：
+//   - name: 任务名
+//   - job: 任务定义
+//
+// 返回：
+//   - 运行结果
+func runJob(name string, job general.Job) string {
+	status := general.JobStatus{Job: name, Start: time.Now(), Result: general.JobSucceeded}
+	color.Printf("%s Job %s -> %s\n", general.ClockFlag, general.FgBlueText(name), general.SecondaryText(status.Start.Format(scheduleTimeFormat)))
+
+	unlock, err := general.LockJob(name)
+	switch {
+	case errors.Is(err, general.ErrJobLocked):
+		status.Result, status.Message = general.JobSkipped, err.Error()
+	case err != nil:
+		status.Result, status.Message = general.JobFailed, err.Error()
+	default:
+		err = runJobLocked(job)
+		unlock()
+		if err != nil {
+			status.Result, status.Message = general.JobFailed, err.Error()
+		}
+	}
+	status.Duration = time.Since(status.Start).Round(time.Second).String()
+
+	resultText := general.SuccessText(status.Result)
+	if status.Result != general.JobSucceeded {
+		resultText = general.DangerText(status.Result + ": " + status.Message)
+	}
+	color.Printf("%s Job %s -> %s\n", general.ClockFlag, general.FgBlueText(name), resultText)
+
+	if err := general.RecordJobStatus(status); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+	}
+	return status.Result
+}
+
+// runJobLocked 使用任务的设置保存 image 或 volume 到备份集
+//
+//   - 任务中设置的输出文件夹和命名模板临时覆盖当前配置，运行结束后恢复
+//   - 任务的名称或模式没有选中对象时视为失败，备份集不完成，也不执行保留策略
+//
+// 参数：
+//   - job: 任务定义
+//
+// 返回：
+//   - 错误信息
+func runJobLocked(job general.Job) error {
+	original := general.CurrentSettings
+	defer func() { general.CurrentSettings = original }()
+	if job.OutputDir != "" {
+		general.CurrentSettings.OutputDir = job.OutputDir
+	}
+	if job.NameTemplate != "" {
+		if job.Kind == backupSetImage {
+			general.CurrentSettings.ImageTemplate = job.NameTemplate
+		} else {
+			general.CurrentSettings.VolumeTemplate = job.NameTemplate
+		}
+	}
+
+	format := job.Format
+	if format == "" {
+		format = ImageFormatDocker
+	}
+	matchOption := general.MatchOption{Regex: job.Regex, Exclude: job.Exclude}
+	setOption := SetOption{Enabled: true, Retention: job.Retention(), RequireMatch: true}
+
+	save := func() error {
+		var saved bool
+		if job.Kind == backupSetImage {
+			saved = SaveImages(job.Names, matchOption, format, job.Platform, setOption)
+		} else {
+			saved = SaveVolumes(job.Names, matchOption, setOption)
+		}
+		if !saved {
+			return errors.New(general.JobIncompleteMessage)
+		}
+		return nil
+	}
+
+	if job.Context != "" {
+		return general.WithContext(job.Context, save)
+	}
+	return save()
+}
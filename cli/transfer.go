@@ -0,0 +1,150 @@
+/*
+File: transfer.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:20:14
+
+Description: 子命令 'transfer' 的实现
+*/
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// TransferImages 将指定 images 从当前 docker service 直接传输到目标 docker service
+//
+// 参数：
+//   - names: image 的 Repository(:Tag)、ID、模式或 'all'，允许一次传输多个
+//   - option: 匹配选项
+//   - toContext: 目标 docker context 名
+//   - toHost: 目标 docker service 地址，不为空时优先于 toContext
+//   - compress: 是否使用 gzip 压缩传输的数据
+func TransferImages(names []string, option general.MatchOption, toContext string, toHost string, compress bool) {
+	if len(names) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "image", "transfer")
+		return
+	}
+	if toContext == "" && toHost == "" {
+		color.Printf(general.DangerText(general.SpecifyMessage), "target docker context or host", "transfer to")
+		return
+	}
+
+	// 获取 image 列表
+	images, err := general.ListImages()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 参数 names 允许是 image 的 Repository(:Tag), ID, 模式或 'all'
+	selectedImages, err := selectImages(images, names, option, "Transfer")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if len(selectedImages) == 0 {
+		return
+	}
+
+	// 连接目标 docker service
+	transfer, err := general.NewHostTransfer(toContext, toHost)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer transfer.Close()
+
+	for _, image := range selectedImages {
+		name := image.Reference()
+		result, err := transfer.TransferImage(name, compress, func(sent int64) {
+			color.Printf("\r%s Transfer %s: %s sent", general.SendFlag, general.FgBlueText(name), general.HumanSize(sent))
+		})
+		color.Print("\r\033[K")
+		if err != nil {
+			color.Printf("%s Transfer %s -> %s\n", general.SendFlag, general.FgBlueText(name), general.DangerText(err))
+			continue
+		}
+
+		if !result.Loaded {
+			for _, message := range result.Messages {
+				color.Printf("%s Transfer %s -> %s\n", general.SendFlag, general.FgBlueText(name), general.DangerText(strings.TrimSpace(message)))
+			}
+			continue
+		}
+		for _, message := range result.Messages {
+			color.Printf("%s Transfer %s -> %s\n", general.SendFlag, general.FgBlueText(name), general.FgMagentaText(strings.TrimSpace(message)))
+		}
+		color.Printf("%s %d/%d layers, %s sent, %s skipped (already on target)\n", general.SendFlag, result.Layers-result.Skipped, result.Layers, general.FgGreenText(general.HumanSize(result.Sent)), general.SecondaryText(general.HumanSize(result.SkippedSize)))
+	}
+}
+
+// MigrateVolumes 将指定 volumes 从当前 docker service 直接迁移到目标 docker service 的同名 volume
+//
+// 参数：
+//   - names: volume 的 Name、模式或 'all'，允许一次迁移多个
+//   - option: 匹配选项
+//   - toContext: 目标 docker context 名
+//   - toHost: 目标 docker service 地址，不为空时优先于 toContext
+//   - compress: 是否使用 gzip 压缩传输的数据
+func MigrateVolumes(names []string, option general.MatchOption, toContext string, toHost string, compress bool) {
+	if len(names) == 0 {
+		color.Printf(general.DangerText(general.SpecifyMessage), "volume", "transfer")
+		return
+	}
+	if toContext == "" && toHost == "" {
+		color.Printf(general.DangerText(general.SpecifyMessage), "target docker context or host", "transfer to")
+		return
+	}
+
+	// 获取 volume 列表
+	volumes, err := general.ListVolumes()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	var volumeNames []string
+	for _, volume := range volumes.Volumes {
+		volumeNames = append(volumeNames, volume.Name)
+	}
+
+	// 参数 names 允许是 volume 的 Name、模式或 'all'
+	selectedVolumes, err := selectVolumes(volumeNames, names, option, "Transfer")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if len(selectedVolumes) == 0 {
+		return
+	}
+
+	// 连接目标 docker service
+	transfer, err := general.NewHostTransfer(toContext, toHost)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer transfer.Close()
+
+	for _, volumeName := range selectedVolumes {
+		sent, err := transfer.MigrateVolume(volumeName, volumeName, compress, func(sent int64) {
+			color.Printf("\r%s Transfer %s: %s sent", general.SendFlag, general.FgBlueText(volumeName), general.HumanSize(sent))
+		})
+		color.Print("\r\033[K")
+		if err != nil {
+			color.Printf("%s Transfer %s -> %s\n", general.SendFlag, general.FgBlueText(volumeName), general.DangerText(err))
+			continue
+		}
+		color.Printf("%s Transfer %s -> %s (%s sent, checksums verified)\n", general.SendFlag, general.FgBlueText(volumeName), general.FgMagentaText(volumeName), general.FgGreenText(general.HumanSize(sent)))
+	}
+}
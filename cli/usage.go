@@ -0,0 +1,226 @@
+/*
+File: usage.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:02:31
+
+Description: 子命令 'usage' 的实现
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/wocker/general"
+)
+
+// image 磁盘使用情况
+type ImageUsage struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	ID         string `json:"id"`
+	Size       int64  `json:"size"`        // 总大小，包括共享层
+	SharedSize int64  `json:"shared_size"` // 与其他 image 共享的层的大小，-1 表示不可用
+	UniqueSize int64  `json:"unique_size"` // 仅该 image 使用的层的大小，-1 表示不可用
+	Containers int64  `json:"containers"`  // 使用该 image 的容器数量
+}
+
+// Repository 磁盘使用情况
+type RepositoryUsage struct {
+	Repository string `json:"repository"`
+	Images     int    `json:"images"`      // image 数量
+	Size       int64  `json:"size"`        // 各 image 总大小之和，共享层会被重复计算
+	UniqueSize int64  `json:"unique_size"` // 各 image 独占大小之和
+}
+
+// volume 磁盘使用情况
+type VolumeUsage struct {
+	Name     string `json:"name"`
+	Driver   string `json:"driver"`
+	Size     int64  `json:"size"`      // 大小，-1 表示不可用
+	RefCount int64  `json:"ref_count"` // 引用该 volume 的容器数量，-1 表示不可用
+}
+
+// 构建缓存磁盘使用情况
+type BuildCacheUsage struct {
+	Count       int   `json:"count"`       // 缓存记录数量
+	InUse       int   `json:"in_use"`      // 正在使用的缓存记录数量
+	Size        int64 `json:"size"`        // 总大小
+	SharedSize  int64 `json:"shared_size"` // 共享的缓存记录大小
+	Reclaimable int64 `json:"reclaimable"` // 可回收的大小
+}
+
+// 磁盘使用情况
+type Usage struct {
+	LayersSize   int64             `json:"layers_size"` // 所有 image 层实际占用的大小，共享层只计算一次
+	Images       []ImageUsage      `json:"images"`
+	Repositories []RepositoryUsage `json:"repositories"`
+	Volumes      []VolumeUsage     `json:"volumes"`
+	BuildCache   BuildCacheUsage   `json:"build_cache"`
+}
+
+// collectUsage 获取并整理磁盘使用情况
+//
+// 返回：
+//   - 磁盘使用情况
+//   - 错误信息
+func collectUsage() (Usage, error) {
+	var usage Usage
+
+	diskUsage, err := general.DiskUsage()
+	if err != nil {
+		return usage, err
+	}
+	usage.LayersSize = diskUsage.LayersSize
+
+	// image 及 Repository
+	repositories := make(map[string]*RepositoryUsage)
+	for _, image := range diskUsage.Images {
+		info := newImageInfo(*image)
+		uniqueSize := int64(-1)
+		if image.SharedSize >= 0 {
+			uniqueSize = image.Size - image.SharedSize
+		}
+		usage.Images = append(usage.Images, ImageUsage{
+			Repository: info.Repo,
+			Tag:        info.Tag,
+			ID:         info.ID,
+			Size:       image.Size,
+			SharedSize: image.SharedSize,
+			UniqueSize: uniqueSize,
+			Containers: image.Containers,
+		})
+
+		// 同一 image 在同一 Repository 中的多个 Tag 只计算一次
+		counted := make(map[string]bool)
+		for _, repoTag := range image.RepoTags {
+			repo, _ := general.SplitRepoTag(repoTag)
+			if repo == "<none>" || counted[repo] {
+				continue
+			}
+			counted[repo] = true
+			if repositories[repo] == nil {
+				repositories[repo] = &RepositoryUsage{Repository: repo}
+			}
+			repositories[repo].Images++
+			repositories[repo].Size += image.Size
+			if uniqueSize > 0 {
+				repositories[repo].UniqueSize += uniqueSize
+			}
+		}
+	}
+	sort.SliceStable(usage.Images, func(i, j int) bool { return usage.Images[i].Size > usage.Images[j].Size })
+	for _, repository := range repositories {
+		usage.Repositories = append(usage.Repositories, *repository)
+	}
+	sort.SliceStable(usage.Repositories, func(i, j int) bool { return usage.Repositories[i].Size > usage.Repositories[j].Size })
+
+	// volume
+	for _, volume := range diskUsage.Volumes {
+		volumeUsage := VolumeUsage{Name: volume.Name, Driver: volume.Driver, Size: -1, RefCount: -1}
+		if volume.UsageData != nil {
+			volumeUsage.Size = volume.UsageData.Size
+			volumeUsage.RefCount = volume.UsageData.RefCount
+		}
+		usage.Volumes = append(usage.Volumes, volumeUsage)
+	}
+	sort.SliceStable(usage.Volumes, func(i, j int) bool { return usage.Volumes[i].Size > usage.Volumes[j].Size })
+
+	// 构建缓存
+	for _, cache := range diskUsage.BuildCache {
+		usage.BuildCache.Count++
+		usage.BuildCache.Size += cache.Size
+		if cache.InUse {
+			usage.BuildCache.InUse++
+		}
+		if cache.Shared {
+			usage.BuildCache.SharedSize += cache.Size
+		}
+		if !cache.InUse && !cache.Shared {
+			usage.BuildCache.Reclaimable += cache.Size
+		}
+	}
+
+	return usage, nil
+}
+
+// usageSize 格式化大小，-1 表示不可用
+func usageSize(size int64) string {
+	if size < 0 {
+		return "N/A"
+	}
+	return general.HumanSize(size)
+}
+
+// ShowUsage 输出磁盘使用情况
+//
+// 参数：
+//   - format: 输出格式，'table' 或 'json'
+func ShowUsage(format string) {
+	usage, err := collectUsage()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		data, err := json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		color.Println(string(data))
+	case "table":
+		// image
+		tableHeader := []string{"Repository", "Tag", "ID", "Size", "Shared", "Unique", "Containers"} // 表头
+		tableData := [][]string{}                                                                    // 表数据
+		for _, image := range usage.Images {
+			tableData = append(tableData, []string{image.Repository, image.Tag, image.ID[:idMinViewLength], usageSize(image.Size), usageSize(image.SharedSize), usageSize(image.UniqueSize), color.Sprint(image.Containers)})
+		}
+		color.Println(general.NewTable(tableHeader, tableData))
+
+		// Repository
+		tableHeader = []string{"Repository", "Images", "Size", "Unique"}
+		tableData = [][]string{}
+		for _, repository := range usage.Repositories {
+			tableData = append(tableData, []string{repository.Repository, color.Sprint(repository.Images), usageSize(repository.Size), usageSize(repository.UniqueSize)})
+		}
+		color.Println(general.NewTable(tableHeader, tableData))
+
+		// volume
+		tableHeader = []string{"Name", "Driver", "Size", "Ref Count"}
+		tableData = [][]string{}
+		for _, volume := range usage.Volumes {
+			refCount := "N/A"
+			if volume.RefCount >= 0 {
+				refCount = color.Sprint(volume.RefCount)
+			}
+			tableData = append(tableData, []string{volume.Name, volume.Driver, usageSize(volume.Size), refCount})
+		}
+		color.Println(general.NewTable(tableHeader, tableData))
+
+		// 汇总
+		var volumesSize int64
+		for _, volume := range usage.Volumes {
+			if volume.Size > 0 {
+				volumesSize += volume.Size
+			}
+		}
+		tableHeader = []string{"Type", "Count", "Size", "Detail"}
+		tableData = [][]string{
+			{"Images", color.Sprint(len(usage.Images)), usageSize(usage.LayersSize), "shared layers counted once"},
+			{"Volumes", color.Sprint(len(usage.Volumes)), usageSize(volumesSize), ""},
+			{"Build Cache", color.Sprint(usage.BuildCache.Count), usageSize(usage.BuildCache.Size), color.Sprintf("%d in use, %s shared, %s reclaimable", usage.BuildCache.InUse, strings.TrimSpace(usageSize(usage.BuildCache.SharedSize)), strings.TrimSpace(usageSize(usage.BuildCache.Reclaimable)))},
+		}
+		color.Println(general.NewTable(tableHeader, tableData))
+	default:
+		color.Printf("%s %s\n", general.DangerText(general.ErrorInfoFlag), color.Sprintf(general.UnsupportedFormatMessage, format))
+	}
+}
@@ -10,65 +10,181 @@ Description: 子命令 'volume' 的实现
 package cli
 
 import (
-	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
-	"github.com/charmbracelet/lipgloss"
-	"github.com/charmbracelet/lipgloss/table"
 	"github.com/gookit/color"
 	"github.com/yhyj/wocker/general"
 )
 
 // ListVolumes 输出所有 volume 的信息
-func ListVolumes() {
+//
+//   - 指定多个 context 时汇总输出，并增加 Host 列
+//
+// 参数：
+//   - contexts: docker context 名，为空时使用当前的 docker service
+func ListVolumes(contexts []string) {
+	tableHeader := []string{"Name", "Driver", "Mountpoint"} // 表头
+	tableData := [][]string{}                               // 表数据
+
+	if len(contexts) == 0 {
+		rows, err := volumeRows()
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		tableData = rows
+	} else {
+		tableHeader = append([]string{"Host"}, tableHeader...)
+		for _, contextName := range contexts {
+			err := general.WithContext(contextName, func() error {
+				rows, err := volumeRows()
+				for _, row := range rows {
+					tableData = append(tableData, append([]string{contextName}, row...))
+				}
+				return err
+			})
+			if err != nil {
+				color.Printf("%s %s: %s\n", general.DangerText(general.ErrorInfoFlag), general.FgBlueText(contextName), err)
+			}
+		}
+	}
+
+	dataTable := general.NewTable(tableHeader, tableData) // 创建一个表格
+
+	color.Println(dataTable)
+}
+
+// volumeRows 获取当前 docker service 中所有 volume 的表格行数据
+//
+// 返回：
+//   - 行数据切片，列为 Name、Driver、Mountpoint
+//   - 错误信息
+func volumeRows() ([][]string, error) {
 	// 获取 volume 列表
 	volumes, err := general.ListVolumes()
 	if err != nil {
-		fileName, lineNo := general.GetCallerInfo()
-		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-		return
+		return nil, err
 	}
 
-	tableHeader := []string{"Name", "Driver", "Mountpoint"} // 表头
-	tableData := [][]string{}                               // 表数据
-	rowData := []string{}                                   // 行数据
+	tableData := [][]string{} // 表数据
+	rowData := []string{}     // 行数据
 	for _, volume := range volumes.Volumes {
 		// 组装行数据
 		rowData = []string{volume.Name, volume.Driver, volume.Mountpoint}
 		tableData = append(tableData, rowData)
 	}
 
-	dataTable := table.New()                                // 创建一个表格
-	dataTable.Border(lipgloss.RoundedBorder())              // 设置表格边框
-	dataTable.BorderStyle(general.BorderStyle)              // 设置表格边框样式
-	dataTable.StyleFunc(func(row, col int) lipgloss.Style { // 按位置设置单元格样式
-		var style lipgloss.Style
+	return tableData, nil
+}
 
-		if row == 0 {
-			return general.HeaderStyle // 第一行为表头
-		}
+// identity volume 存档文件名中 volume 名之后的标识，由配置项 volume_identity 设置
+var identity = "volume"
 
-		return style
-	})
+// archiveFileExtension volume 存档文件扩展名，由配置项 volume_extension 设置
+var archiveFileExtension = ".tar.gz"
 
-	dataTable.Headers(tableHeader...) // 设置表头
-	dataTable.Rows(tableData...)      // 设置单元格
+// volumeArchiveFile 返回 volume 的存档文件名
+func volumeArchiveFile(volumeName string) string {
+	return color.Sprintf("%s_%s%s", volumeName, identity, archiveFileExtension)
+}
 
-	color.Println(dataTable)
+// selectVolumes 根据名称或模式从 volume 名称列表中选出匹配的 volume
+//
+// 参数：
+//   - volumeNames: 当前所有 volume 名称
+//   - names: volume 的 Name、模式或 'all'
+//   - option: 匹配选项
+//   - action: 当前操作名，用于输出信息
+//
+// 返回：
+//   - 匹配成功且未被排除的 volume 名称切片，已去重
+//   - 错误信息
+func selectVolumes(volumeNames []string, names []string, option general.MatchOption, action string) ([]string, error) {
+	selected, _, err := selectVolumesByName(volumeNames, names, option, action)
+	return selected, err
 }
 
-var identity = "volume"
+// selectVolumesByName 根据名称或模式从 volume 名称列表中选出匹配的 volume，同时返回没有选中 volume 的名称
+//
+// 参数：
+//   - volumeNames: 当前所有 volume 名称
+//   - names: volume 的 Name、模式或 'all'
+//   - option: 匹配选项
+//   - action: 当前操作名，用于输出信息
+//
+// 返回：
+//   - 匹配成功且未被排除的 volume 名称切片，已去重
+//   - 没有匹配到 volume 的名称
+//   - 错误信息
+func selectVolumesByName(volumeNames []string, names []string, option general.MatchOption, action string) ([]string, []string, error) {
+	var (
+		selected  []string // 选中的 volume 名称切片
+		unmatched []string // 没有选中 volume 的名称
+	)
 
-const archiveFileExtension = ".tar.gz" // volume 存档文件扩展名
+	// 参数中包含 'all'，选中所有 volume
+	if general.SliceContains(names, "all") {
+		names = []string{"*"}
+		option.Regex = false
+	}
+
+	for _, name := range names {
+		var matchingVolumes []string // 匹配成功的 volume 名称切片
+		for _, volumeName := range volumeNames {
+			var (
+				matched bool
+				err     error
+			)
+			if option.Regex || general.IsGlobPattern(name) {
+				matched, err = general.MatchName(name, volumeName, option.Regex)
+			} else {
+				matched = volumeName == name
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			if matched {
+				matchingVolumes = append(matchingVolumes, volumeName)
+			}
+		}
+
+		if len(matchingVolumes) == 0 {
+			color.Printf("%s %s %s -> %s\n", general.PackFlag, action, general.FgBlueText(name), general.DangerText(general.NoSuchVolumeMessage))
+			unmatched = append(unmatched, name)
+			continue
+		}
+
+		for _, volumeName := range matchingVolumes {
+			excluded, err := general.MatchAny(option.Exclude, volumeName, option.Regex)
+			if err != nil {
+				return nil, nil, err
+			}
+			if excluded || general.SliceContains(selected, volumeName) {
+				continue
+			}
+			selected = append(selected, volumeName)
+		}
+	}
+
+	return selected, unmatched, nil
+}
 
 // SaveVolumes 将指定 volumes 保存到各自存档文件
 //
 // 参数：
-//   - names: volume name，允许一次保存多个
-func SaveVolumes(names []string) {
+//   - names: volume name 或模式，允许一次保存多个
+//   - option: 匹配选项
+//   - setOption: 备份集选项
+//
+// 返回：
+//   - 是否全部保存成功
+func SaveVolumes(names []string, option general.MatchOption, setOption SetOption) bool {
 	if len(names) == 0 {
 		color.Printf(general.DangerText(general.SpecifyMessage), "volume", "save")
-		return
+		return false
 	}
 
 	// 获取 volume 列表
@@ -76,7 +192,7 @@ func SaveVolumes(names []string) {
 	if err != nil {
 		fileName, lineNo := general.GetCallerInfo()
 		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-		return
+		return false
 	}
 
 	// 获取当前所有 volume 名称
@@ -85,54 +201,219 @@ func SaveVolumes(names []string) {
 		volumeNames = append(volumeNames, volume.Name)
 	}
 
-	// 获取当前目录
-	currentDir, err := os.Getwd()
+	// 打开保存存档的存储
+	storage, err := outputStorage()
 	if err != nil {
 		fileName, lineNo := general.GetCallerInfo()
 		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-		return
+		return false
 	}
+	defer storage.Close()
 
-	// 参数 names 允许是 volume 的 Name 或 'all'
-	if general.SliceContains(names, "all") { // 参数中包含 'all'，将所有 volume 保存到各自存档文件
-		for _, volumeName := range volumeNames {
-			volumeArchiveFile := color.Sprintf("%s_%s%s", volumeName, identity, archiveFileExtension)
-			if err := general.SaveVolume(volumeName, currentDir, volumeArchiveFile); err != nil {
-				fileName, lineNo := general.GetCallerInfo()
-				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-				return
-			}
-			// 输出信息
-			color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(volumeName), general.FgMagentaText(volumeArchiveFile))
+	// 参数 names 允许是 volume 的 Name、模式或 'all'
+	selectedVolumes, unmatched, err := selectVolumesByName(volumeNames, names, option, "Save")
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+	// 没有选中任何 volume 时不创建备份集，避免空备份集参与保留策略
+	if len(selectedVolumes) == 0 {
+		return false
+	}
+
+	var archiveFiles []string // 与 selectedVolumes 一一对应的存档文件
+	for _, volumeName := range selectedVolumes {
+		archiveFile, err := volumeArchiveName(volumeName)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return false
 		}
-	} else { // 参数为 volume 的 Name
-		for _, name := range names {
-			if !general.SliceContains(volumeNames, name) {
-				color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(name), general.DangerText(general.NoSuchVolumeMessage))
-				continue
-			}
-			volumeArchiveFile := color.Sprintf("%s_%s%s", name, identity, archiveFileExtension)
-			if err := general.SaveVolume(name, currentDir, volumeArchiveFile); err != nil {
-				fileName, lineNo := general.GetCallerInfo()
-				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-				return
-			}
-			// 输出信息
-			color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(name), general.FgMagentaText(volumeArchiveFile))
+		archiveFiles = append(archiveFiles, archiveFile)
+	}
+
+	// 写入前检查存档文件名是否重复
+	if reportNameCollisions(selectedVolumes, archiveFiles) {
+		return false
+	}
+
+	// 有名称没有选中 volume 时备份集不完整，不完成备份集也不执行保留策略
+	skipped := setOption.RequireMatch && len(unmatched) > 0
+
+	// 启用备份集时保存到本次运行的备份集文件夹
+	saveDir, err := startBackupSet(storage, backupSetVolume, setOption)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+
+	// 结束时将本次运行的保存记录写入目录
+	var (
+		records   []general.CatalogRecord
+		completed bool
+	)
+	defer func() { catalogSaves(records, storage, saveDir, setOption, completed) }()
+
+	for index, volumeName := range selectedVolumes {
+		// 模板中可以包含子文件夹
+		record, err := saveVolumeArchive(storage, volumeName, path.Join(saveDir, filepath.ToSlash(archiveFiles[index])))
+		records = append(records, record)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return false
 		}
+		// 输出信息
+		color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(volumeName), general.FgMagentaText(record.Archive))
+	}
+
+	if skipped {
+		return false
 	}
+	completed = finishBackupSet(storage, saveDir, backupSetVolume, setOption)
+	return completed
+}
+
+// saveVolumeArchive 将 volume 保存到存储
+//
+//   - 本机的 docker service 保存到本地存储时通过挂载输出文件夹保存
+//   - 远程 docker service、远程存储和拆分为分卷时经由 docker API 读取后写入，避免挂载路径被解析到远程主机上
+//
+// 参数：
+//   - storage: 保存存档的存储
+//   - volumeName: volume 名
+//   - name: 存档在存储中的路径
+//
+// 返回：
+//   - 保存记录
+//   - 错误信息
+func saveVolumeArchive(storage general.Storage, volumeName string, name string) (general.CatalogRecord, error) {
+	storedName := general.StoredArchiveName(name)
+	record := general.NewCatalogRecord(general.CatalogSave, backupSetVolume, volumeName, storage.Location(storedName))
+	var err error
+	if archivePath, local := general.LocalPath(storage, name); local && storedName == name && general.IsLocalDaemon() {
+		archiveDir := filepath.Dir(archivePath)
+		if err = general.CreateFolder(archiveDir); err == nil {
+			err = general.SaveVolume(volumeName, archiveDir, filepath.Base(archivePath))
+		}
+		if err == nil {
+			record.Digest, err = general.FileDigest(archivePath)
+		}
+	} else {
+		record.Digest, err = general.SaveVolumeToStorage(volumeName, storage, name)
+		setArchiveSize(&record, storage, storedName)
+	}
+	record.Finish(err)
+	return record, err
+}
+
+// volumeArchiveName 按配置项 volume_name_template 返回 volume 的存档文件名
+//
+//   - 未设置模板时使用 volumeArchiveFile 的命名方式
+//   - 模板生成的文件名不以存档扩展名结尾时追加扩展名
+//
+// 参数：
+//   - volumeName: volume 名
+//
+// 返回：
+//   - 相对于输出文件夹的存档文件路径
+//   - 错误信息
+func volumeArchiveName(volumeName string) (string, error) {
+	nameTemplate := general.CurrentSettings.VolumeTemplate
+	if nameTemplate == "" {
+		return volumeArchiveFile(volumeName), nil
+	}
+
+	data := general.VolumeNameData{
+		Name:     general.SanitizeFileName(volumeName),
+		Identity: general.SanitizeFileName(identity),
+		Date:     general.GetCurrentTimestamp("20060102"),
+		Time:     general.GetCurrentTimestamp("150405"),
+	}
+	name, err := general.RenderName(nameTemplate, data)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(name, archiveFileExtension) {
+		name += archiveFileExtension
+	}
+	return name, nil
+}
+
+// volumeBackupFile 返回恢复前安全备份的存档文件名，避免覆盖待恢复的存档
+func volumeBackupFile(volumeName string) string {
+	return color.Sprintf("%s_%s-backup-%s%s", volumeName, identity, general.GetCurrentTimestamp("20060102150405"), archiveFileExtension)
+}
+
+// volumeNameFromArchive 从存档文件名中解析 volume 名
+//
+//   - 设置了配置项 volume_name_template 且路径符合模板时，按模板从路径中解析，{{.Name}} 可以位于文件夹名中
+//   - 否则存档文件名的格式为 '<volume>_<identity>[<后缀>]<extension>'，不含 '_<identity>' 时使用去掉扩展名的文件名
+//
+// 参数：
+//   - file: 存档文件
+//
+// 返回：
+//   - volume 名
+func volumeNameFromArchive(file string) string {
+	if nameTemplate := general.CurrentSettings.VolumeTemplate; nameTemplate != "" {
+		if name, ok := general.VolumeNameFromPath(nameTemplate, identity, archiveFileExtension, file); ok {
+			return name
+		}
+	}
+	name := strings.TrimSuffix(filepath.Base(file), archiveFileExtension)
+	if index := strings.LastIndex(name, "_"+identity); index > 0 {
+		return name[:index]
+	}
+	return name
+}
+
+// RestoreOption 恢复 volume 的选项
+type RestoreOption struct {
+	Mode   string // 恢复到已存在的 volume 时的处理方式，为空时拒绝恢复到已存在的 volume
+	Backup bool   // 恢复到已存在的 volume 前先保存到存档文件
+	Force  bool   // 清空 volume 前不请求确认
+}
+
+// volumeLoadRecord 创建加载 volume 存档的目录记录，摘要为存档的 sha256
+//
+// 参数：
+//   - volumeName: volume 名
+//   - file: 存档文件或远程存档的 URL
+//   - localFile: 可在本地访问的存档文件，远程存档和分卷存档为拼接后的临时文件
+//
+// 返回：
+//   - 记录
+func volumeLoadRecord(volumeName string, file string, localFile string) general.CatalogRecord {
+	record := general.NewCatalogRecord(general.CatalogLoad, backupSetVolume, volumeName, file)
+	record.Digest, _ = general.FileDigest(localFile)
+	record.Size, _ = general.PathSize(localFile)
+	return record
 }
 
 // LoadVolumes 从存档文件加载 volume
 //
+//   - volume 已存在时按 option.Mode 处理，未指定时跳过
+//   - 远程存档先下载到临时文件夹，每个存档加载结束后删除
+//
 // 参数：
-//   - files: 存档文件名，允许一次加载多个
-func LoadVolumes(files []string) {
+//   - files: 存档文件名或远程存档的 URL，允许一次加载多个
+//   - option: 恢复选项
+func LoadVolumes(files []string, option RestoreOption) {
 	if len(files) == 0 {
 		color.Printf(general.DangerText(general.SpecifyMessage), "volume archive file", "load")
 		return
 	}
 
+	switch option.Mode {
+	case "", general.RestoreModeWipe, general.RestoreModeOverwrite, general.RestoreModeMissing:
+	default:
+		color.Printf("%s\n", general.DangerText(color.Sprintf(general.UnsupportedFormatMessage, option.Mode)))
+		return
+	}
+
 	// 获取 volume 列表
 	volumes, err := general.ListVolumes()
 	if err != nil {
@@ -147,35 +428,131 @@ func LoadVolumes(files []string) {
 		volumeNames = append(volumeNames, volume.Name)
 	}
 
-	// 获取当前目录
-	currentDir, err := os.Getwd()
+	// 打开保存存档的存储，用于保存安全备份
+	storage, err := outputStorage()
 	if err != nil {
 		fileName, lineNo := general.GetCallerInfo()
 		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
 		return
 	}
+	defer storage.Close()
+
+	// 结束时将加载记录写入目录
+	var records []general.CatalogRecord
+	defer func() { recordCatalog(records) }()
 
 	for _, file := range files {
-		// 排除非存档文件
-		if !strings.HasSuffix(file, archiveFileExtension) {
+		// 排除非存档文件，分卷存档按去掉分卷后缀的文件名判断
+		archiveName := general.TrimPartSuffix(file)
+		if !strings.HasSuffix(archiveName, archiveFileExtension) {
 			color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(general.NotVolumeArchiveMessage))
 			continue
 		}
 
-		volumeName := strings.Split(file, archiveFileExtension)[0]
-
-		// 排除已存在的 volume
-		if general.SliceContains(volumeNames, volumeName) {
-			color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(general.VolumeExistMessage))
-			continue
+		fileRecords, ok := loadVolumeFile(storage, file, volumeNames, option)
+		records = append(records, fileRecords...)
+		if !ok {
+			return
 		}
+	}
+}
+
+// restoreVolumeFile 将本地存档文件恢复到 volume
+//
+//   - 本机的 docker service 挂载存档所在文件夹恢复，远程 docker service 经由 docker API 传输存档内容
+//
+// 参数：
+//   - volumeName: volume 名
+//   - localFile: 本地存档文件路径
+//   - mode: 恢复方式
+//
+// 返回：
+//   - 错误信息
+func restoreVolumeFile(volumeName string, localFile string, mode string) error {
+	if !general.IsLocalDaemon() {
+		return general.RestoreVolumeFromArchive(volumeName, localFile, mode)
+	}
+	return general.RestoreVolume(volumeName, filepath.Dir(localFile), filepath.Base(localFile), mode)
+}
 
-		if err := general.LoadVolume(volumeName, currentDir, file); err != nil {
+// loadVolumeFile 从一个存档文件加载 volume，下载或拼接的临时文件在返回前删除
+//
+// 参数：
+//   - storage: 保存安全备份的存储
+//   - file: 存档文件名或远程存档的 URL
+//   - volumeNames: 当前所有 volume 名称
+//   - option: 恢复选项
+//
+// 返回：
+//   - 保存和加载记录
+//   - 是否继续加载其余存档
+func loadVolumeFile(storage general.Storage, file string, volumeNames []string, option RestoreOption) ([]general.CatalogRecord, bool) {
+	var records []general.CatalogRecord
+	volumeName := volumeNameFromArchive(general.TrimPartSuffix(file))
+
+	// 存档所在文件夹挂载到临时容器中，远程存档和分卷存档先拼接到临时文件
+	localFile, cleanup, err := general.FetchLocation(file)
+	if err != nil {
+		color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(err))
+		return records, true
+	}
+	defer cleanup()
+
+	// volume 不存在，直接加载
+	if !general.SliceContains(volumeNames, volumeName) {
+		record := volumeLoadRecord(volumeName, file, localFile)
+		err := restoreVolumeFile(volumeName, localFile, general.RestoreModeOverwrite)
+		record.Finish(err)
+		records = append(records, record)
+		if err != nil {
 			fileName, lineNo := general.GetCallerInfo()
 			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
-			return
+			return records, false
 		}
 		// 输出信息
-		color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.FgMagentaText(file))
+		color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.FgMagentaText(volumeName))
+		return records, true
+	}
+
+	// volume 已存在，未指定恢复方式时跳过
+	if option.Mode == "" {
+		color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(general.VolumeExistMessage))
+		return records, true
+	}
+
+	// 清空 volume 前请求确认
+	if option.Mode == general.RestoreModeWipe && !option.Force {
+		confirmed, err := general.Confirm(color.Sprintf("Wipe volume %s and restore it from %s?", volumeName, file))
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return records, false
+		}
+		if !confirmed {
+			return records, true
+		}
+	}
+
+	// 安全备份
+	if option.Backup {
+		backupRecord, err := saveVolumeArchive(storage, volumeName, volumeBackupFile(volumeName))
+		records = append(records, backupRecord)
+		if err != nil {
+			color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(err))
+			return records, true
+		}
+		color.Printf("%s Save %s -> %s\n", general.PackFlag, general.FgBlueText(volumeName), general.FgMagentaText(backupRecord.Archive))
+	}
+
+	record := volumeLoadRecord(volumeName, file, localFile)
+	err = restoreVolumeFile(volumeName, localFile, option.Mode)
+	record.Finish(err)
+	records = append(records, record)
+	if err != nil {
+		color.Printf("%s Load %s -> %s\n", general.LoadFlag, general.FgBlueText(file), general.DangerText(err))
+		return records, true
 	}
+	// 输出信息
+	color.Printf("%s Load %s -> %s (%s)\n", general.LoadFlag, general.FgBlueText(file), general.FgMagentaText(volumeName), option.Mode)
+	return records, true
 }
@@ -0,0 +1,62 @@
+/*
+File: catalog.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 22:54:30
+
+Description: 执行子命令 'catalog'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+	"github.com/yhyj/wocker/general"
+)
+
+// catalogCmd represents the catalog command
+var catalogCmd = &cobra.Command{
+	Use:   "catalog [item...]",
+	Short: "Query the catalog of saved and loaded archives",
+	Long:  `Every image and volume save and load is recorded in a local catalog under $XDG_STATE_HOME/wocker with its digest, archive path, size, duration, docker host and result. List the records, optionally filtered by image or volume name patterns, find the latest good backup of each item, or spot archives that no longer exist on disk.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		kindFlag, _ := cmd.Flags().GetString("kind")
+		operationFlag, _ := cmd.Flags().GetString("operation")
+		failedFlag, _ := cmd.Flags().GetBool("failed")
+		regexFlag, _ := cmd.Flags().GetBool("regex")
+		limitFlag, _ := cmd.Flags().GetInt("limit")
+		latestFlag, _ := cmd.Flags().GetBool("latest")
+		missingFlag, _ := cmd.Flags().GetBool("missing")
+		forgetFlag, _ := cmd.Flags().GetBool("forget")
+
+		filter := general.CatalogFilter{Items: args, Regex: regexFlag, Kind: kindFlag, Operation: operationFlag}
+		if failedFlag {
+			filter.Result = general.CatalogFailed
+		}
+
+		switch {
+		case latestFlag:
+			cli.LatestBackups(filter)
+		case missingFlag:
+			cli.MissingArchives(filter, forgetFlag)
+		default:
+			cli.ListCatalog(filter, limitFlag)
+		}
+	},
+}
+
+func init() {
+	catalogCmd.Flags().String("kind", "", "Only show records of 'image' or 'volume'")
+	catalogCmd.Flags().String("operation", "", "Only show records of 'save' or 'load'")
+	catalogCmd.Flags().Bool("failed", false, "Only show failed operations")
+	catalogCmd.Flags().Bool("regex", false, "Treat item names as regular expressions")
+	catalogCmd.Flags().Int("limit", 20, "Maximum number of records to show, 0 for all")
+	catalogCmd.Flags().Bool("latest", false, "Show the latest successful save of each item whose archive still exists")
+	catalogCmd.Flags().Bool("missing", false, "Show saved archives that no longer exist on disk")
+	catalogCmd.Flags().Bool("forget", false, "Remove the records of missing archives from the catalog, use with '--missing'")
+
+	catalogCmd.Flags().BoolP("help", "h", false, "help for catalog command")
+	rootCmd.AddCommand(catalogCmd)
+}
@@ -0,0 +1,53 @@
+/*
+File: config.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 18:40:03
+
+Description: 执行子命令 'config'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Operate the configuration file",
+	Long:  `Create, print or check the configuration file. Settings are taken from command line flags first, then WOCKER_* environment variables (for example WOCKER_OUTPUT_DIR), then the selected profile of the configuration file, then the defaults.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		configFlag, _ := cmd.Flags().GetString("config")
+		profileFlag, _ := cmd.Flags().GetString("profile")
+		createFlag, _ := cmd.Flags().GetBool("create")
+		forceFlag, _ := cmd.Flags().GetBool("force")
+		printFlag, _ := cmd.Flags().GetBool("print")
+		checkFlag, _ := cmd.Flags().GetBool("check")
+
+		if createFlag {
+			cli.CreateConfig(configFlag, forceFlag)
+		}
+
+		if printFlag {
+			cli.PrintConfig(configFlag, profileFlag)
+		}
+
+		if checkFlag {
+			cli.CheckConfig(configFlag)
+		}
+	},
+}
+
+func init() {
+	configCmd.Flags().Bool("create", false, "Create a configuration file with a default profile")
+	configCmd.Flags().Bool("force", false, "Overwrite the existing configuration file when creating")
+	configCmd.Flags().Bool("print", false, "Print the configuration file and the effective settings with their sources")
+	configCmd.Flags().Bool("check", false, "Check the configuration file for syntax errors, unknown keys and invalid values")
+
+	configCmd.Flags().BoolP("help", "h", false, "help for config command")
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,41 @@
+/*
+File: diff.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:31:48
+
+Description: 执行子命令 'diff'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare two images, or a volume with a volume archive",
+	Long:  `Compare the layers, config and files of two images, each one can be a local image or an image archive, for example: 'diff app:1.4 app:1.5' or 'diff app_1.4_0123456789ab.dockerimage app:1.5'. With '--volume', compare a live volume with a volume archive to see what restoring it would change, for example: 'diff --volume volume1 volume1_volume.tar.gz'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		volumeFlag, _ := cmd.Flags().GetBool("volume")
+		summaryFlag, _ := cmd.Flags().GetBool("summary")
+
+		if volumeFlag {
+			cli.DiffVolume(args, summaryFlag)
+		} else {
+			cli.DiffImages(args, summaryFlag)
+		}
+	},
+}
+
+func init() {
+	diffCmd.Flags().Bool("volume", false, "Compare a volume with a volume archive instead of two images")
+	diffCmd.Flags().Bool("summary", false, "Only show the number of changed files instead of listing them")
+
+	diffCmd.Flags().BoolP("help", "h", false, "help for diff command")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -10,8 +10,10 @@ Description: 执行子命令 'image'
 package cmd
 
 import (
+	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 	"github.com/yhyj/wocker/cli"
+	"github.com/yhyj/wocker/general"
 )
 
 // imageCmd represents the image command
@@ -22,27 +24,117 @@ var imageCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		// 解析参数
 		listFlag, _ := cmd.Flags().GetBool("list")
+		contextsFlag, _ := cmd.Flags().GetStringSlice("contexts")
 		saveFlag, _ := cmd.Flags().GetBool("save")
 		loadFlag, _ := cmd.Flags().GetBool("load")
+		pruneFlag, _ := cmd.Flags().GetBool("prune")
+		inspectFlag, _ := cmd.Flags().GetBool("inspect")
+		pushFlag, _ := cmd.Flags().GetBool("push")
+		pullFlag, _ := cmd.Flags().GetBool("pull")
+		regexFlag, _ := cmd.Flags().GetBool("regex")
+		excludeFlag, _ := cmd.Flags().GetStringSlice("exclude")
+		formatFlag, _ := cmd.Flags().GetString("format")
+		registryFlag, _ := cmd.Flags().GetString("registry")
+		platformFlag, _ := cmd.Flags().GetString("platform")
+		keepFlag, _ := cmd.Flags().GetInt("keep")
+		danglingFlag, _ := cmd.Flags().GetBool("dangling")
+		olderThanFlag, _ := cmd.Flags().GetString("older-than")
+		dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
+		backupFlag, _ := cmd.Flags().GetBool("backup")
+		forceFlag, _ := cmd.Flags().GetBool("force")
+
+		setFlag, _ := cmd.Flags().GetBool("set")
+		keepLastFlag, _ := cmd.Flags().GetInt("keep-last")
+		keepDailyFlag, _ := cmd.Flags().GetInt("keep-daily")
+		keepWeeklyFlag, _ := cmd.Flags().GetInt("keep-weekly")
+		keepMonthlyFlag, _ := cmd.Flags().GetInt("keep-monthly")
+
+		matchOption := general.MatchOption{Regex: regexFlag, Exclude: excludeFlag}
+		retention := general.RetentionPolicy{Last: keepLastFlag, Daily: keepDailyFlag, Weekly: keepWeeklyFlag, Monthly: keepMonthlyFlag}
+		setOption := cli.SetOption{Enabled: setFlag || retention.Enabled(), Retention: retention}
+
+		// 命令行参数优先于配置
+		if cmd.Flags().Changed("output-dir") {
+			general.CurrentSettings.OutputDir, _ = cmd.Flags().GetString("output-dir")
+		}
+		if cmd.Flags().Changed("name-template") {
+			general.CurrentSettings.ImageTemplate, _ = cmd.Flags().GetString("name-template")
+		}
+		if cmd.Flags().Changed("split-size") {
+			general.CurrentSettings.SplitSize, _ = cmd.Flags().GetString("split-size")
+		}
+		if err := general.CurrentSettings.Validate(); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
 
 		if listFlag {
-			cli.ListImages()
+			cli.ListImages(contextsFlag)
+		}
+
+		if inspectFlag {
+			cli.ShowImageDetails(args, matchOption)
 		}
 
 		if saveFlag {
-			cli.SaveImages(args)
+			cli.SaveImages(args, matchOption, formatFlag, platformFlag, setOption)
 		}
 
 		if loadFlag {
 			cli.LoadImages(args)
 		}
+
+		if pushFlag {
+			cli.PushImages(args, matchOption, registryFlag)
+		}
+
+		if pullFlag {
+			cli.PullImages(args, registryFlag)
+		}
+
+		if pruneFlag {
+			olderThan, err := general.ParseDuration(olderThanFlag)
+			if olderThanFlag != "" && err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				return
+			}
+			pruneOption := cli.PruneOption{Keep: keepFlag, Dangling: danglingFlag, OlderThan: olderThan, DryRun: dryRunFlag, Backup: backupFlag, Force: forceFlag}
+			cli.PruneImages(args, matchOption, pruneOption)
+		}
 	},
 }
 
 func init() {
 	imageCmd.Flags().Bool("list", false, "List all local images")
-	imageCmd.Flags().Bool("save", false, "Save one or more images with TAG and ID to a tar archive, for example: '--save image1 image2:tag' or '--save all'")
-	imageCmd.Flags().Bool("load", false, "Load an image from a tar archive, for example: '--load image1_archive image2_archive'")
+	imageCmd.Flags().StringSlice("contexts", []string{}, "List images of several docker contexts in one table with a Host column, for example: '--list --contexts default,server1'")
+	imageCmd.Flags().Bool("inspect", false, "Show the config, build history and layers of one or more images, and flag large or wasteful layers, for example: '--inspect image1:tag'")
+	imageCmd.Flags().Bool("save", false, "Save one or more images with TAG and ID to a tar archive, for example: '--save image1 image2:tag', '--save \"myorg/*:1.*\"' or '--save all'")
+	imageCmd.Flags().Bool("load", false, "Load an image from a tar archive or an OCI image layout, for example: '--load image1_archive image2.oci'")
+	imageCmd.Flags().Bool("push", false, "Retag one or more images under the registry prefix and push them, selected like '--save', for example: '--push --registry localhost:5000/backup \"myorg/*\"'")
+	imageCmd.Flags().Bool("pull", false, "Pull the images listed in reference list files, one reference per line, for example: '--pull images.list'")
+	imageCmd.Flags().Bool("prune", false, "Remove images by retention rules, always previews the images to remove first, for example: '--prune --keep 3 --dangling'")
+	imageCmd.Flags().Bool("regex", false, "Treat image names as regular expressions matched against 'REPOSITORY:TAG'")
+	imageCmd.Flags().StringSlice("exclude", []string{}, "Exclude images matching the pattern, can be specified multiple times, for example: '--exclude \"*:latest\"'")
+	imageCmd.Flags().String("format", "docker", "Archive format when saving, 'docker' (docker save tar), 'oci' (OCI image layout folder) or 'oci-archive' (OCI image layout tar)")
+	imageCmd.Flags().String("platform", "", "Only keep the platform in the archive when saving, for example: 'linux/arm64' or 'linux/arm/v7', keeps all local platforms if not specified")
+	imageCmd.Flags().String("registry", "", "Registry prefix to push to or pull from, for example: 'localhost:5000/backup'")
+	imageCmd.Flags().Int("keep", 0, "Keep the N newest tags of each repository when pruning")
+	imageCmd.Flags().Bool("dangling", false, "Remove dangling images when pruning, only those selected by ID prefix when image names are given, otherwise all on the host")
+	imageCmd.Flags().String("older-than", "", "Only remove images created earlier than the duration when pruning, for example: '72h', '30d' or '2w'")
+	imageCmd.Flags().Bool("dry-run", false, "Only preview the images to remove when pruning")
+	imageCmd.Flags().Bool("backup", false, "Save images to tar archives before removing them when pruning")
+	imageCmd.Flags().Bool("force", false, "Do not ask for confirmation before removing images")
+
+	imageCmd.Flags().Bool("set", false, "Save into a new timestamped backup set folder 'image-<YYYYMMDDhhmmss>' under the output folder")
+	imageCmd.Flags().Int("keep-last", 0, "After a successful '--set' run, keep the N newest image backup sets and remove the others not kept by other '--keep-*' rules")
+	imageCmd.Flags().Int("keep-daily", 0, "After a successful '--set' run, keep the newest image backup set of each of the last N days")
+	imageCmd.Flags().Int("keep-weekly", 0, "After a successful '--set' run, keep the newest image backup set of each of the last N weeks")
+	imageCmd.Flags().Int("keep-monthly", 0, "After a successful '--set' run, keep the newest image backup set of each of the last N months")
+	imageCmd.Flags().String("output-dir", "", "Folder or storage URL to save archives to, defaults to the 'output_dir' setting or the current folder")
+	imageCmd.Flags().String("name-template", "", "Go template for archive names, '/' creates subfolders, fields: Repo, Tag, ID, Platform, Date, Time, for example: '{{.Repo}}/{{.Tag}}-{{.Date}}'")
+	imageCmd.Flags().String("split-size", "", "Split archives into numbered parts of at most this size with an index file, for example: '4GB' for FAT32 or '4.7GB' for DVDs")
 
 	imageCmd.Flags().BoolP("help", "h", false, "help for image command")
 	rootCmd.AddCommand(imageCmd)
@@ -0,0 +1,30 @@
+/*
+File: inspect.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 13:15:02
+
+Description: 执行子命令 'inspect-archive'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+)
+
+// inspectArchiveCmd represents the inspect-archive command
+var inspectArchiveCmd = &cobra.Command{
+	Use:   "inspect-archive",
+	Short: "Inspect image or volume archives",
+	Long:  `Read image archives (docker save tar) or volume archives offline and show what is inside, without a docker service, for example: 'inspect-archive image_archive volume_archive'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.InspectArchives(args)
+	},
+}
+
+func init() {
+	inspectArchiveCmd.Flags().BoolP("help", "h", false, "help for inspect-archive command")
+	rootCmd.AddCommand(inspectArchiveCmd)
+}
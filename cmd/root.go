@@ -12,7 +12,10 @@ package cmd
 import (
 	"os"
 
+	"github.com/gookit/color"
 	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+	"github.com/yhyj/wocker/general"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -23,6 +26,35 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		configFlag, _ := cmd.Flags().GetString("config")
+		profileFlag, _ := cmd.Flags().GetString("profile")
+		contextFlag, _ := cmd.Flags().GetString("context")
+		hostFlag, _ := cmd.Flags().GetString("host")
+
+		// 读取配置，子命令 'config' 自行处理配置文件中的错误
+		if _, err := general.LoadSettings(configFlag, profileFlag); err != nil && cmd != configCmd {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			os.Exit(1)
+		}
+		cli.ApplySettings()
+
+		// 命令行参数优先于配置
+		if contextFlag == "" && hostFlag == "" {
+			contextFlag, hostFlag = general.CurrentSettings.Context, general.CurrentSettings.Host
+		}
+
+		// 切换 docker service
+		if contextFlag != "" || hostFlag != "" {
+			if err := general.UseDocker(contextFlag, hostFlag); err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				os.Exit(1)
+			}
+		}
+	},
 }
 
 func Execute() {
@@ -33,5 +65,9 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("config", general.ConfigFile(), "Configuration file")
+	rootCmd.PersistentFlags().String("profile", "", "Profile in the configuration file to use, overrides WOCKER_PROFILE and the 'profile' key")
+	rootCmd.PersistentFlags().String("context", "", "Name of the docker context to use, overrides DOCKER_HOST and the current context")
+	rootCmd.PersistentFlags().String("host", "", "Docker service to connect to, for example: 'ssh://user@server' or 'tcp://server:2376' (TLS from DOCKER_CERT_PATH)")
 	rootCmd.Flags().BoolP("help", "h", false, "help for wocker")
 }
@@ -0,0 +1,49 @@
+/*
+File: schedule.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 21:40:52
+
+Description: 执行子命令 'schedule'
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+)
+
+// scheduleCmd represents the schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule [job...]",
+	Short: "Run scheduled backups",
+	Long:  `List, run or serve the backup jobs defined in the [jobs.<name>] tables of the configuration file. Each job saves the selected images or volumes into a timestamped backup set and applies its retention policy. Runs of the same job never overlap, and the result of every run is recorded in status.json and history.log under $XDG_STATE_HOME/wocker.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		configFlag, _ := cmd.Flags().GetString("config")
+		runFlag, _ := cmd.Flags().GetBool("run")
+		serveFlag, _ := cmd.Flags().GetBool("serve")
+
+		switch {
+		case serveFlag:
+			cli.ServeJobs(configFlag)
+		case runFlag:
+			if !cli.RunJobs(configFlag, args) {
+				os.Exit(1)
+			}
+		default:
+			cli.ListJobs(configFlag)
+		}
+	},
+}
+
+func init() {
+	scheduleCmd.Flags().Bool("run", false, "Run the specified jobs now")
+	scheduleCmd.Flags().Bool("serve", false, "Keep running and start each job on its schedule, stop on SIGINT or SIGTERM")
+
+	scheduleCmd.Flags().BoolP("help", "h", false, "help for schedule command")
+	rootCmd.AddCommand(scheduleCmd)
+}
@@ -0,0 +1,54 @@
+/*
+File: transfer.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:24:37
+
+Description: 执行子命令 'transfer'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+	"github.com/yhyj/wocker/general"
+)
+
+// transferCmd represents the transfer command
+var transferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Transfer images or volumes directly to another docker service",
+	Long:  `Stream images from the current docker service (see '--context' and '--host') into another one without intermediate files, for example: 'transfer --to-context prod app:1.5' or 'transfer --host ssh://user@build --to-host ssh://user@prod app:1.5'. Layers the target already has are skipped when the target uses the classic image store. With '--volume', migrate volumes to volumes of the same name on the target, created with the same driver and labels and verified by checksums, for example: 'transfer --volume --to-context prod volume1'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		toContextFlag, _ := cmd.Flags().GetString("to-context")
+		toHostFlag, _ := cmd.Flags().GetString("to-host")
+		compressFlag, _ := cmd.Flags().GetBool("compress")
+		if !cmd.Flags().Changed("compress") {
+			compressFlag = general.CurrentSettings.Compress
+		}
+		volumeFlag, _ := cmd.Flags().GetBool("volume")
+		regexFlag, _ := cmd.Flags().GetBool("regex")
+		excludeFlag, _ := cmd.Flags().GetStringSlice("exclude")
+
+		matchOption := general.MatchOption{Regex: regexFlag, Exclude: excludeFlag}
+		if volumeFlag {
+			cli.MigrateVolumes(args, matchOption, toContextFlag, toHostFlag, compressFlag)
+		} else {
+			cli.TransferImages(args, matchOption, toContextFlag, toHostFlag, compressFlag)
+		}
+	},
+}
+
+func init() {
+	transferCmd.Flags().String("to-context", "", "Name of the docker context to transfer to")
+	transferCmd.Flags().String("to-host", "", "Docker service to transfer to, for example: 'ssh://user@server', overrides '--to-context'")
+	transferCmd.Flags().Bool("volume", false, "Transfer volumes instead of images")
+	transferCmd.Flags().Bool("compress", false, "Compress the data with gzip on the way, useful on slow links, defaults to the 'compress' setting")
+	transferCmd.Flags().Bool("regex", false, "Treat image or volume names as regular expressions, image names are matched against 'REPOSITORY:TAG'")
+	transferCmd.Flags().StringSlice("exclude", []string{}, "Exclude images or volumes matching the pattern, can be specified multiple times, for example: '--exclude \"*:latest\"'")
+
+	transferCmd.Flags().BoolP("help", "h", false, "help for transfer command")
+	rootCmd.AddCommand(transferCmd)
+}
@@ -0,0 +1,35 @@
+/*
+File: usage.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:02:31
+
+Description: 执行子命令 'usage'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/wocker/cli"
+)
+
+// usageCmd represents the usage command
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show docker disk usage",
+	Long:  `Show disk usage of images, repositories, volumes and build cache, with unique and shared sizes of images.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		formatFlag, _ := cmd.Flags().GetString("format")
+
+		cli.ShowUsage(formatFlag)
+	},
+}
+
+func init() {
+	usageCmd.Flags().String("format", "table", "Output format, 'table' or 'json'")
+
+	usageCmd.Flags().BoolP("help", "h", false, "help for usage command")
+	rootCmd.AddCommand(usageCmd)
+}
@@ -10,8 +10,10 @@ Description: 执行子命令 'volume'
 package cmd
 
 import (
+	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 	"github.com/yhyj/wocker/cli"
+	"github.com/yhyj/wocker/general"
 )
 
 // volumeCmd represents the volume command
@@ -22,27 +24,142 @@ var volumeCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		// 解析参数
 		listFlag, _ := cmd.Flags().GetBool("list")
+		contextsFlag, _ := cmd.Flags().GetStringSlice("contexts")
 		saveFlag, _ := cmd.Flags().GetBool("save")
 		loadFlag, _ := cmd.Flags().GetBool("load")
+		pruneFlag, _ := cmd.Flags().GetBool("prune")
+		regexFlag, _ := cmd.Flags().GetBool("regex")
+		excludeFlag, _ := cmd.Flags().GetStringSlice("exclude")
+		unusedFlag, _ := cmd.Flags().GetBool("unused")
+		olderThanFlag, _ := cmd.Flags().GetString("older-than")
+		dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
+		backupFlag, _ := cmd.Flags().GetBool("backup")
+		forceFlag, _ := cmd.Flags().GetBool("force")
+		restoreFlag, _ := cmd.Flags().GetString("restore")
+		cloneFlag, _ := cmd.Flags().GetBool("clone")
+		renameFlag, _ := cmd.Flags().GetBool("rename")
+		exportFlag, _ := cmd.Flags().GetBool("export")
+		importFlag, _ := cmd.Flags().GetBool("import")
+		fromDirFlag, _ := cmd.Flags().GetString("from-dir")
+		includePathFlag, _ := cmd.Flags().GetStringSlice("include-path")
+		excludePathFlag, _ := cmd.Flags().GetStringSlice("exclude-path")
+		listArchiveFlag, _ := cmd.Flags().GetBool("list-archive")
+		extractFlag, _ := cmd.Flags().GetBool("extract")
+		pathFlag, _ := cmd.Flags().GetStringSlice("path")
+		toDirFlag, _ := cmd.Flags().GetString("to-dir")
+		toVolumeFlag, _ := cmd.Flags().GetString("to-volume")
+
+		setFlag, _ := cmd.Flags().GetBool("set")
+		keepLastFlag, _ := cmd.Flags().GetInt("keep-last")
+		keepDailyFlag, _ := cmd.Flags().GetInt("keep-daily")
+		keepWeeklyFlag, _ := cmd.Flags().GetInt("keep-weekly")
+		keepMonthlyFlag, _ := cmd.Flags().GetInt("keep-monthly")
+
+		matchOption := general.MatchOption{Regex: regexFlag, Exclude: excludeFlag}
+		retention := general.RetentionPolicy{Last: keepLastFlag, Daily: keepDailyFlag, Weekly: keepWeeklyFlag, Monthly: keepMonthlyFlag}
+		setOption := cli.SetOption{Enabled: setFlag || retention.Enabled(), Retention: retention}
+
+		// 命令行参数优先于配置
+		if cmd.Flags().Changed("output-dir") {
+			general.CurrentSettings.OutputDir, _ = cmd.Flags().GetString("output-dir")
+		}
+		if cmd.Flags().Changed("name-template") {
+			general.CurrentSettings.VolumeTemplate, _ = cmd.Flags().GetString("name-template")
+		}
+		if cmd.Flags().Changed("split-size") {
+			general.CurrentSettings.SplitSize, _ = cmd.Flags().GetString("split-size")
+		}
+		if err := general.CurrentSettings.Validate(); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
 
 		if listFlag {
-			cli.ListVolumes()
+			cli.ListVolumes(contextsFlag)
 		}
 
 		if saveFlag {
-			cli.SaveVolumes(args)
+			cli.SaveVolumes(args, matchOption, setOption)
 		}
 
 		if loadFlag {
-			cli.LoadVolumes(args)
+			restoreOption := cli.RestoreOption{Mode: restoreFlag, Backup: backupFlag, Force: forceFlag}
+			cli.LoadVolumes(args, restoreOption)
+		}
+
+		if pruneFlag {
+			olderThan, err := general.ParseDuration(olderThanFlag)
+			if olderThanFlag != "" && err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				return
+			}
+			pruneOption := cli.PruneOption{Unused: unusedFlag, OlderThan: olderThan, DryRun: dryRunFlag, Backup: backupFlag, Force: forceFlag}
+			cli.PruneVolumes(args, matchOption, pruneOption)
+		}
+
+		if cloneFlag {
+			cli.CloneVolume(args, false)
+		}
+
+		if renameFlag {
+			cli.CloneVolume(args, true)
+		}
+
+		if exportFlag {
+			cli.ExportVolumes(args, matchOption, toDirFlag, general.PathFilter(includePathFlag, excludePathFlag))
+		}
+
+		if importFlag {
+			cli.ImportVolume(args, fromDirFlag, general.PathFilter(includePathFlag, excludePathFlag))
+		}
+
+		if listArchiveFlag {
+			cli.ListVolumeArchives(args)
+		}
+
+		if extractFlag {
+			cli.ExtractVolumeArchive(args, pathFlag, toDirFlag, toVolumeFlag)
 		}
 	},
 }
 
 func init() {
 	volumeCmd.Flags().Bool("list", false, "List all volumes")
-	volumeCmd.Flags().Bool("save", false, "Save one or more volumes with timestamp to a tar archive, for example: '--save volume1 volume2' or '--save all'")
+	volumeCmd.Flags().StringSlice("contexts", []string{}, "List volumes of several docker contexts in one table with a Host column, for example: '--list --contexts default,server1'")
+	volumeCmd.Flags().Bool("save", false, "Save one or more volumes to tar archives, use '--set' to save them to a timestamped backup set, for example: '--save volume1 volume2', '--save \"db-*\"' or '--save all'")
 	volumeCmd.Flags().Bool("load", false, "Load a volume from a tar archive, for example: '--load volume1_archive volume2_archive'")
+	volumeCmd.Flags().Bool("prune", false, "Remove volumes by retention rules, always previews the volumes to remove first, for example: '--prune --unused'")
+	volumeCmd.Flags().Bool("regex", false, "Treat volume names as regular expressions")
+	volumeCmd.Flags().StringSlice("exclude", []string{}, "Exclude volumes matching the pattern, can be specified multiple times, for example: '--exclude \"*-cache\"'")
+	volumeCmd.Flags().Bool("unused", false, "Remove volumes not used by any container when pruning")
+	volumeCmd.Flags().String("older-than", "", "Only remove volumes created earlier than the duration when pruning, for example: '72h', '30d' or '2w'")
+	volumeCmd.Flags().Bool("dry-run", false, "Only preview the volumes to remove when pruning")
+	volumeCmd.Flags().Bool("backup", false, "Save volumes to tar archives before removing them when pruning, or before restoring into them when loading")
+	volumeCmd.Flags().Bool("force", false, "Do not ask for confirmation before removing or wiping volumes")
+	volumeCmd.Flags().String("restore", "", "How to load an archive into an existing volume: 'wipe' (remove all content first), 'overwrite' (replace matching files) or 'missing' (only add missing files)")
+	volumeCmd.Flags().Bool("clone", false, "Copy a volume to a new volume with the same driver, labels and options, for example: '--clone volume1 volume2'")
+	volumeCmd.Flags().Bool("rename", false, "Rename a volume not used by any container, the source is removed after the copy is verified, for example: '--rename volume1 volume2'")
+	volumeCmd.Flags().Bool("export", false, "Export the content of volumes to plain folders under '--to-dir', for example: '--export volume1 --to-dir ./dump'")
+	volumeCmd.Flags().Bool("import", false, "Import the content of a local folder into a volume, for example: '--import volume1 --from-dir ./seed'")
+	volumeCmd.Flags().String("from-dir", "", "Import from the local folder")
+	volumeCmd.Flags().StringSlice("include-path", []string{}, "Only export or import paths matching the pattern, can be specified multiple times, for example: '--include-path \"etc/*\"'")
+	volumeCmd.Flags().StringSlice("exclude-path", []string{}, "Do not export or import paths matching the pattern, can be specified multiple times, for example: '--exclude-path \"*.log\"'")
+	volumeCmd.Flags().Bool("list-archive", false, "List the file tree of volume archives, for example: '--list-archive volume1_volume.tar.gz'")
+	volumeCmd.Flags().Bool("extract", false, "Extract files from a volume archive, for example: '--extract volume1_volume.tar.gz --path etc/app.conf --to-dir ./restore'")
+	volumeCmd.Flags().StringSlice("path", []string{}, "Path in the archive to extract, including everything under it, can be specified multiple times")
+	volumeCmd.Flags().String("to-dir", "", "Extract or export to the local folder")
+	volumeCmd.Flags().String("to-volume", "", "Extract into the existing volume")
+
+	volumeCmd.Flags().Bool("set", false, "Save into a new timestamped backup set folder 'volume-<YYYYMMDDhhmmss>' under the output folder")
+	volumeCmd.Flags().Int("keep-last", 0, "After a successful '--set' run, keep the N newest volume backup sets and remove the others not kept by other '--keep-*' rules")
+	volumeCmd.Flags().Int("keep-daily", 0, "After a successful '--set' run, keep the newest volume backup set of each of the last N days")
+	volumeCmd.Flags().Int("keep-weekly", 0, "After a successful '--set' run, keep the newest volume backup set of each of the last N weeks")
+	volumeCmd.Flags().Int("keep-monthly", 0, "After a successful '--set' run, keep the newest volume backup set of each of the last N months")
+	volumeCmd.Flags().String("output-dir", "", "Folder or storage URL to save archives to, defaults to the 'output_dir' setting or the current folder")
+	volumeCmd.Flags().String("name-template", "", "Go template for archive names, '/' creates subfolders, fields: Name (required), Identity, Date, Time, for example: '{{.Name}}/{{.Date}}-{{.Time}}'")
+	volumeCmd.Flags().String("split-size", "", "Split archives into numbered parts of at most this size with an index file, for example: '4GB' for FAT32 or '4.7GB' for DVDs")
 
 	volumeCmd.Flags().BoolP("help", "h", false, "help for volume command")
 	rootCmd.AddCommand(volumeCmd)
@@ -0,0 +1,488 @@
+/*
+File: define_archive.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 12:08:44
+
+Description: 处理 tar 存档
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DockerArchiveManifest docker save 存档中 manifest.json 的条目
+type DockerArchiveManifest struct {
+	Config   string   `json:"Config"`   // image 配置文件在存档中的路径
+	RepoTags []string `json:"RepoTags"` // image 的 Repository:Tag
+	Layers   []string `json:"Layers"`   // image 各层在存档中的路径，从底层到顶层
+}
+
+// safeJoin 将存档内的路径拼接到目标文件夹，拒绝逃逸出目标文件夹的路径
+//
+// 参数：
+//   - folderPath: 目标文件夹路径
+//   - name: 存档内的路径
+//
+// 返回：
+//   - 拼接后的路径
+//   - 错误信息
+func safeJoin(folderPath, name string) (string, error) {
+	target := filepath.Join(folderPath, filepath.FromSlash(name))
+	if target != filepath.Clean(folderPath) && !strings.HasPrefix(target, filepath.Clean(folderPath)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+	return target, nil
+}
+
+// checkResolvedParent 检查路径的上级文件夹解析符号链接后仍位于目标文件夹中
+//
+//   - 存档中的符号链接可以指向目标文件夹之外，不能通过它们写入后续条目
+//   - 尚不存在的上级文件夹之后会作为普通文件夹创建，只需解析已存在的部分
+//
+// 参数：
+//   - root: 目标文件夹解析符号链接后的路径
+//   - target: 待写入的路径
+//
+// 返回：
+//   - 错误信息
+func checkResolvedParent(root, target string) error {
+	existing := filepath.Dir(target)
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		existing = filepath.Dir(existing)
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+	if resolved != root && !strings.HasPrefix(resolved, root+string(os.PathSeparator)) {
+		return fmt.Errorf("illegal path in archive, escapes through a symbolic link: %s", target)
+	}
+	return nil
+}
+
+// removeSymlink 删除路径上已有的符号链接，避免写入文件时跟随它写到别处
+func removeSymlink(target string) error {
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return os.Remove(target)
+	}
+	return nil
+}
+
+// ExtractTar 将 tar 流解包到指定文件夹
+//
+//   - 仅处理普通文件、文件夹和符号链接
+//
+// 参数：
+//   - reader: tar 流
+//   - folderPath: 目标文件夹路径
+//
+// 返回：
+//   - 错误信息
+func ExtractTar(reader io.Reader, folderPath string) error {
+	return ExtractTarFiltered(reader, folderPath, nil)
+}
+
+// ExtractTarFiltered 将 tar 流中符合条件的条目解包到指定文件夹
+//
+//   - 仅处理普通文件、文件夹、符号链接和硬链接，保留权限和修改时间
+//   - 拒绝通过已解包的符号链接写到目标文件夹之外的条目
+//
+// 参数：
+//   - reader: tar 流
+//   - folderPath: 目标文件夹路径
+//   - keep: 判断条目是否需要解包，参数为规范化后的路径，为 nil 时解包全部
+//
+// 返回：
+//   - 错误信息
+func ExtractTarFiltered(reader io.Reader, folderPath string, keep func(name string) bool) error {
+	if err := CreateFolder(folderPath); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(folderPath)
+	if err != nil {
+		return err
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if keep != nil && !keep(CleanArchivePath(header.Name)) {
+			continue
+		}
+
+		target, err := safeJoin(folderPath, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkResolvedParent(root, target); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := CreateFolder(filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := removeSymlink(target); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, header.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tarReader); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+			if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := CreateFolder(filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := DeleteFile(target); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			linkTarget, err := safeJoin(folderPath, header.Linkname)
+			if err != nil {
+				return err
+			}
+			if err := checkResolvedParent(root, linkTarget); err != nil {
+				return err
+			}
+			if err := CreateFolder(filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := DeleteFile(target); err != nil {
+				return err
+			}
+			if err := os.Link(linkTarget, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// FilterTar 将 tar 流中符合条件的条目写入新的 tar 流
+//
+// 参数：
+//   - reader: 源 tar 流
+//   - writer: 目标 tar 流
+//   - keep: 判断条目是否保留，参数为规范化后的路径
+//
+// 返回：
+//   - 错误信息
+func FilterTar(reader io.Reader, writer io.Writer, keep func(name string) bool) error {
+	tarReader := tar.NewReader(reader)
+	tarWriter := tar.NewWriter(writer)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !keep(CleanArchivePath(header.Name)) {
+			continue
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return err
+		}
+	}
+	return tarWriter.Close()
+}
+
+// RebaseTar 将 tar 流中位于指定文件夹下的条目改写为相对于该文件夹的路径
+//
+//   - 用于处理 docker cp 输出的 tar 流，其条目以被复制的文件夹名开头
+//   - 不在该文件夹下的条目被丢弃
+//
+// 参数：
+//   - reader: 源 tar 流
+//   - writer: 目标 tar 流
+//   - base: 文件夹在源 tar 流中的路径
+//
+// 返回：
+//   - 错误信息
+func RebaseTar(reader io.Reader, writer io.Writer, base string) error {
+	base = CleanArchivePath(base)
+	rebase := func(name string) (string, bool) {
+		name = CleanArchivePath(name)
+		if name == base {
+			return "./", true
+		}
+		if rest, found := strings.CutPrefix(name, base+"/"); found {
+			return "./" + rest, true
+		}
+		return "", false
+	}
+
+	tarReader := tar.NewReader(reader)
+	tarWriter := tar.NewWriter(writer)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name, ok := rebase(header.Name)
+		if !ok {
+			continue
+		}
+		if header.Typeflag == tar.TypeDir && !strings.HasSuffix(name, "/") {
+			name += "/"
+		}
+		header.Name = name
+		if header.Typeflag == tar.TypeLink {
+			if header.Linkname, ok = rebase(header.Linkname); !ok {
+				continue
+			}
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return err
+		}
+	}
+	return tarWriter.Close()
+}
+
+// TarDirectory 将文件夹中符合条件的内容打包为 tar 流
+//
+//   - 路径相对于该文件夹，不包含文件夹本身，保留权限、所有者和修改时间，不跟随符号链接
+//
+// 参数：
+//   - folderPath: 文件夹路径
+//   - writer: 目标 tar 流
+//   - keep: 判断条目是否打包，参数为规范化后的路径
+//
+// 返回：
+//   - 错误信息
+func TarDirectory(folderPath string, writer io.Writer, keep func(name string) bool) error {
+	tarWriter := tar.NewWriter(writer)
+	err := filepath.WalkDir(folderPath, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(folderPath, filePath)
+		if err != nil {
+			return err
+		}
+		name := CleanArchivePath(filepath.ToSlash(relPath))
+		if name == "" || !keep(name) {
+			// 未选中的文件夹仍需深入，其下的内容可能被 include 选中
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		linkname := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if linkname, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, linkname)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		header.Uname, header.Gname = "", ""
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			file, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			if _, err := io.Copy(tarWriter, file); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tarWriter.Close()
+}
+
+// PathSelector 返回判断存档路径是否位于指定路径之一（或其下）的函数
+//
+// 参数：
+//   - paths: 指定路径，为空时选中全部
+//
+// 返回：
+//   - 判断函数，参数为规范化后的路径
+func PathSelector(paths []string) func(name string) bool {
+	var cleanedPaths []string
+	for _, selected := range paths {
+		cleanedPaths = append(cleanedPaths, CleanArchivePath(selected))
+	}
+	return func(name string) bool {
+		if len(cleanedPaths) == 0 {
+			return true
+		}
+		for _, selected := range cleanedPaths {
+			if selected == "" || name == selected || strings.HasPrefix(name, selected+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ArchiveEntry 存档中的条目
+type ArchiveEntry struct {
+	Path     string      // 规范化后的路径
+	Type     byte        // 条目类型，与 tar.Header.Typeflag 一致
+	Size     int64       // 大小
+	Mode     os.FileMode // 权限
+	ModTime  time.Time   // 修改时间
+	Linkname string      // 链接目标
+}
+
+// ListArchive 列出存档中的所有条目
+//
+// 参数：
+//   - archiveFile: 存档文件，允许压缩
+//
+// 返回：
+//   - 条目切片，按路径排序
+//   - 错误信息
+func ListArchive(archiveFile string) ([]ArchiveEntry, error) {
+	reader, err := OpenArchive(archiveFile)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var entries []ArchiveEntry
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := CleanArchivePath(header.Name)
+		if name == "" {
+			continue
+		}
+		entries = append(entries, ArchiveEntry{
+			Path:     name,
+			Type:     header.Typeflag,
+			Size:     header.Size,
+			Mode:     header.FileInfo().Mode(),
+			ModTime:  header.ModTime,
+			Linkname: header.Linkname,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// TarEntryNames 列出 tar 存档中的所有条目名
+//
+// 参数：
+//   - archiveFile: tar 存档文件
+//
+// 返回：
+//   - 条目名切片
+//   - 错误信息
+func TarEntryNames(archiveFile string) ([]string, error) {
+	file, err := os.Open(archiveFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var names []string
+	tarReader := tar.NewReader(file)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, strings.TrimPrefix(header.Name, "./"))
+	}
+}
+
+// writeTarFile 向 tar 流写入一个普通文件
+//
+// 参数：
+//   - tarWriter: tar 写入器
+//   - name: 文件在存档中的路径
+//   - size: 文件大小
+//   - reader: 文件内容
+//
+// 返回：
+//   - 错误信息
+func writeTarFile(tarWriter *tar.Writer, name string, size int64, reader io.Reader) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0644,
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.Copy(tarWriter, reader)
+	return err
+}
@@ -0,0 +1,124 @@
+/*
+File: define_archive_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 10:52:17
+
+Description: tar 存档解包测试
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarEntry 测试用的 tar 条目
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	content  string
+}
+
+// buildTar 按顺序写入条目，返回 tar 流
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Reader {
+	t.Helper()
+	var buffer bytes.Buffer
+	tarWriter := tar.NewWriter(&buffer)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0644, Size: int64(len(entry.content))}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buffer.Bytes())
+}
+
+func TestExtractTarRejectsSymlinkEscape(t *testing.T) {
+	outside := t.TempDir()
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{"write through symlink to folder", []tarEntry{
+			{name: "a", typeflag: tar.TypeSymlink, linkname: outside},
+			{name: "a/passwd", typeflag: tar.TypeReg, content: "owned"},
+		}},
+		{"create folder through symlink", []tarEntry{
+			{name: "a", typeflag: tar.TypeSymlink, linkname: outside},
+			{name: "a/sub/passwd", typeflag: tar.TypeReg, content: "owned"},
+		}},
+		{"relative symlink to parent", []tarEntry{
+			{name: "a", typeflag: tar.TypeSymlink, linkname: "../" + filepath.Base(outside)},
+			{name: "a/passwd", typeflag: tar.TypeReg, content: "owned"},
+		}},
+		{"hard link through symlink", []tarEntry{
+			{name: "a", typeflag: tar.TypeSymlink, linkname: outside},
+			{name: "link", typeflag: tar.TypeLink, linkname: "a/secret"},
+		}},
+		{"lexical escape", []tarEntry{
+			{name: "../passwd", typeflag: tar.TypeReg, content: "owned"},
+		}},
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// 目标文件夹与 outside 同级，使相对符号链接能指向 outside
+			folderPath, err := os.MkdirTemp(filepath.Dir(outside), "extract-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(folderPath)
+
+			if err := ExtractTar(buildTar(t, test.entries), folderPath); err == nil {
+				t.Fatal("ExtractTar should reject the archive")
+			}
+			if FileExist(filepath.Join(outside, "passwd")) || FileExist(filepath.Join(outside, "sub")) {
+				t.Fatal("ExtractTar wrote outside the target folder")
+			}
+		})
+	}
+}
+
+func TestExtractTarKeepsInsideSymlinks(t *testing.T) {
+	folderPath := t.TempDir()
+	entries := []tarEntry{
+		{name: "data/", typeflag: tar.TypeDir},
+		{name: "current", typeflag: tar.TypeSymlink, linkname: "data"},
+		{name: "current/file", typeflag: tar.TypeReg, content: "inside"},
+		{name: "localtime", typeflag: tar.TypeSymlink, linkname: "/etc/localtime"},
+		{name: "replaced", typeflag: tar.TypeSymlink, linkname: "/etc/hostname"},
+		{name: "replaced", typeflag: tar.TypeReg, content: "regular"},
+	}
+	if err := ExtractTar(buildTar(t, entries), folderPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if content, err := os.ReadFile(filepath.Join(folderPath, "data", "file")); err != nil || string(content) != "inside" {
+		t.Errorf("data/file = %q, %v, want %q", content, err, "inside")
+	}
+	if linkname, err := os.Readlink(filepath.Join(folderPath, "localtime")); err != nil || linkname != "/etc/localtime" {
+		t.Errorf("localtime -> %q, %v, want /etc/localtime", linkname, err)
+	}
+	info, err := os.Lstat(filepath.Join(folderPath, "replaced"))
+	if err != nil || !info.Mode().IsRegular() {
+		t.Errorf("replaced should be a regular file instead of following the earlier symlink")
+	}
+}
@@ -0,0 +1,372 @@
+/*
+File: define_catalog.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 22:06:14
+
+Description: 记录每次保存和加载的存档目录
+
+Notice:
+	- 目录保存在 $XDG_STATE_HOME/wocker/catalog.db 中，是一个 bbolt 数据库
+	- 每次写入时短暂打开数据库，多个 wocker 进程可以同时使用
+*/
+
+package general
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	catalogFileName = "catalog.db" // 目录数据库文件名
+	catalogTimeout  = 5 * time.Second
+)
+
+// catalogBucket 保存记录的 bucket，键为递增的记录 ID
+var catalogBucket = []byte("records")
+
+// 目录记录的操作
+const (
+	CatalogSave = "save" // 保存
+	CatalogLoad = "load" // 加载
+)
+
+// 目录记录的结果
+const (
+	CatalogSucceeded = "succeeded" // 成功
+	CatalogFailed    = "failed"    // 失败
+)
+
+// CatalogRecord 一次保存或加载的记录
+type CatalogRecord struct {
+	ID        uint64        `json:"id"`                // 记录 ID，写入时分配
+	Time      time.Time     `json:"time"`              // 开始时间
+	Operation string        `json:"operation"`         // 操作，'save' 或 'load'
+	Kind      string        `json:"kind"`              // 类型，'image' 或 'volume'
+	Item      string        `json:"item"`              // image 引用或 volume 名
+	Digest    string        `json:"digest,omitempty"`  // image ID 或 volume 存档的 sha256
+	Archive   string        `json:"archive"`           // 存档的绝对路径
+	Size      int64         `json:"size"`              // 存档大小
+	Duration  time.Duration `json:"duration"`          // 耗时
+	Host      string        `json:"host"`              // docker service 地址
+	Machine   string        `json:"machine,omitempty"` // 运行 wocker 的主机名
+	Result    string        `json:"result"`            // 结果
+	Message   string        `json:"message,omitempty"` // 失败原因
+}
+
+// CatalogFilter 查询目录的条件，空值表示不限制
+type CatalogFilter struct {
+	Items     []string // image 引用或 volume 名的模式
+	Regex     bool     // 是否将 Items 视为正则表达式，否则视为 shell 通配符
+	Kind      string   // 类型
+	Operation string   // 操作
+	Result    string   // 结果
+}
+
+// NewCatalogRecord 创建一条记录，开始时间为当前时间
+//
+// 参数：
+//   - operation: 操作
+//   - kind: 类型
+//   - item: image 引用或 volume 名
+//   - archive: 存档路径或远程存档的 URL
+//
+// 返回：
+//   - 记录
+func NewCatalogRecord(operation string, kind string, item string, archive string) CatalogRecord {
+	// 远程存档记录完整的 URL
+	if absArchive, err := filepath.Abs(archive); err == nil && !IsRemoteLocation(archive) {
+		archive = absArchive
+	}
+	return CatalogRecord{
+		Time:      time.Now(),
+		Operation: operation,
+		Kind:      kind,
+		Item:      item,
+		Archive:   archive,
+		Host:      DockerHost(),
+		Machine:   machineName(),
+	}
+}
+
+// Finish 记录结果、耗时和存档大小
+//
+// 参数：
+//   - err: 操作返回的错误，为 nil 时表示成功
+func (record *CatalogRecord) Finish(err error) {
+	record.Duration = time.Since(record.Time)
+	// 调用方未设置大小时读取本地存档的大小
+	if record.Size == 0 && !IsRemoteLocation(record.Archive) {
+		record.Size, _ = PathSize(record.Archive)
+	}
+	record.Result = CatalogSucceeded
+	if err != nil {
+		record.Result, record.Message = CatalogFailed, err.Error()
+	}
+}
+
+// Origin 返回存档所属 docker service 的标识
+//
+//   - 本地 socket 地址在每台主机上都相同，加上主机名区分共享同一目录的主机
+//
+// 返回：
+//   - 标识
+func (record CatalogRecord) Origin() string {
+	if record.Machine != "" && isLocalSocket(record.Host) {
+		return record.Machine + ":" + record.Host
+	}
+	return record.Host
+}
+
+// CatalogFile 返回目录数据库的路径
+func CatalogFile() string {
+	return filepath.Join(StateDir(), catalogFileName)
+}
+
+// openCatalog 打开目录数据库，其他进程正在写入时最多等待 catalogTimeout
+//
+// 参数：
+//   - readOnly: 是否只读打开，数据库不存在时返回 nil
+//
+// 返回：
+//   - 数据库
+//   - 错误信息
+func openCatalog(readOnly bool) (*bolt.DB, error) {
+	catalogFile := CatalogFile()
+	if readOnly && !FileExist(catalogFile) {
+		return nil, nil
+	}
+	if err := CreateFolder(filepath.Dir(catalogFile)); err != nil {
+		return nil, err
+	}
+	return bolt.Open(catalogFile, 0600, &bolt.Options{Timeout: catalogTimeout, ReadOnly: readOnly})
+}
+
+// AddCatalogRecords 将记录写入目录
+//
+// 参数：
+//   - records: 记录，ID 由目录分配
+//
+// 返回：
+//   - 错误信息
+func AddCatalogRecords(records []CatalogRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	db, err := openCatalog(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(catalogBucket)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if record.ID, err = bucket.NextSequence(); err != nil {
+				return err
+			}
+			value, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(catalogKey(record.ID), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// QueryCatalog 按条件查询目录
+//
+// 参数：
+//   - filter: 查询条件
+//
+// 返回：
+//   - 满足条件的记录，按时间从新到旧排序
+//   - 错误信息
+func QueryCatalog(filter CatalogFilter) ([]CatalogRecord, error) {
+	db, err := openCatalog(true)
+	if err != nil || db == nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var records []CatalogRecord
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(catalogBucket)
+		if bucket == nil {
+			return nil
+		}
+		// 记录 ID 递增，倒序遍历即从新到旧
+		cursor := bucket.Cursor()
+		for key, value := cursor.Last(); key != nil; key, value = cursor.Prev() {
+			var record CatalogRecord
+			if err := json.Unmarshal(value, &record); err != nil {
+				return fmt.Errorf("catalog record %d: %w", binary.BigEndian.Uint64(key), err)
+			}
+			matched, err := filter.match(record)
+			if err != nil {
+				return err
+			}
+			if matched {
+				records = append(records, record)
+			}
+		}
+		return nil
+	})
+	return records, err
+}
+
+// match 判断记录是否满足查询条件
+func (filter CatalogFilter) match(record CatalogRecord) (bool, error) {
+	if (filter.Kind != "" && record.Kind != filter.Kind) ||
+		(filter.Operation != "" && record.Operation != filter.Operation) ||
+		(filter.Result != "" && record.Result != filter.Result) {
+		return false, nil
+	}
+	if len(filter.Items) == 0 {
+		return true, nil
+	}
+	return MatchAny(filter.Items, record.Item, filter.Regex)
+}
+
+// MissingArchives 找出存档已不在磁盘或远程存储上的成功保存记录
+//
+//   - 同一存档路径只检查最新的一条记录
+//   - 无法连接远程存储时，其中的存档视为存在
+//
+// 参数：
+//   - records: 记录，按时间从新到旧排序
+//
+// 返回：
+//   - 存档已不存在的记录
+func MissingArchives(records []CatalogRecord) []CatalogRecord {
+	var (
+		missing []CatalogRecord
+		checked = make(map[string]bool)
+		checker = NewArchiveChecker()
+	)
+	defer checker.Close()
+	for _, record := range records {
+		if record.Operation != CatalogSave || record.Result != CatalogSucceeded || checked[record.Archive] {
+			continue
+		}
+		checked[record.Archive] = true
+		if !checker.Exists(record.Archive) {
+			missing = append(missing, record)
+		}
+	}
+	return missing
+}
+
+// RemoveCatalogRecords 从目录中删除记录
+//
+// 参数：
+//   - ids: 记录 ID
+//
+// 返回：
+//   - 错误信息
+func RemoveCatalogRecords(ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db, err := openCatalog(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(catalogBucket)
+		if bucket == nil {
+			return nil
+		}
+		for _, id := range ids {
+			if err := bucket.Delete(catalogKey(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// catalogKey 将记录 ID 转换为按数值排序的键
+func catalogKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+// FileDigest 计算文件的 sha256
+//
+// 参数：
+//   - filePath: 文件路径
+//
+// 返回：
+//   - 'sha256:' 加上十六进制摘要
+//   - 错误信息
+func FileDigest(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+// PathSize 返回文件大小，文件夹返回其中所有文件的大小之和
+//
+// 参数：
+//   - path: 文件或文件夹路径
+//
+// 返回：
+//   - 大小
+//   - 错误信息
+func PathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// machineName 返回运行 wocker 的主机名
+func machineName() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+// DockerHost 返回当前 docker service 的地址
+func DockerHost() string {
+	return docker.DaemonHost()
+}
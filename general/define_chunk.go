@@ -0,0 +1,419 @@
+/*
+File: define_chunk.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 01:12:36
+
+Description: 分块上传和下载存档
+
+Notice:
+	- 分块保存的存档是一个文件夹，包含分块清单 'chunks.json' 和分块文件 'chunk-000001'、'chunk-000002' ...
+	- 每上传一个分块就更新一次清单，清单中保留上次上传中尚未处理到的分块，多次中断也不会丢失已上传的分块
+	- 再次保存到同一位置时，内容与清单记录相同、存储中大小一致且 ETag 未改变的分块不再重复上传，不重新下载
+	- 读取时逐个下载并校验分块摘要，下载失败的分块单独重试
+*/
+
+package general
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+const (
+	chunkManifestFile = "chunks.json" // 分块清单文件名
+	chunkRetries      = 3             // 上传或下载单个分块失败时的重试次数
+)
+
+// ChunkInfo 一个分块
+type ChunkInfo struct {
+	Index  int    `json:"index"`          // 序号，从 1 开始
+	Size   int64  `json:"size"`           // 大小
+	Digest string `json:"digest"`         // sha256 摘要，格式为 'sha256:<hex>'
+	ETag   string `json:"etag,omitempty"` // 上传后存储返回的 ETag，存储不提供时为空
+}
+
+// ChunkManifest 分块清单
+type ChunkManifest struct {
+	ChunkSize int64       `json:"chunk_size"` // 分块大小，最后一个分块可以更小
+	Size      int64       `json:"size"`       // 已上传的总大小
+	Digest    string      `json:"digest"`     // 存档的 sha256 摘要，上传完成后设置
+	Complete  bool        `json:"complete"`   // 是否上传完成
+	Chunks    []ChunkInfo `json:"chunks"`     // 已上传的分块
+}
+
+// chunkName 返回分块在存储中的路径
+func chunkName(name string, index int) string {
+	return path.Join(name, fmt.Sprintf("chunk-%06d", index))
+}
+
+// withRetry 执行操作，失败时等待后重试
+//
+// 参数：
+//   - operation: 操作
+//
+// 返回：
+//   - 最后一次执行的错误信息
+func withRetry(operation func() error) error {
+	err := operation()
+	for attempt := 1; attempt <= chunkRetries && err != nil; attempt++ {
+		time.Sleep(time.Duration(attempt) * time.Second)
+		err = operation()
+	}
+	return err
+}
+
+// ReadChunkManifest 读取分块保存的存档的清单
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//
+// 返回：
+//   - 分块清单
+//   - 错误信息
+func ReadChunkManifest(storage Storage, name string) (ChunkManifest, error) {
+	var manifest ChunkManifest
+	reader, err := storage.Get(path.Join(name, chunkManifestFile))
+	if err != nil {
+		return manifest, err
+	}
+	defer reader.Close()
+
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%s: %w", storage.Location(path.Join(name, chunkManifestFile)), err)
+	}
+	return manifest, nil
+}
+
+// writeChunkManifest 写入分块清单
+func writeChunkManifest(storage Storage, name string, manifest ChunkManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return withRetry(func() error {
+		return storage.Put(path.Join(name, chunkManifestFile), bytes.NewReader(content))
+	})
+}
+
+// uploadedChunk 返回清单中记录的已上传分块
+//
+//   - 只比较存储中分块的大小和 ETag，不重新下载，摘要在读取存档时校验
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//   - previous: 上次上传留下的清单
+//   - chunk: 本次读取到的分块，不含 ETag
+//
+// 返回：
+//   - 清单中记录的分块
+//   - 分块是否已上传
+func uploadedChunk(storage Storage, name string, previous ChunkManifest, chunk ChunkInfo) (ChunkInfo, bool) {
+	if chunk.Index > len(previous.Chunks) {
+		return chunk, false
+	}
+	recorded := previous.Chunks[chunk.Index-1]
+	if recorded.Index != chunk.Index || recorded.Size != chunk.Size || recorded.Digest != chunk.Digest {
+		return chunk, false
+	}
+	entry, err := storage.Stat(chunkName(name, chunk.Index))
+	if err != nil || entry.IsDir || entry.Size != recorded.Size || entry.ETag != recorded.ETag {
+		return chunk, false
+	}
+	return recorded, true
+}
+
+// putChunk 上传分块并记录存储返回的 ETag
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//   - chunk: 分块
+//   - data: 分块内容
+//
+// 返回：
+//   - 记录了 ETag 的分块
+//   - 错误信息
+func putChunk(storage Storage, name string, chunk ChunkInfo, data []byte) (ChunkInfo, error) {
+	if err := withRetry(func() error { return storage.Put(chunkName(name, chunk.Index), bytes.NewReader(data)) }); err != nil {
+		return chunk, err
+	}
+	// 获取不到 ETag 时续传只比较大小
+	if entry, err := storage.Stat(chunkName(name, chunk.Index)); err == nil {
+		chunk.ETag = entry.ETag
+	}
+	return chunk, nil
+}
+
+// mergeChunkManifest 返回上传过程中写入的清单
+//
+//   - 本次已处理的分块之后，保留上次清单中记录的分块，这些分块文件尚未被覆盖
+//
+// 参数：
+//   - manifest: 本次已处理的分块
+//   - previous: 上次上传留下的清单
+//
+// 返回：
+//   - 合并后的清单
+func mergeChunkManifest(manifest ChunkManifest, previous ChunkManifest) ChunkManifest {
+	merged := ChunkManifest{ChunkSize: manifest.ChunkSize}
+	merged.Chunks = append(merged.Chunks, manifest.Chunks...)
+	if len(previous.Chunks) > len(manifest.Chunks) {
+		merged.Chunks = append(merged.Chunks, previous.Chunks[len(manifest.Chunks):]...)
+	}
+	for _, chunk := range merged.Chunks {
+		merged.Size += chunk.Size
+	}
+	return merged
+}
+
+// PutChunked 将 content 分块写入存储
+//
+//   - 同一位置已有分块清单且分块大小相同时，跳过内容相同且已上传的分块，这些分块的摘要由 OpenChunked 在读取时校验
+//   - 每个分块上传失败时单独重试，读取 content 出错时不上传不完整的分块
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径，保存为文件夹
+//   - content: 存档内容
+//   - chunkSize: 分块大小
+//
+// 返回：
+//   - 分块清单
+//   - 错误信息
+func PutChunked(storage Storage, name string, content io.Reader, chunkSize int64) (ChunkManifest, error) {
+	manifest := ChunkManifest{ChunkSize: chunkSize}
+
+	// 读取上次中断的上传留下的清单，同一位置已有普通文件时先删除
+	previous, err := ReadChunkManifest(storage, name)
+	if err != nil {
+		previous = ChunkManifest{}
+		if entry, err := storage.Stat(name); err == nil && !entry.IsDir {
+			if err := storage.Remove(name); err != nil {
+				return manifest, err
+			}
+		}
+	}
+	previousCount := len(previous.Chunks)
+	if previous.ChunkSize != chunkSize {
+		previous = ChunkManifest{}
+	}
+	if err := storage.MakeFolder(name); err != nil {
+		return manifest, err
+	}
+
+	var (
+		archiveHash = sha256.New()
+		buffer      = make([]byte, chunkSize)
+		skipped     int
+	)
+	for index := 1; ; index++ {
+		count, readErr := io.ReadFull(content, buffer)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return manifest, readErr
+		}
+		if count > 0 {
+			data := buffer[:count]
+			archiveHash.Write(data)
+			chunk := ChunkInfo{Index: index, Size: int64(count), Digest: fmt.Sprintf("sha256:%x", sha256.Sum256(data))}
+			if recorded, ok := uploadedChunk(storage, name, previous, chunk); ok {
+				chunk = recorded
+				skipped++
+			} else if chunk, err = putChunk(storage, name, chunk, data); err != nil {
+				return manifest, err
+			}
+			manifest.Chunks = append(manifest.Chunks, chunk)
+			manifest.Size += chunk.Size
+			if err := writeChunkManifest(storage, name, mergeChunkManifest(manifest, previous)); err != nil {
+				return manifest, err
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if skipped > 0 {
+		color.Printf("%s Resume %s -> %s\n", PackFlag, FgBlueText(storage.Location(name)), SecondaryText(color.Sprintf(ChunksReusedMessage, skipped, len(manifest.Chunks))))
+	}
+
+	manifest.Digest, manifest.Complete = fmt.Sprintf("sha256:%x", archiveHash.Sum(nil)), true
+	if err := writeChunkManifest(storage, name, manifest); err != nil {
+		return manifest, err
+	}
+	// 删除上次上传留下的多余分块
+	for index := len(manifest.Chunks) + 1; index <= previousCount; index++ {
+		storage.Remove(chunkName(name, index))
+	}
+	return manifest, nil
+}
+
+// OpenChunked 读取分块保存的存档，逐个下载并校验分块
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//
+// 返回：
+//   - 存档内容
+//   - 错误信息
+func OpenChunked(storage Storage, name string) (io.ReadCloser, error) {
+	manifest, err := ReadChunkManifest(storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if !manifest.Complete {
+		return nil, fmt.Errorf("%s: %s", storage.Location(name), IncompleteChunksMessage)
+	}
+	return &chunkReader{storage: storage, name: name, manifest: manifest, archiveHash: sha256.New()}, nil
+}
+
+// chunkReader 按清单依次读取分块
+type chunkReader struct {
+	storage     Storage
+	name        string
+	manifest    ChunkManifest
+	next        int       // 下一个读取的分块在清单中的位置
+	buffer      []byte    // 当前分块中尚未返回的数据
+	archiveHash hash.Hash // 已读取数据的摘要
+}
+
+func (reader *chunkReader) Read(data []byte) (int, error) {
+	for len(reader.buffer) == 0 {
+		if reader.next == len(reader.manifest.Chunks) {
+			if digest := fmt.Sprintf("sha256:%x", reader.archiveHash.Sum(nil)); digest != reader.manifest.Digest {
+				return 0, fmt.Errorf("%s: digest %s does not match %s", reader.storage.Location(reader.name), digest, reader.manifest.Digest)
+			}
+			return 0, io.EOF
+		}
+		chunk := reader.manifest.Chunks[reader.next]
+		if err := withRetry(func() error { return reader.fetch(chunk) }); err != nil {
+			return 0, err
+		}
+		reader.next++
+	}
+	count := copy(data, reader.buffer)
+	reader.buffer = reader.buffer[count:]
+	return count, nil
+}
+
+// fetch 下载并校验分块
+func (reader *chunkReader) fetch(chunk ChunkInfo) error {
+	chunkFile, err := reader.storage.Get(chunkName(reader.name, chunk.Index))
+	if err != nil {
+		return err
+	}
+	defer chunkFile.Close()
+
+	content, err := io.ReadAll(io.LimitReader(chunkFile, chunk.Size+1))
+	if err != nil {
+		return err
+	}
+	if digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content)); int64(len(content)) != chunk.Size || digest != chunk.Digest {
+		return fmt.Errorf("%s: chunk %d is corrupted", reader.storage.Location(reader.name), chunk.Index)
+	}
+	reader.archiveHash.Write(content)
+	reader.buffer = content
+	return nil
+}
+
+func (reader *chunkReader) Close() error {
+	reader.buffer = nil
+	return nil
+}
+
+// IsChunkedArchive 判断本地路径是否是分块保存的存档
+//
+// 参数：
+//   - folderPath: 本地路径
+//
+// 返回：
+//   - 是否是分块保存的存档
+func IsChunkedArchive(folderPath string) bool {
+	info, err := os.Stat(folderPath)
+	return err == nil && info.IsDir() && FileExist(filepath.Join(folderPath, chunkManifestFile))
+}
+
+// openStorageArchive 读取存储中的存档，自动识别分块保存的存档和分卷存档
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//
+// 返回：
+//   - 存档内容
+//   - 错误信息
+func openStorageArchive(storage Storage, name string) (io.ReadCloser, error) {
+	if IsSplitArchive(name) {
+		return OpenSplit(storage, name)
+	}
+	entry, err := storage.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir {
+		return OpenChunked(storage, name)
+	}
+	return storage.Get(name)
+}
+
+// ArchiveSize 返回存储中存档的大小，分块保存的存档和分卷存档返回总大小
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//
+// 返回：
+//   - 大小
+//   - 错误信息
+func ArchiveSize(storage Storage, name string) (int64, error) {
+	if IsSplitArchive(name) {
+		index, err := readSplitIndex(storage, name)
+		return index.Size, err
+	}
+	entry, err := storage.Stat(name)
+	if err != nil {
+		return 0, err
+	}
+	if !entry.IsDir {
+		return entry.Size, nil
+	}
+	manifest, err := ReadChunkManifest(storage, name)
+	return manifest.Size, err
+}
+
+// putArchive 将存档写入存储
+//
+//   - 设置了 split_size 时拆分为分卷，否则远程存储在设置了 chunk_size 时分块写入
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//   - content: 存档内容
+//
+// 返回：
+//   - 错误信息
+func putArchive(storage Storage, name string, content io.Reader) error {
+	if partSize, err := splitSize(); err != nil {
+		return err
+	} else if partSize > 0 {
+		_, err := PutSplit(storage, name, content, partSize)
+		return err
+	}
+	if _, local := LocalPath(storage, name); local || CurrentSettings.ChunkSize == 0 {
+		return storage.Put(name, content)
+	}
+	_, err := PutChunked(storage, name, content, int64(CurrentSettings.ChunkSize)<<20)
+	return err
+}
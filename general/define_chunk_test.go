@@ -0,0 +1,173 @@
+/*
+File: define_chunk_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 15:06:42
+
+Description: 分块上传的断点续传测试
+*/
+
+package general
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// countingStorage 记录写入的分块
+type countingStorage struct {
+	Storage
+	puts []string
+}
+
+func (storage *countingStorage) Put(name string, content io.Reader) error {
+	if !strings.HasSuffix(name, chunkManifestFile) {
+		storage.puts = append(storage.puts, path.Base(name))
+	}
+	return storage.Storage.Put(name, content)
+}
+
+// etagStorage 以修改时间作为 ETag，模拟提供 ETag 的存储
+type etagStorage struct {
+	Storage
+}
+
+func (storage etagStorage) Stat(name string) (StorageEntry, error) {
+	entry, err := storage.Storage.Stat(name)
+	if err == nil && !entry.IsDir {
+		entry.ETag = fmt.Sprintf("%d", entry.ModTime.UnixNano())
+	}
+	return entry, err
+}
+
+// interruptedReader 读取 data 的前 size 字节后返回错误，模拟中断的保存
+func interruptedReader(data []byte, size int) io.Reader {
+	return io.MultiReader(bytes.NewReader(data[:size]), iotest.ErrReader(errors.New("interrupted")))
+}
+
+func TestPutChunkedResume(t *testing.T) {
+	const chunkSize = 16
+	folder := t.TempDir()
+	local, err := OpenStorage(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &countingStorage{Storage: local}
+	data := []byte(strings.Repeat("0123456789abcdef", 5)[:76])
+
+	// 第一次在 4 个分块后中断，第二次在 2 个分块后中断，清单仍然记录 4 个分块
+	if _, err := PutChunked(storage, "app.dockerimage", interruptedReader(data, 4*chunkSize), chunkSize); err == nil {
+		t.Fatal("first PutChunked should fail")
+	}
+	if _, err := PutChunked(storage, "app.dockerimage", interruptedReader(data, 2*chunkSize+5), chunkSize); err == nil {
+		t.Fatal("second PutChunked should fail")
+	}
+	manifest, err := ReadChunkManifest(storage, "app.dockerimage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Complete || len(manifest.Chunks) != 4 || manifest.Size != 4*chunkSize {
+		t.Fatalf("manifest after two interruptions = %+v, want 4 uploaded chunks", manifest)
+	}
+
+	// 截断第 3 个分块，大小与清单不符，续传时重新上传该分块
+	if err := os.Truncate(local.Location(chunkName("app.dockerimage", 3)), chunkSize/2); err != nil {
+		t.Fatal(err)
+	}
+
+	storage.puts = nil
+	manifest, err = PutChunked(storage, "app.dockerimage", bytes.NewReader(data), chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"chunk-000003", "chunk-000005"}; strings.Join(storage.puts, ",") != strings.Join(want, ",") {
+		t.Errorf("resumed PutChunked uploaded %v, want %v", storage.puts, want)
+	}
+	if !manifest.Complete || len(manifest.Chunks) != 5 || manifest.Size != int64(len(data)) {
+		t.Errorf("manifest = %+v, want 5 chunks of %d bytes", manifest, len(data))
+	}
+
+	reader, err := OpenChunked(storage, "app.dockerimage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, data) {
+		t.Errorf("OpenChunked returned %q, want %q", content, data)
+	}
+}
+
+func TestPutChunkedResumeChecksETag(t *testing.T) {
+	const chunkSize = 16
+	data := []byte(strings.Repeat("0123456789abcdef", 3))
+	tests := []struct {
+		name       string
+		etag       bool     // 存储是否提供 ETag
+		wantPuts   []string // 续传时上传的分块
+		openFailed bool     // 读取存档时是否发现分块损坏
+	}{
+		{name: "with etag", etag: true, wantPuts: []string{"chunk-000002", "chunk-000003"}, openFailed: false},
+		{name: "without etag", etag: false, wantPuts: []string{"chunk-000003"}, openFailed: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			local, err := OpenStorage(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var inner Storage = local
+			if test.etag {
+				inner = etagStorage{Storage: local}
+			}
+			storage := &countingStorage{Storage: inner}
+
+			if _, err := PutChunked(storage, "app.dockerimage", interruptedReader(data, 3*chunkSize-1), chunkSize); err == nil {
+				t.Fatal("first PutChunked should fail")
+			}
+
+			// 篡改第 2 个分块，大小不变，修改时间改变
+			chunkFile := local.Location(chunkName("app.dockerimage", 2))
+			if err := os.WriteFile(chunkFile, bytes.Repeat([]byte("x"), chunkSize), 0644); err != nil {
+				t.Fatal(err)
+			}
+			modTime := time.Now().Add(time.Hour)
+			if err := os.Chtimes(chunkFile, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+
+			// 大小相同时只有 ETag 能发现篡改，否则由读取时的摘要校验发现
+			storage.puts = nil
+			if _, err := PutChunked(storage, "app.dockerimage", bytes.NewReader(data), chunkSize); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(storage.puts, ",") != strings.Join(test.wantPuts, ",") {
+				t.Errorf("resumed PutChunked uploaded %v, want %v", storage.puts, test.wantPuts)
+			}
+
+			reader, err := OpenChunked(storage, "app.dockerimage")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			content, err := io.ReadAll(reader)
+			if (err != nil) != test.openFailed {
+				t.Fatalf("OpenChunked read error = %v, want failure %v", err, test.openFailed)
+			}
+			if err == nil && !bytes.Equal(content, data) {
+				t.Errorf("OpenChunked returned %q, want %q", content, data)
+			}
+		})
+	}
+}
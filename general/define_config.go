@@ -0,0 +1,407 @@
+/*
+File: define_config.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 18:05:12
+
+Description: 处理配置文件
+
+Notice:
+	- 配置的优先级：命令行参数 > 环境变量 > 配置文件中的 profile > 默认值
+	- 环境变量名为 'WOCKER_' 加上大写的配置项名，例如 WOCKER_OUTPUT_DIR
+*/
+
+package general
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	configFileName = "config.toml" // 配置文件名
+	envPrefix      = "WOCKER_"     // 环境变量前缀
+	profileEnv     = "WOCKER_PROFILE"
+
+	minIDLength = 4  // 显示 ID 的最小长度
+	maxIDLength = 64 // 显示 ID 的最大长度，即 sha256 的长度
+
+	maxChunkSize = 1024 // 分块的最大大小，单位 MiB，上传时每个分块需要完整读入内存
+)
+
+// 配置项来源
+const (
+	SourceDefault = "default" // 默认值
+	SourceProfile = "profile" // 配置文件中的 profile
+	SourceEnv     = "env"     // 环境变量
+)
+
+// Settings 一组配置项，同时用于配置文件中的 profile 和最终生效的配置
+type Settings struct {
+	Context         string `toml:"context" comment:"docker context name"`
+	Host            string `toml:"host" comment:"docker service address, overrides context, for example: 'ssh://user@server'"`
+	OutputDir       string `toml:"output_dir" comment:"folder to save image and volume archives to, or a storage URL: 's3://bucket/prefix', 'sftp://user@host/path', 'webdav://host/path' or 'webdavs://host/path'"`
+	HelperImage     string `toml:"helper_image" comment:"image of the helper containers used for volume operations, must provide sh and tar"`
+	Compress        bool   `toml:"compress" comment:"compress data with gzip when transferring between docker services"`
+	IDLength        int    `toml:"id_length" comment:"length of the IDs shown and used in archive names, 4 to 64"`
+	VolumeIdentity  string `toml:"volume_identity" comment:"marker between the volume name and the extension in volume archive names"`
+	VolumeExtension string `toml:"volume_extension" comment:"file extension of volume archives, the archives are gzip-compressed tar files"`
+	ImageTemplate   string `toml:"image_name_template" comment:"Go template for image archive names, fields: Repo, Tag, ID, Platform, Date, Time, for example: '{{.Repo}}/{{.Tag}}-{{.Date}}', empty for '<repo>_<tag>_<id>'"`
+	VolumeTemplate  string `toml:"volume_name_template" comment:"Go template for volume archive names, fields: Name, Identity, Date, Time, empty for '<name>_<identity>'"`
+	S3Endpoint      string `toml:"s3_endpoint" comment:"endpoint of the S3-compatible storage used by 's3://bucket/prefix' output directories, for example: 'minio.local:9000'"`
+	S3Region        string `toml:"s3_region" comment:"region of the S3-compatible storage, empty to detect it"`
+	S3Insecure      bool   `toml:"s3_insecure" comment:"connect to the S3-compatible storage over plain http"`
+	WebDAVInsecure  bool   `toml:"webdav_insecure" comment:"connect to 'webdav://' storages over plain http, sending the password in cleartext"`
+	SplitSize       string `toml:"split_size" comment:"split archives into numbered parts of at most this size with an index file, for removable media, for example: '4GB' for FAT32 or '4.7GB' for DVDs, empty to not split"`
+	ChunkSize       int    `toml:"chunk_size" comment:"split archives saved to remote storage into chunks of this many MiB, so that interrupted saves resume from the last uploaded chunk, for example: 64, 0 to upload archives as single files"`
+}
+
+// Config 配置文件
+type Config struct {
+	Profile  string              `toml:"profile" comment:"profile used when neither '--profile' nor WOCKER_PROFILE is set, empty to use only the defaults"`
+	Profiles map[string]Settings `toml:"profiles"`
+	Jobs     map[string]Job      `toml:"jobs,omitempty" comment:"scheduled backups run by 'wocker schedule --serve'"`
+
+	profileKeys map[string]map[string]bool // 配置文件中每个 profile 出现的配置项，由 LoadConfig 设置
+}
+
+// SettingItem 单个生效的配置项
+type SettingItem struct {
+	Key    string // 配置项名
+	Value  string // 配置项的值
+	Source string // 配置项来源
+}
+
+// CurrentSettings 当前生效的配置，由 LoadSettings 更新
+var CurrentSettings = DefaultSettings()
+
+// DefaultSettings 返回默认配置
+//
+// 返回：
+//   - 默认配置
+func DefaultSettings() Settings {
+	return Settings{
+		OutputDir:       ".",
+		HelperImage:     "busybox",
+		IDLength:        12,
+		VolumeIdentity:  "volume",
+		VolumeExtension: ".tar.gz",
+		S3Endpoint:      "s3.amazonaws.com",
+	}
+}
+
+// ConfigFile 返回默认的配置文件路径
+//
+//   - 位于用户配置文件夹中，Linux 上为 $XDG_CONFIG_HOME/wocker/config.toml，默认 ~/.config/wocker/config.toml
+//
+// 返回：
+//   - 配置文件路径
+func ConfigFile() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, strings.ToLower(Name), configFileName)
+}
+
+// LoadConfig 读取配置文件
+//
+//   - 配置文件不存在时返回空配置
+//   - 不允许未知的配置项
+//
+// 参数：
+//   - configFile: 配置文件路径
+//
+// 返回：
+//   - 配置
+//   - 错误信息
+func LoadConfig(configFile string) (Config, error) {
+	var config Config
+	content, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	decoder := toml.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		var strictErr *toml.StrictMissingError
+		if errors.As(err, &strictErr) {
+			return config, fmt.Errorf("%s: %s", configFile, strings.TrimSpace(strictErr.String()))
+		}
+		return config, fmt.Errorf("%s: %w", configFile, err)
+	}
+
+	// 记录 profile 中出现的配置项，以区分显式设置的零值和未设置的配置项
+	var raw struct {
+		Profiles map[string]map[string]any `toml:"profiles"`
+	}
+	if err := toml.Unmarshal(content, &raw); err != nil {
+		return config, fmt.Errorf("%s: %w", configFile, err)
+	}
+	config.profileKeys = make(map[string]map[string]bool)
+	for name, values := range raw.Profiles {
+		keys := make(map[string]bool)
+		for key := range values {
+			keys[key] = true
+		}
+		config.profileKeys[name] = keys
+	}
+	return config, nil
+}
+
+// LoadSettings 读取配置文件和环境变量，更新当前生效的配置
+//
+//   - profile 的优先级：参数 profileName > 环境变量 WOCKER_PROFILE > 配置文件中的 profile
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - profileName: profile 名，为空时按优先级选择
+//
+// 返回：
+//   - 生效的配置项及其来源
+//   - 错误信息
+func LoadSettings(configFile string, profileName string) ([]SettingItem, error) {
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if profileName == "" {
+		profileName = os.Getenv(profileEnv)
+	}
+	if profileName == "" {
+		profileName = config.Profile
+	}
+
+	settings, items, err := resolveSettings(config, profileName, true)
+	if err != nil {
+		return nil, err
+	}
+	CurrentSettings = settings
+	return items, nil
+}
+
+// resolveSettings 按优先级合并默认值、profile 和环境变量
+//
+// 参数：
+//   - config: 配置
+//   - profileName: profile 名，为空时不使用 profile
+//   - useEnv: 是否读取环境变量
+//
+// 返回：
+//   - 生效的配置
+//   - 生效的配置项及其来源
+//   - 错误信息
+func resolveSettings(config Config, profileName string, useEnv bool) (Settings, []SettingItem, error) {
+	settings := DefaultSettings()
+	var profile Settings
+	if profileName != "" {
+		found, ok := config.Profiles[profileName]
+		if !ok {
+			return settings, nil, fmt.Errorf(NoSuchProfileMessage, profileName)
+		}
+		profile = found
+	}
+
+	var items []SettingItem
+	settingsValue := reflect.ValueOf(&settings).Elem()
+	profileValue := reflect.ValueOf(profile)
+	for index := 0; index < settingsValue.NumField(); index++ {
+		key, _, _ := strings.Cut(settingsValue.Type().Field(index).Tag.Get("toml"), ",")
+		field := settingsValue.Field(index)
+		source := SourceDefault
+
+		// 读取自配置文件的 profile 按出现的配置项判断是否设置，包括显式设置的 false、0 和 ""，其他 profile 以零值表示未设置
+		value := profileValue.Field(index)
+		set := !value.IsZero()
+		if keys, ok := config.profileKeys[profileName]; ok {
+			set = keys[key]
+		}
+		if set {
+			field.Set(value)
+			source = sourceName(SourceProfile, profileName)
+		}
+
+		envName := envPrefix + strings.ToUpper(key)
+		if value, ok := os.LookupEnv(envName); ok && useEnv {
+			if err := setSettingField(field, value); err != nil {
+				return settings, nil, fmt.Errorf("%s: %w", envName, err)
+			}
+			source = sourceName(SourceEnv, envName)
+		}
+
+		items = append(items, SettingItem{Key: key, Value: fmt.Sprint(field.Interface()), Source: source})
+	}
+
+	if err := settings.Validate(); err != nil {
+		return settings, nil, err
+	}
+	return settings, items, nil
+}
+
+// sourceName 拼接配置项来源和来源的名称
+func sourceName(source string, name string) string {
+	return fmt.Sprintf("%s (%s)", source, name)
+}
+
+// setSettingField 将字符串形式的值写入配置项
+//
+// 参数：
+//   - field: 配置项
+//   - value: 字符串形式的值
+//
+// 返回：
+//   - 错误信息
+func setSettingField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	default:
+		field.SetString(value)
+	}
+	return nil
+}
+
+// Validate 检查配置项的取值范围
+//
+//   - 用命令行参数覆盖配置项后需要再次检查
+//
+// 返回：
+//   - 错误信息
+func (settings Settings) Validate() error {
+	if settings.IDLength < minIDLength || settings.IDLength > maxIDLength {
+		return fmt.Errorf("id_length must be between %d and %d, got %d", minIDLength, maxIDLength, settings.IDLength)
+	}
+	if settings.VolumeIdentity == "" || strings.ContainsAny(settings.VolumeIdentity, `/\`) {
+		return fmt.Errorf("volume_identity must be a non-empty file name part, got %q", settings.VolumeIdentity)
+	}
+	if !strings.HasPrefix(settings.VolumeExtension, ".") || len(settings.VolumeExtension) < 2 || strings.ContainsAny(settings.VolumeExtension, `/\`) {
+		return fmt.Errorf("volume_extension must start with '.' and be a file name part, got %q", settings.VolumeExtension)
+	}
+	if settings.SplitSize != "" {
+		if _, err := ParseSize(settings.SplitSize); err != nil {
+			return fmt.Errorf("split_size: %w", err)
+		}
+	}
+	if settings.ChunkSize < 0 || settings.ChunkSize > maxChunkSize {
+		return fmt.Errorf("chunk_size must be between 0 and %d, got %d", maxChunkSize, settings.ChunkSize)
+	}
+
+	// 使用示例数据检查模板
+	if settings.ImageTemplate != "" {
+		sample := ImageNameData{Repo: "repo", Tag: "tag", ID: "0123456789ab", Platform: "linux-amd64", Date: "20060102", Time: "150405"}
+		if _, err := RenderName(settings.ImageTemplate, sample); err != nil {
+			return fmt.Errorf("image_name_template: %w", err)
+		}
+	}
+	if settings.VolumeTemplate != "" {
+		if err := CheckVolumeTemplate(settings.VolumeTemplate, settings.VolumeIdentity); err != nil {
+			return fmt.Errorf("volume_name_template: %w", err)
+		}
+	}
+	return nil
+}
+
+// CheckConfig 检查配置文件
+//
+//   - 检查语法、未知的配置项、默认 profile 是否存在，以及每个 profile 和定时任务的取值和引用的 docker context
+//
+// 参数：
+//   - configFile: 配置文件路径
+//
+// 返回：
+//   - 发现的问题
+func CheckConfig(configFile string) []error {
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		return []error{err}
+	}
+
+	var problems []error
+	if config.Profile != "" {
+		if _, ok := config.Profiles[config.Profile]; !ok {
+			problems = append(problems, fmt.Errorf(NoSuchProfileMessage, config.Profile))
+		}
+	}
+	for name, profile := range config.Profiles {
+		// 未设置的配置项使用默认值后再检查
+		settings, _, err := resolveSettings(config, name, false)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("profile %s: %w", name, err))
+			continue
+		}
+		if profile.Context != "" {
+			if _, err := ResolveContext(profile.Context); err != nil {
+				problems = append(problems, fmt.Errorf("profile %s: %w", name, err))
+			}
+		}
+		if info, err := os.Stat(settings.OutputDir); err == nil && !info.IsDir() {
+			problems = append(problems, fmt.Errorf("profile %s: output_dir %s is not a folder", name, settings.OutputDir))
+		}
+	}
+	for name, job := range config.Jobs {
+		if err := job.Validate(); err != nil {
+			problems = append(problems, fmt.Errorf("job %s: %w", name, err))
+			continue
+		}
+		if job.Context != "" {
+			if _, err := ResolveContext(job.Context); err != nil {
+				problems = append(problems, fmt.Errorf("job %s: %w", name, err))
+			}
+		}
+	}
+	return problems
+}
+
+// CreateConfig 创建带有默认 profile 的配置文件
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - force: 配置文件已存在时是否覆盖
+//
+// 返回：
+//   - 错误信息
+func CreateConfig(configFile string, force bool) error {
+	if FileExist(configFile) && !force {
+		return errors.New(ConfigExistMessage)
+	}
+
+	config := Config{
+		Profile:  "default",
+		Profiles: map[string]Settings{"default": DefaultSettings()},
+	}
+	content, err := toml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	file, err := ReCreateFile(configFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(content)
+	return err
+}
@@ -0,0 +1,90 @@
+/*
+File: define_config_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 11:58:21
+
+Description: 配置合并测试
+*/
+
+package general
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSettingsExplicitZeroValues(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), configFileName)
+	content := `
+[profiles.base]
+s3_insecure = true
+output_dir = '/backup'
+
+[profiles.secure]
+s3_insecure = false
+output_dir = ''
+s3_endpoint = ''
+
+[profiles.partial]
+id_length = 16
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := DefaultSettings()
+	tests := []struct {
+		profile string
+		check   func(Settings) bool
+	}{
+		{"base", func(settings Settings) bool {
+			return settings.S3Insecure && settings.OutputDir == "/backup" && settings.S3Endpoint == defaults.S3Endpoint
+		}},
+		{"secure", func(settings Settings) bool {
+			return !settings.S3Insecure && settings.OutputDir == "" && settings.S3Endpoint == ""
+		}},
+		{"partial", func(settings Settings) bool {
+			return settings.IDLength == 16 && settings.OutputDir == defaults.OutputDir && settings.VolumeExtension == defaults.VolumeExtension
+		}},
+	}
+	for _, test := range tests {
+		settings, items, err := resolveSettings(config, test.profile, false)
+		if err != nil {
+			t.Fatalf("profile %s: %v", test.profile, err)
+		}
+		if !test.check(settings) {
+			t.Errorf("profile %s resolved to %+v", test.profile, settings)
+		}
+		for _, item := range items {
+			if item.Key == "output_dir" && test.profile == "secure" && item.Source != sourceName(SourceProfile, "secure") {
+				t.Errorf("output_dir source = %s, want the profile", item.Source)
+			}
+		}
+	}
+
+	// 不是从配置文件读取的 profile 以零值表示未设置
+	config = Config{Profiles: map[string]Settings{"code": {IDLength: 8}}}
+	settings, _, err := resolveSettings(config, "code", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.IDLength != 8 || settings.OutputDir != defaults.OutputDir {
+		t.Errorf("profile code resolved to %+v", settings)
+	}
+}
+
+func TestValidateVolumeExtension(t *testing.T) {
+	for extension, valid := range map[string]bool{".tar.gz": true, ".tgz": true, "tar.gz": false, ".": false, "./x": false, "": false} {
+		settings := DefaultSettings()
+		settings.VolumeExtension = extension
+		if err := settings.Validate(); (err == nil) != valid {
+			t.Errorf("volume_extension %q: Validate() = %v, want valid %v", extension, err, valid)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+/*
+File: define_context.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:20:36
+
+Description: 连接 docker context 和远程 docker service
+*/
+
+package general
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+const defaultContextName = "default" // 使用环境变量的 context
+
+// DockerContext docker context 的连接信息
+type DockerContext struct {
+	Name          string // context 名
+	Host          string // docker service 地址，为空时使用环境变量
+	TLSDir        string // TLS 证书所在文件夹，包含 ca.pem、cert.pem 和 key.pem，为空时不使用 context 的证书
+	SkipTLSVerify bool   // 是否跳过 TLS 证书校验
+}
+
+// contextMetadata docker context 的元数据文件 meta.json
+type contextMetadata struct {
+	Name      string `json:"Name"`
+	Endpoints map[string]struct {
+		Host          string `json:"Host"`
+		SkipTLSVerify bool   `json:"SkipTLSVerify"`
+	} `json:"Endpoints"`
+}
+
+// CurrentContextName 获取当前使用的 docker context 名
+//
+//   - 优先级与 docker 命令一致：环境变量 DOCKER_CONTEXT、配置文件中的 currentContext、'default'
+//
+// 返回：
+//   - context 名
+func CurrentContextName() string {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name
+	}
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if data, err := os.ReadFile(filepath.Join(DockerConfigDir(), "config.json")); err == nil {
+		if json.Unmarshal(data, &config) == nil && config.CurrentContext != "" {
+			return config.CurrentContext
+		}
+	}
+	return defaultContextName
+}
+
+// ResolveContext 读取 docker context 的连接信息
+//
+//   - context 保存在 ~/.docker/contexts/meta/<sha256(name)>/meta.json，证书保存在 ~/.docker/contexts/tls/<sha256(name)>/docker
+//
+// 参数：
+//   - name: context 名，'default' 表示使用环境变量
+//
+// 返回：
+//   - 连接信息
+//   - 错误信息
+func ResolveContext(name string) (DockerContext, error) {
+	dockerContext := DockerContext{Name: name}
+	if name == defaultContextName {
+		return dockerContext, nil
+	}
+
+	hash := sha256.Sum256([]byte(name))
+	contextID := hex.EncodeToString(hash[:])
+	data, err := os.ReadFile(filepath.Join(DockerConfigDir(), "contexts", "meta", contextID, "meta.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dockerContext, fmt.Errorf(NoSuchContextMessage, name)
+		}
+		return dockerContext, err
+	}
+	var metadata contextMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return dockerContext, err
+	}
+	endpoint, ok := metadata.Endpoints["docker"]
+	if !ok {
+		return dockerContext, fmt.Errorf("context %s has no docker endpoint", name)
+	}
+	dockerContext.Host, dockerContext.SkipTLSVerify = endpoint.Host, endpoint.SkipTLSVerify
+
+	tlsDir := filepath.Join(DockerConfigDir(), "contexts", "tls", contextID, "docker")
+	if FileExist(filepath.Join(tlsDir, "ca.pem")) || FileExist(filepath.Join(tlsDir, "cert.pem")) {
+		dockerContext.TLSDir = tlsDir
+	}
+	return dockerContext, nil
+}
+
+// NewDockerClient 创建连接指定 docker service 的客户端
+//
+//   - host 为空时与 docker 命令一样读取环境变量 DOCKER_HOST、DOCKER_TLS_VERIFY 和 DOCKER_CERT_PATH
+//   - 'ssh://' 通过 ssh 在远程主机上执行 `docker system dial-stdio` 建立连接
+//   - 'tcp://' 在指定证书文件夹或设置了环境变量 DOCKER_CERT_PATH 时使用 TLS
+//
+// 参数：
+//   - dockerContext: 连接信息
+//
+// 返回：
+//   - docker 客户端
+//   - 错误信息
+func NewDockerClient(dockerContext DockerContext) (*client.Client, error) {
+	options := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+
+	if dockerContext.Host != "" {
+		hostURL, err := url.Parse(dockerContext.Host)
+		if err != nil {
+			return nil, err
+		}
+		if hostURL.Scheme == "ssh" {
+			options = append(options, client.WithHost("http://docker"), client.WithDialContext(sshDialer(hostURL)))
+		} else {
+			options = append(options, client.WithHost(dockerContext.Host))
+		}
+	}
+
+	if dockerContext.TLSDir != "" {
+		caFile := filepath.Join(dockerContext.TLSDir, "ca.pem")
+		if dockerContext.SkipTLSVerify || !FileExist(caFile) {
+			caFile = ""
+		}
+		options = append(options, client.WithTLSClientConfig(caFile, filepath.Join(dockerContext.TLSDir, "cert.pem"), filepath.Join(dockerContext.TLSDir, "key.pem")))
+	}
+
+	return client.NewClientWithOpts(options...)
+}
+
+// newDockerClientFor 创建连接指定 context 或地址的客户端
+//
+// 参数：
+//   - contextName: docker context 名，为空时使用当前 context
+//   - host: docker service 地址，不为空时优先于 contextName
+//
+// 返回：
+//   - docker 客户端
+//   - 错误信息
+func newDockerClientFor(contextName string, host string) (*client.Client, error) {
+	dockerContext := DockerContext{Host: host}
+	if host == "" {
+		if contextName == "" {
+			contextName = CurrentContextName()
+		}
+		resolved, err := ResolveContext(contextName)
+		if err != nil {
+			return nil, err
+		}
+		dockerContext = resolved
+	}
+	return NewDockerClient(dockerContext)
+}
+
+// UseDocker 切换后续操作使用的 docker service
+//
+// 参数：
+//   - contextName: docker context 名，为空时使用当前 context
+//   - host: docker service 地址，例如 'ssh://user@server'、'tcp://server:2376'，不为空时优先于 contextName
+//
+// 返回：
+//   - 错误信息
+func UseDocker(contextName string, host string) error {
+	newClient, err := newDockerClientFor(contextName, host)
+	if err != nil {
+		return err
+	}
+	if docker != nil {
+		docker.Close()
+	}
+	docker = newClient
+	return nil
+}
+
+// WithContext 临时切换到指定 context 执行操作，完成后恢复原来的 docker service
+//
+// 参数：
+//   - contextName: docker context 名
+//   - function: 要执行的操作
+//
+// 返回：
+//   - 错误信息，包括连接错误和操作返回的错误
+func WithContext(contextName string, function func() error) error {
+	newClient, err := newDockerClientFor(contextName, "")
+	if err != nil {
+		return err
+	}
+	defer newClient.Close()
+
+	original := docker
+	docker = newClient
+	defer func() { docker = original }()
+
+	return function()
+}
+
+// IsLocalDaemon 判断当前 docker service 是否运行在本机
+//
+//   - 只有通过本地 socket 连接的 docker service 才能挂载本机的文件夹
+//
+// 返回：
+//   - 是否运行在本机
+func IsLocalDaemon() bool {
+	return isLocalSocket(docker.DaemonHost())
+}
+
+// isLocalSocket 判断 docker service 地址是否是本地 socket，即 'unix://' 或 'npipe://'
+func isLocalSocket(host string) bool {
+	return strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://")
+}
+
+// sshDialer 返回通过 ssh 连接远程 docker service 的拨号函数
+//
+// 参数：
+//   - hostURL: ssh 地址，例如 'ssh://user@server:22'
+//
+// 返回：
+//   - 拨号函数
+func sshDialer(hostURL *url.URL) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	args := []string{}
+	if hostURL.User != nil {
+		args = append(args, "-l", hostURL.User.Username())
+	}
+	if port := hostURL.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	args = append(args, "--", hostURL.Hostname(), "docker", "system", "dial-stdio")
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// 连接的生命周期不受单次请求的 ctx 限制
+		cmd := exec.Command("ssh", args...)
+		cmd.Stderr = os.Stderr
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+	}
+}
+
+// commandConn 以子进程的标准输入输出作为连接
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (conn *commandConn) Read(data []byte) (int, error)  { return conn.stdout.Read(data) }
+func (conn *commandConn) Write(data []byte) (int, error) { return conn.stdin.Write(data) }
+
+func (conn *commandConn) Close() error {
+	conn.stdin.Close()
+	conn.cmd.Process.Kill()
+	conn.cmd.Wait()
+	return nil
+}
+
+func (conn *commandConn) LocalAddr() net.Addr                { return commandAddr{} }
+func (conn *commandConn) RemoteAddr() net.Addr               { return commandAddr{} }
+func (conn *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (conn *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (conn *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// commandAddr commandConn 的地址
+type commandAddr struct{}
+
+func (commandAddr) Network() string { return "command" }
+func (commandAddr) String() string  { return "command" }
@@ -0,0 +1,28 @@
+/*
+File: define_context_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 16:02:11
+
+Description: docker service 地址判断测试
+*/
+
+package general
+
+import "testing"
+
+func TestIsLocalSocket(t *testing.T) {
+	tests := map[string]bool{
+		"unix:///var/run/docker.sock":      true,
+		"npipe:////./pipe/docker_engine":   true,
+		"tcp://10.0.0.5:2376":              false,
+		"ssh://user@server":                false,
+		"http://docker.example.com":        false,
+		"unix-like://not/really/a/socket/": false,
+	}
+	for host, want := range tests {
+		if got := isLocalSocket(host); got != want {
+			t.Errorf("isLocalSocket(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
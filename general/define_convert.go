@@ -9,6 +9,13 @@ Description: 单位和格式数据转换
 
 package general
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
 // Human 存储数据转换为人类可读的格式
 //
 // 参数：
@@ -40,3 +47,55 @@ func Human(size float64, initialUnit string) (float64, string) {
 
 	return size, initialUnit
 }
+
+// HumanSize 将字节数转换为人类可读的字符串
+//
+// 参数：
+//   - size: 字节数
+//
+// 返回：
+//   - 格式化的字符串，例如 '  12.3 MiB'
+func HumanSize(size int64) string {
+	value, unit := Human(float64(size), "B")
+	return fmt.Sprintf("%6.1f %s", value, unit)
+}
+
+// sizeUnits 大小单位对应的字节数，不带 'i' 的单位按 1000 进位
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"m":   1e6,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"g":   1e9,
+	"gb":  1e9,
+	"gib": 1 << 30,
+	"t":   1e12,
+	"tb":  1e12,
+	"tib": 1 << 40,
+}
+
+// ParseSize 将人类可读的大小转换为字节数
+//
+// 参数：
+//   - size: 大小，例如 '4GB'、'4.7G' 或 '700MiB'，'GB' 和 'G' 按 1000 进位，'GiB' 按 1024 进位
+//
+// 返回：
+//   - 字节数
+//   - 错误信息，小于 1 字节时返回错误
+func ParseSize(size string) (int64, error) {
+	trimmed := strings.TrimSpace(size)
+	number := strings.TrimRight(trimmed, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ ")
+	multiplier, ok := sizeUnits[strings.ToLower(strings.TrimSpace(trimmed[len(number):]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit: %q", size)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value*multiplier < 1 || value*multiplier >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size: %q", size)
+	}
+	return int64(value * multiplier), nil
+}
@@ -0,0 +1,199 @@
+/*
+File: define_cron.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 20:41:17
+
+Description: 解析 cron 表达式并计算下次运行时间
+
+Notice:
+	- 支持标准的 5 个字段：分钟、小时、日、月、星期，以及 '@hourly'、'@daily'、'@weekly'、'@monthly'、'@yearly'
+	- 字段支持 '*'、列表 '1,15'、范围 '1-5'、步长 '0-30/10' 以及月份和星期的英文缩写
+	- 日和星期都不是 '*' 时，满足其中之一即可，与 cron 一致
+*/
+
+package general
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros cron 表达式的简写
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField cron 表达式中单个字段的取值范围和名称
+type cronField struct {
+	name  string         // 字段名，用于错误信息
+	min   int            // 最小值
+	max   int            // 最大值
+	names map[string]int // 英文缩写对应的值
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}},
+	{name: "day of week", min: 0, max: 7, names: map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}},
+}
+
+// Schedule 解析后的 cron 表达式
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // 各字段允许的值，按位表示
+	domStar, dowStar              bool   // 日和星期是否为 '*'
+}
+
+// ParseSchedule 解析 cron 表达式
+//
+// 参数：
+//   - expression: cron 表达式，例如 '30 3 * * *' 或 '@daily'
+//
+// 返回：
+//   - 解析后的 cron 表达式
+//   - 错误信息
+func ParseSchedule(expression string) (Schedule, error) {
+	var schedule Schedule
+	if macro, ok := cronMacros[strings.ToLower(strings.TrimSpace(expression))]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return schedule, fmt.Errorf("invalid schedule %q: expected %d fields, got %d", expression, len(cronFields), len(fields))
+	}
+
+	var bits [5]uint64
+	for index, field := range fields {
+		value, err := parseCronField(field, cronFields[index])
+		if err != nil {
+			return schedule, fmt.Errorf("invalid schedule %q: %w", expression, err)
+		}
+		bits[index] = value
+	}
+	// 星期中的 7 与 0 都表示星期日
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	schedule = Schedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	return schedule, nil
+}
+
+// parseCronField 解析 cron 表达式中的单个字段
+//
+// 参数：
+//   - field: 字段内容
+//   - spec: 字段的取值范围和名称
+//
+// 返回：
+//   - 字段允许的值，按位表示
+//   - 错误信息
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, spec.name)
+			}
+			step = parsed
+		}
+
+		start, end := spec.min, spec.max
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(startPart, spec); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseCronValue(endPart, spec); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// 'N/step' 表示从 N 开始到最大值
+				end = spec.max
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, spec.name)
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue 解析字段中的单个值
+func parseCronValue(value string, spec cronField) (int, error) {
+	if number, ok := spec.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < spec.min || number > spec.max {
+		return 0, fmt.Errorf("invalid value %q in %s, expected %d-%d", value, spec.name, spec.min, spec.max)
+	}
+	return number, nil
+}
+
+// matchDay 判断日期是否满足日和星期字段
+func (schedule Schedule) matchDay(moment time.Time) bool {
+	domMatch := schedule.dom&(1<<moment.Day()) != 0
+	dowMatch := schedule.dow&(1<<moment.Weekday()) != 0
+	if schedule.domStar || schedule.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next 计算指定时间之后的下次运行时间
+//
+// 参数：
+//   - after: 起始时间，不包括该时间本身
+//
+// 返回：
+//   - 下次运行时间，5 年内没有满足条件的时间时返回零值
+func (schedule Schedule) Next(after time.Time) time.Time {
+	moment := after.Truncate(time.Minute).Add(time.Minute)
+	limit := moment.AddDate(5, 0, 0)
+	for moment.Before(limit) {
+		year, month, day := moment.Date()
+		location := moment.Location()
+		switch {
+		case schedule.month&(1<<month) == 0:
+			moment = time.Date(year, month+1, 1, 0, 0, 0, 0, location)
+		case !schedule.matchDay(moment):
+			moment = time.Date(year, month, day+1, 0, 0, 0, 0, location)
+		case schedule.hour&(1<<moment.Hour()) == 0:
+			moment = time.Date(year, month, day, moment.Hour()+1, 0, 0, 0, location)
+		case schedule.minute&(1<<moment.Minute()) == 0:
+			moment = moment.Add(time.Minute)
+		default:
+			return moment
+		}
+	}
+	return time.Time{}
+}
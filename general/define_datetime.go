@@ -9,7 +9,12 @@ Description: 处理日期/时间
 
 package general
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // UnixTime2TimeString Unix 时间戳转换为字符串格式
 //
@@ -38,3 +43,30 @@ func GetCurrentTimestamp(format string) string {
 
 	return timestamp
 }
+
+// ParseDuration 解析时间间隔字符串
+//
+//   - 在 time.ParseDuration 的基础上支持 'd'（天）和 'w'（周）单位，例如 '30d'、'2w'
+//
+// 参数：
+//   - duration: 时间间隔字符串
+//
+// 返回：
+//   - 时间间隔
+//   - 错误信息
+func ParseDuration(duration string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if value, found := strings.CutSuffix(duration, suffix); found {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", duration)
+			}
+			return time.Duration(number * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(duration)
+}
@@ -0,0 +1,365 @@
+/*
+File: define_diff.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:31:48
+
+Description: 比较文件系统，以及两个 image 的层和配置
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// 文件变化类型
+const (
+	ChangeAdded    = "Added"    // 新增
+	ChangeRemoved  = "Removed"  // 删除
+	ChangeModified = "Modified" // 修改
+)
+
+// 层的 whiteout 标记，见 OCI image-spec 的 layer 规范
+const (
+	whiteoutPrefix = ".wh."         // 删除下层中同名的文件
+	whiteoutOpaque = ".wh..wh..opq" // 删除下层中所在文件夹的全部内容
+)
+
+// specialFileType 设备文件和命名管道等特殊文件统一使用的类型
+const specialFileType = tar.TypeFifo
+
+// FileEntry 文件系统中的条目
+type FileEntry struct {
+	Type     byte        // 条目类型，与 tar.Header.Typeflag 一致，硬链接视为普通文件，特殊文件为 specialFileType
+	Size     int64       // 大小
+	Mode     os.FileMode // 权限
+	Linkname string      // 符号链接目标
+	Checksum string      // 普通文件内容的 sha256
+}
+
+// ImageSnapshot image 的配置、层和合并所有层之后的文件系统
+type ImageSnapshot struct {
+	Name   string               // image 名
+	Config ocispec.Image        // 配置
+	Layers []ArchiveLayer       // 层，从底层到顶层
+	Files  map[string]FileEntry // 文件系统，键为规范化后的路径
+}
+
+// FileChange 文件变化
+type FileChange struct {
+	Path    string // 路径
+	Change  string // 变化类型
+	OldSize int64  // 原大小，新增时为 0
+	NewSize int64  // 新大小，删除时为 0
+}
+
+// LayerCreatedBy 返回与各层一一对应的创建命令
+//
+//   - 只有产生了层的历史记录才与层一一对应
+//
+// 参数：
+//   - config: image 配置
+//
+// 返回：
+//   - 创建命令切片，从底层到顶层
+func LayerCreatedBy(config ocispec.Image) []string {
+	var createdBy []string
+	for _, history := range config.History {
+		if !history.EmptyLayer {
+			createdBy = append(createdBy, history.CreatedBy)
+		}
+	}
+	return createdBy
+}
+
+// SnapshotImage 读取 image 的配置、层和文件系统
+//
+//   - source 为已存在的文件时视为 docker save 存档（允许压缩），否则视为本地 image 的引用或 ID
+//   - 存档包含多个 image 时只读取第一个
+//
+// 参数：
+//   - source: 存档文件或 image 引用
+//
+// 返回：
+//   - image 快照
+//   - 错误信息
+func SnapshotImage(source string) (ImageSnapshot, error) {
+	snapshot := ImageSnapshot{Name: source, Files: make(map[string]FileEntry)}
+
+	var reader io.ReadCloser
+	if FileExist(source) {
+		archive, err := OpenArchive(source)
+		if err != nil {
+			return snapshot, err
+		}
+		reader = archive
+	} else {
+		saved, err := docker.ImageSave(ctx, []string{source})
+		if err != nil {
+			return snapshot, err
+		}
+		reader = saved
+	}
+	defer reader.Close()
+
+	// 解包到临时文件夹
+	tempDir, err := os.MkdirTemp("", "wocker-diff-")
+	if err != nil {
+		return snapshot, err
+	}
+	defer os.RemoveAll(tempDir)
+	if err := ExtractTar(reader, tempDir); err != nil {
+		return snapshot, err
+	}
+
+	manifests, err := ReadDockerArchiveManifest(tempDir)
+	if err != nil {
+		return snapshot, err
+	}
+	if len(manifests) == 0 {
+		return snapshot, fmt.Errorf("no image in %s", source)
+	}
+	manifest := manifests[0]
+	if len(manifest.RepoTags) > 0 {
+		snapshot.Name = manifest.RepoTags[0]
+	}
+
+	configData, err := os.ReadFile(filepath.Join(tempDir, filepath.FromSlash(CleanArchivePath(manifest.Config))))
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(configData, &snapshot.Config); err != nil {
+		return snapshot, err
+	}
+
+	createdBy := LayerCreatedBy(snapshot.Config)
+	for index, layer := range manifest.Layers {
+		layerFile := filepath.Join(tempDir, filepath.FromSlash(CleanArchivePath(layer)))
+		info, err := os.Stat(layerFile)
+		if err != nil {
+			return snapshot, err
+		}
+		archiveLayer := ArchiveLayer{Path: layer, Size: info.Size()}
+		if index < len(snapshot.Config.RootFS.DiffIDs) {
+			archiveLayer.DiffID = snapshot.Config.RootFS.DiffIDs[index].String()
+		}
+		if index < len(createdBy) {
+			archiveLayer.CreatedBy = createdBy[index]
+		}
+		snapshot.Layers = append(snapshot.Layers, archiveLayer)
+
+		if err := applyLayerFile(snapshot.Files, layerFile); err != nil {
+			return snapshot, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+// applyLayerFile 将层文件叠加到文件系统上
+//
+// 参数：
+//   - files: 文件系统
+//   - layerFile: 层文件，允许压缩
+//
+// 返回：
+//   - 错误信息
+func applyLayerFile(files map[string]FileEntry, layerFile string) error {
+	reader, err := OpenArchive(layerFile)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return applyLayer(files, reader)
+}
+
+// removeTree 从文件系统中删除指定路径下的条目
+//
+// 参数：
+//   - files: 文件系统
+//   - root: 路径，为空时表示根目录
+//   - self: 是否同时删除路径本身
+//   - keep: 不删除的条目
+func removeTree(files map[string]FileEntry, root string, self bool, keep map[string]bool) {
+	for name := range files {
+		if keep[name] {
+			continue
+		}
+		if (self && name == root) || root == "" || strings.HasPrefix(name, root+"/") {
+			delete(files, name)
+		}
+	}
+}
+
+// applyLayer 将层的 tar 流叠加到文件系统上，处理 whiteout 标记
+//
+// 参数：
+//   - files: 文件系统
+//   - reader: 层的 tar 流
+//
+// 返回：
+//   - 错误信息
+func applyLayer(files map[string]FileEntry, reader io.Reader) error {
+	added := make(map[string]bool) // 当前层写入的条目，不受当前层的 opaque 标记影响
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := CleanArchivePath(header.Name)
+		if name == "" {
+			continue
+		}
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+
+		switch {
+		case base == whiteoutOpaque:
+			removeTree(files, dir, false, added)
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			removeTree(files, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), true, nil)
+			continue
+		}
+
+		file, err := tarFileEntry(header, tarReader, files)
+		if err != nil {
+			return err
+		}
+
+		// 非文件夹覆盖了原有的文件夹时，删除其下的内容
+		if previous, ok := files[name]; ok && previous.Type == tar.TypeDir && file.Type != tar.TypeDir {
+			removeTree(files, name, false, nil)
+		}
+		files[name] = file
+		added[name] = true
+	}
+}
+
+// tarFileEntry 根据 tar 条目生成文件系统条目，普通文件计算内容的 sha256
+//
+// 参数：
+//   - header: tar 条目头
+//   - reader: tar 条目内容
+//   - files: 已读取的文件系统，用于解析硬链接
+//
+// 返回：
+//   - 文件系统条目
+//   - 错误信息
+func tarFileEntry(header *tar.Header, reader io.Reader, files map[string]FileEntry) (FileEntry, error) {
+	file := FileEntry{
+		Type:     header.Typeflag,
+		Size:     header.Size,
+		Mode:     header.FileInfo().Mode().Perm(),
+		Linkname: header.Linkname,
+	}
+	switch header.Typeflag {
+	case tar.TypeReg:
+		hash := sha256.New()
+		if _, err := io.Copy(hash, reader); err != nil {
+			return file, err
+		}
+		file.Checksum = hex.EncodeToString(hash.Sum(nil))
+	case tar.TypeLink:
+		// 硬链接与其目标内容相同
+		if target, ok := files[CleanArchivePath(header.Linkname)]; ok {
+			file = target
+		}
+	case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		file.Type = specialFileType
+	}
+	return file, nil
+}
+
+// ArchiveFiles 读取存档中的所有条目
+//
+// 参数：
+//   - archiveFile: 存档文件，允许压缩
+//
+// 返回：
+//   - 文件系统，键为规范化后的路径
+//   - 错误信息
+func ArchiveFiles(archiveFile string) (map[string]FileEntry, error) {
+	reader, err := OpenArchive(archiveFile)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	files := make(map[string]FileEntry)
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := CleanArchivePath(header.Name)
+		if name == "" {
+			continue
+		}
+		file, err := tarFileEntry(header, tarReader, files)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = file
+	}
+}
+
+// DiffFiles 比较两个文件系统中除文件夹以外的条目
+//
+//   - 类型、大小、权限、链接目标或内容不同时视为修改，不比较文件夹
+//
+// 参数：
+//   - oldFiles: 原文件系统
+//   - newFiles: 新文件系统
+//
+// 返回：
+//   - 文件变化切片，按路径排序
+func DiffFiles(oldFiles map[string]FileEntry, newFiles map[string]FileEntry) []FileChange {
+	var changes []FileChange
+	for name, oldFile := range oldFiles {
+		if oldFile.Type == tar.TypeDir {
+			continue
+		}
+		newFile, ok := newFiles[name]
+		switch {
+		case !ok || newFile.Type == tar.TypeDir:
+			changes = append(changes, FileChange{Path: name, Change: ChangeRemoved, OldSize: oldFile.Size})
+		case oldFile != newFile:
+			changes = append(changes, FileChange{Path: name, Change: ChangeModified, OldSize: oldFile.Size, NewSize: newFile.Size})
+		}
+	}
+	for name, newFile := range newFiles {
+		if newFile.Type == tar.TypeDir {
+			continue
+		}
+		if oldFile, ok := oldFiles[name]; !ok || oldFile.Type == tar.TypeDir {
+			changes = append(changes, FileChange{Path: name, Change: ChangeAdded, NewSize: newFile.Size})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
@@ -10,15 +10,25 @@ Description: 与 docker 交互
 package general
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/gookit/color"
 )
 
@@ -49,23 +59,36 @@ func ListVolumes() (volume.ListResponse, error) {
 	return docker.VolumeList(ctx, volume.ListOptions{})
 }
 
+// DiskUsage 获取 docker 的磁盘使用情况
+//
+//   - 功能与命令 `docker system df --verbose` 一样，但显示效果不一样
+//
+// 返回：
+//   - 磁盘使用情况
+//   - 错误信息
+func DiskUsage() (types.DiskUsage, error) {
+	return docker.DiskUsage(ctx, types.DiskUsageOptions{})
+}
+
 // SaveImage 将指定 image 保存到存档文件
 //
 //   - 功能与命令 `docker save <imageName> -o <archiveFile>` 一样
+//   - 指定平台时，先解包到临时文件夹，只保留该平台的内容后重新打包
 //
 // 参数：
 //   - imageName: image 的 Repository(:Tag) 或 ID
 //   - archiveFile: 存档文件
+//   - platform: 平台，例如 'linux/arm64'，为空时保留存档中的所有平台
 //
 // 返回：
 //   - 错误信息
-func SaveImage(imageName string, archiveFile string) error {
+func SaveImage(imageName string, archiveFile string, platform string) error {
 	// 检索指定 image 为 io.ReadCloser
-	reader, err := docker.ImageSave(ctx, []string{imageName})
+	content, err := saveImage(docker, imageName, platform)
 	if err != nil {
 		return err
 	}
-	defer reader.Close()
+	defer content.Close()
 
 	// 创建文件
 	file, err := ReCreateFile(archiveFile)
@@ -75,7 +98,7 @@ func SaveImage(imageName string, archiveFile string) error {
 	defer file.Close()
 
 	// 将镜像数据写入文件
-	_, err = io.Copy(file, reader)
+	_, err = io.Copy(file, content)
 	if err != nil {
 		fileName, lineNo := GetCallerInfo()
 		color.Printf("%s %s %s\n", DangerText(ErrorInfoFlag), SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
@@ -85,6 +108,55 @@ func SaveImage(imageName string, archiveFile string) error {
 	return nil
 }
 
+// saveImage 获取指定 image 的 docker save 存档流
+//
+// 参数：
+//   - dockerClient: docker 客户端
+//   - imageName: image 名
+//   - platform: 只保留指定平台，例如 'linux/amd64'，为空时保留全部平台
+//
+// 返回：
+//   - docker save 存档流，使用完毕后需要关闭
+//   - 错误信息
+func saveImage(dockerClient *client.Client, imageName string, platform string) (io.ReadCloser, error) {
+	reader, err := dockerClient.ImageSave(ctx, []string{imageName})
+	if err != nil {
+		return nil, err
+	}
+	if platform == "" {
+		return reader, nil
+	}
+	defer reader.Close()
+
+	// 解包到临时文件夹并筛选平台
+	tempDir, err := os.MkdirTemp("", "wocker-save-")
+	if err != nil {
+		return nil, err
+	}
+	if err := ExtractTar(reader, tempDir); err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+	if err := FilterArchivePlatform(tempDir, platform); err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(TarDirectory(tempDir, pipeWriter, func(string) bool { return true }))
+	}()
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{pipeReader, closerFunc(func() error {
+		err := pipeReader.Close()
+		os.RemoveAll(tempDir)
+		return err
+	})}, nil
+}
+
 // SaveVolume 将指定 volume 保存到存档文件
 //
 //   - 功能与命令 `docker run --rm -v <volumeName>:/volume -v <filePath>:/backup busybox tar czf /backup/<archiveFile> -C /volume .` 一样
@@ -105,8 +177,8 @@ func SaveVolume(volumeName string, filePath string, archiveFile string) error {
 
 	// 创建一个临时容器并挂载 volume
 	containerConfig := &container.Config{
-		// 基于 busybox 镜像创建容器
-		Image: "busybox",
+		// 基于辅助镜像（默认 busybox）创建容器
+		Image: CurrentSettings.HelperImage,
 		// 使用 tar 打包 volume 中的文件
 		Cmd: []string{"tar", "czf", backupFileInContainer, "-C", volumePathInContainer, "."},
 	}
@@ -120,19 +192,60 @@ func SaveVolume(volumeName string, filePath string, archiveFile string) error {
 		},
 	}
 
-	// 创建容器，容器名称留空使其随机生成
-	resp, err := docker.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	return runHelperContainer(containerConfig, hostConfig)
+}
+
+// SaveImageToStorage 将指定 image 保存到存储
+//
+//   - 远程存储在设置了 chunk_size 时分块保存，中断后再次保存时从最后一个已上传的分块继续
+//
+// 参数：
+//   - imageName: image 的 Repository(:Tag) 或 ID
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//   - platform: 平台，例如 'linux/arm64'，为空时保留存档中的所有平台
+//
+// 返回：
+//   - 错误信息
+func SaveImageToStorage(imageName string, storage Storage, name string, platform string) error {
+	content, err := saveImage(docker, imageName, platform)
 	if err != nil {
 		return err
 	}
-	containerID := resp.ID
+	defer content.Close()
 
-	// 启动容器
-	if err := docker.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
-		return err
+	return putArchive(storage, name, content)
+}
+
+// SaveVolumeToStorage 将指定 volume 打包压缩后保存到存储
+//
+//   - 数据经由 docker API 读取，不需要挂载本地文件夹
+//   - 远程存储在设置了 chunk_size 时分块保存，中断后再次保存时从最后一个已上传的分块继续
+//
+// 参数：
+//   - volumeName: volume 名
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//
+// 返回：
+//   - 存档的 sha256 摘要，格式为 'sha256:<hex>'
+//   - 错误信息
+func SaveVolumeToStorage(volumeName string, storage Storage, name string) (string, error) {
+	content, err := CopyFromVolume(volumeName)
+	if err != nil {
+		return "", err
 	}
+	defer content.Close()
 
-	return nil
+	compressed := gzipStream(content)
+	defer compressed.Close()
+
+	// 写入的同时计算摘要
+	hash := sha256.New()
+	if err := putArchive(storage, name, io.TeeReader(compressed, hash)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
 }
 
 type LoadResponse struct {
@@ -145,26 +258,41 @@ type LoadResponse struct {
 //   - 功能与命令 `docker load -i <archiveFile>` 一样
 //
 // 参数：
-//   - archiveFile: 存档文件
+//   - archiveFile: 存档文件或远程存档的 URL
 //
 // 返回：
 //   - docker service 是否返回错误信息
 //   - docker service 的返回信息
 //   - 错误信息
 func LoadImage(archiveFile string) (bool, []string, error) {
-	var (
-		result  bool     = false
-		message []string = make([]string, 0)
-	)
 	// 打开 tar 存档 文件
-	file, err := os.Open(archiveFile)
+	file, err := OpenLocation(archiveFile)
 	if err != nil {
-		return result, message, err
+		return false, make([]string, 0), err
 	}
 	defer file.Close()
 
-	// 从 tar 存档文件加载 image
-	response, err := docker.ImageLoad(ctx, file, true)
+	return loadImage(docker, file)
+}
+
+// loadImage 从 docker save 存档流加载 image
+//
+// 参数：
+//   - dockerClient: docker 客户端
+//   - reader: docker save 存档流
+//
+// 返回：
+//   - docker service 是否返回错误信息
+//   - docker service 的返回信息
+//   - 错误信息
+func loadImage(dockerClient *client.Client, reader io.Reader) (bool, []string, error) {
+	var (
+		result  bool     = false
+		message []string = make([]string, 0)
+	)
+
+	// 从 tar 存档加载 image
+	response, err := dockerClient.ImageLoad(ctx, reader, true)
 	if err != nil {
 		return result, message, err
 	}
@@ -205,29 +333,150 @@ func LoadImage(archiveFile string) (bool, []string, error) {
 // 返回：
 //   - 错误信息
 func LoadVolume(newVolumeName string, filePath string, archiveFile string) error {
+	return RestoreVolume(newVolumeName, filePath, archiveFile, RestoreModeOverwrite)
+}
+
+// 将存档恢复到已存在的 volume 时的处理方式
+const (
+	RestoreModeWipe      = "wipe"      // 清空 volume 后恢复
+	RestoreModeOverwrite = "overwrite" // 覆盖同名文件，保留存档中没有的文件
+	RestoreModeMissing   = "missing"   // 只添加 volume 中缺少的文件
+)
+
+// RestoreVolume 将存档文件恢复到 volume，volume 不存在时自动创建
+//
+// 参数：
+//   - volumeName: volume 名
+//   - filePath: 存档文件路径
+//   - archiveFile: 存档文件名
+//   - mode: 恢复方式，RestoreModeWipe、RestoreModeOverwrite 或 RestoreModeMissing
+//
+// 返回：
+//   - 错误信息
+func RestoreVolume(volumeName string, filePath string, archiveFile string, mode string) error {
 	const (
 		volumePathInContainer = "/volume" // volume 在容器中的挂载路径
 		backupPathInContainer = "/backup" // 备份文件夹在容器中的挂载路径
 	)
 	backupFileInContainer := color.Sprintf("%s/%s", backupPathInContainer, archiveFile)
 
+	// 使用 tar 解包存档中的文件
+	var cmd []string
+	switch mode {
+	case RestoreModeWipe:
+		// 先删除 volume 中的所有内容（包括隐藏文件），再解包
+		script := `rm -rf "$1"/..?* "$1"/.[!.]* "$1"/* && tar xzf "$0" -C "$1"`
+		cmd = []string{"sh", "-c", script, backupFileInContainer, volumePathInContainer}
+	case RestoreModeOverwrite:
+		cmd = []string{"tar", "xzf", backupFileInContainer, "-C", volumePathInContainer}
+	case RestoreModeMissing:
+		// 先找出 volume 中不存在的条目，只解包这些条目；列表为空时不能调用 tar，否则会解包全部
+		script := `tar tzf "$0" | while IFS= read -r name; do [ -e "$1/$name" ] || [ -L "$1/$name" ] || echo "$name"; done > /tmp/missing && if [ -s /tmp/missing ]; then tar xzf "$0" -C "$1" -T /tmp/missing; fi`
+		cmd = []string{"sh", "-c", script, backupFileInContainer, volumePathInContainer}
+	default:
+		return fmt.Errorf(UnsupportedFormatMessage, mode)
+	}
+
 	// 创建一个临时容器并挂载 volume
 	containerConfig := &container.Config{
-		// 基于 busybox 镜像创建容器
-		Image: "busybox",
-		// 使用 tar 打包 volume 中的文件
-		Cmd: []string{"tar", "xzf", backupFileInContainer, "-C", volumePathInContainer},
+		// 基于辅助镜像（默认 busybox）创建容器
+		Image: CurrentSettings.HelperImage,
+		Cmd:   cmd,
 	}
 	hostConfig := &container.HostConfig{
 		// 自动删除容器
 		AutoRemove: true,
 		// 设置挂载点
 		Binds: []string{
-			color.Sprintf("%s:%s", newVolumeName, volumePathInContainer),
+			color.Sprintf("%s:%s", volumeName, volumePathInContainer),
 			color.Sprintf("%s:%s", filePath, backupPathInContainer),
 		},
 	}
 
+	return runHelperContainer(containerConfig, hostConfig)
+}
+
+// RestoreVolumeFromArchive 将存档文件经由 docker API 恢复到 volume，volume 不存在时自动创建
+//
+//   - 不挂载本地文件夹，适用于远程 docker service
+//
+// 参数：
+//   - volumeName: volume 名
+//   - archiveFile: 本地存档文件路径
+//   - mode: 恢复方式，RestoreModeWipe、RestoreModeOverwrite 或 RestoreModeMissing
+//
+// 返回：
+//   - 错误信息
+func RestoreVolumeFromArchive(volumeName string, archiveFile string, mode string) error {
+	const volumePathInContainer = "/volume" // volume 在容器中的挂载路径
+
+	switch mode {
+	case RestoreModeWipe, RestoreModeOverwrite, RestoreModeMissing:
+	default:
+		return fmt.Errorf(UnsupportedFormatMessage, mode)
+	}
+
+	file, err := os.Open(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader, err := NewDecompressReader(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if _, err := docker.VolumeCreate(ctx, volume.CreateOptions{Name: volumeName}); err != nil {
+		return err
+	}
+	keep := func(string) bool { return true }
+	switch mode {
+	case RestoreModeWipe:
+		// 删除 volume 中的所有内容（包括隐藏文件）
+		containerConfig := &container.Config{
+			Image: CurrentSettings.HelperImage,
+			Cmd:   []string{"sh", "-c", `rm -rf "$0"/..?* "$0"/.[!.]* "$0"/*`, volumePathInContainer},
+		}
+		hostConfig := &container.HostConfig{
+			AutoRemove: true,
+			Binds:      []string{color.Sprintf("%s:%s", volumeName, volumePathInContainer)},
+		}
+		if err := runHelperContainer(containerConfig, hostConfig); err != nil {
+			return err
+		}
+	case RestoreModeMissing:
+		// 只保留 volume 中不存在的条目
+		files, err := VolumeManifest(volumeName)
+		if err != nil {
+			return err
+		}
+		keep = func(name string) bool {
+			_, exist := files[name]
+			return name != "" && !exist
+		}
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(FilterTar(reader, pipeWriter, keep))
+	}()
+	err = CopyToVolume(volumeName, pipeReader)
+	pipeReader.CloseWithError(err)
+	return err
+}
+
+// runHelperContainer 运行一个临时容器并等待其结束
+//
+//   - 容器需设置 AutoRemove，函数在容器被删除后返回，此时其挂载的 volume 已释放
+//
+// 参数：
+//   - containerConfig: 容器配置
+//   - hostConfig: 容器的主机配置
+//
+// 返回：
+//   - 错误信息，容器退出码非 0 时同样返回错误
+func runHelperContainer(containerConfig *container.Config, hostConfig *container.HostConfig) error {
 	// 创建容器，容器名称留空使其随机生成
 	resp, err := docker.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
 	if err != nil {
@@ -235,14 +484,299 @@ func LoadVolume(newVolumeName string, filePath string, archiveFile string) error
 	}
 	containerID := resp.ID
 
+	// 在启动前开始等待，避免错过容器的退出和删除
+	waitCh, errCh := docker.ContainerWait(ctx, containerID, container.WaitConditionRemoved)
+
 	// 启动容器
 	if err := docker.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
 		return err
 	}
 
+	select {
+	case result := <-waitCh:
+		if result.Error != nil && result.Error.Message != "" {
+			return errors.New(result.Error.Message)
+		}
+		if result.StatusCode != 0 {
+			return fmt.Errorf("helper container %s exited with code %d", containerID[:12], result.StatusCode)
+		}
+	case err := <-errCh:
+		return err
+	}
+
 	return nil
 }
 
+// CopyToVolume 将 tar 流解包到已存在的 volume 中
+//
+//   - 功能与命令 `docker create -v <volumeName>:/volume busybox && docker cp - <container>:/volume` 一样
+//   - 保留 tar 流中记录的所有者和权限，已存在的同名文件会被覆盖
+//
+// 参数：
+//   - volumeName: volume 名
+//   - content: tar 流，路径相对于 volume 根目录
+//
+// 返回：
+//   - 错误信息
+func CopyToVolume(volumeName string, content io.Reader) error {
+	return copyToVolume(docker, volumeName, content)
+}
+
+// copyToVolume 使用指定客户端将 tar 流解包到已存在的 volume 中
+//
+// 参数：
+//   - dockerClient: docker 客户端
+//   - volumeName: volume 名
+//   - content: tar 流，路径相对于 volume 根目录
+//
+// 返回：
+//   - 错误信息
+func copyToVolume(dockerClient *client.Client, volumeName string, content io.Reader) error {
+	const volumePathInContainer = "/volume" // volume 在容器中的挂载路径
+
+	// 创建一个不启动的临时容器并挂载 volume
+	containerConfig := &container.Config{
+		Image: CurrentSettings.HelperImage,
+		Cmd:   []string{"true"},
+	}
+	hostConfig := &container.HostConfig{
+		Binds: []string{color.Sprintf("%s:%s", volumeName, volumePathInContainer)},
+	}
+	resp, err := dockerClient.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer dockerClient.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+
+	return dockerClient.CopyToContainer(ctx, resp.ID, volumePathInContainer, content, container.CopyToContainerOptions{CopyUIDGID: true})
+}
+
+// CopyFromVolume 将 volume 的全部内容读取为 tar 流
+//
+//   - 功能与命令 `docker create -v <volumeName>:/volume busybox && docker cp <container>:/volume -` 一样
+//   - 数据经由 docker API 传输，同样适用于远程 docker service
+//
+// 参数：
+//   - volumeName: volume 名
+//
+// 返回：
+//   - tar 流，路径相对于 volume 根目录，关闭时删除临时容器
+//   - 错误信息
+func CopyFromVolume(volumeName string) (io.ReadCloser, error) {
+	return copyFromVolume(docker, volumeName)
+}
+
+// copyFromVolume 使用指定客户端将 volume 的全部内容读取为 tar 流
+//
+// 参数：
+//   - dockerClient: docker 客户端
+//   - volumeName: volume 名
+//
+// 返回：
+//   - tar 流，路径相对于 volume 根目录，关闭时删除临时容器
+//   - 错误信息
+func copyFromVolume(dockerClient *client.Client, volumeName string) (io.ReadCloser, error) {
+	const volumePathInContainer = "/volume" // volume 在容器中的挂载路径
+
+	// 创建一个不启动的临时容器并挂载 volume
+	containerConfig := &container.Config{
+		Image: CurrentSettings.HelperImage,
+		Cmd:   []string{"true"},
+	}
+	hostConfig := &container.HostConfig{
+		Binds: []string{color.Sprintf("%s:%s:ro", volumeName, volumePathInContainer)},
+	}
+	resp, err := dockerClient.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	removeContainer := func() {
+		dockerClient.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+	}
+
+	content, _, err := dockerClient.CopyFromContainer(ctx, resp.ID, volumePathInContainer)
+	if err != nil {
+		removeContainer()
+		return nil, err
+	}
+
+	// docker cp 输出的条目以 'volume/' 开头，改写为相对路径
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(RebaseTar(content, pipeWriter, path.Base(volumePathInContainer)))
+	}()
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{pipeReader, closerFunc(func() error {
+		pipeReader.Close()
+		err := content.Close()
+		removeContainer()
+		return err
+	})}, nil
+}
+
+// runHelperContainerOutput 运行一个临时容器，等待其结束后返回标准输出并删除容器
+//
+// 参数：
+//   - dockerClient: docker 客户端
+//   - containerConfig: 容器配置
+//   - hostConfig: 容器的主机配置，不能设置 AutoRemove
+//
+// 返回：
+//   - 容器的标准输出
+//   - 错误信息，容器退出码非 0 时同样返回错误，包含标准错误输出
+func runHelperContainerOutput(dockerClient *client.Client, containerConfig *container.Config, hostConfig *container.HostConfig) ([]byte, error) {
+	// 创建容器，容器名称留空使其随机生成
+	resp, err := dockerClient.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	containerID := resp.ID
+	defer dockerClient.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true})
+
+	// 在启动前开始等待，避免错过容器的退出
+	waitCh, errCh := dockerClient.ContainerWait(ctx, containerID, container.WaitConditionNextExit)
+
+	// 启动容器
+	if err := dockerClient.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+		return nil, err
+	}
+
+	var statusCode int64
+	select {
+	case result := <-waitCh:
+		if result.Error != nil && result.Error.Message != "" {
+			return nil, errors.New(result.Error.Message)
+		}
+		statusCode = result.StatusCode
+	case err := <-errCh:
+		return nil, err
+	}
+
+	// 读取输出，docker 日志中的标准输出和标准错误输出是多路复用的
+	logs, err := dockerClient.ContainerLogs(ctx, containerID, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return nil, err
+	}
+	defer logs.Close()
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, logs); err != nil {
+		return nil, err
+	}
+
+	if statusCode != 0 {
+		return nil, fmt.Errorf("helper container %s exited with code %d: %s", containerID[:12], statusCode, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// InspectImage 获取 image 的详细信息
+//
+//   - 功能与命令 `docker image inspect <imageName>` 一样
+//
+// 参数：
+//   - imageName: image 的引用或 ID
+//
+// 返回：
+//   - image 信息
+//   - 错误信息
+func InspectImage(imageName string) (types.ImageInspect, error) {
+	info, _, err := docker.ImageInspectWithRaw(ctx, imageName)
+	return info, err
+}
+
+// ImageHistory 获取 image 的构建历史
+//
+//   - 功能与命令 `docker history <imageName>` 一样
+//
+// 参数：
+//   - imageName: image 的引用或 ID
+//
+// 返回：
+//   - 构建历史，从顶层到底层
+//   - 错误信息
+func ImageHistory(imageName string) ([]image.HistoryResponseItem, error) {
+	return docker.ImageHistory(ctx, imageName)
+}
+
+// ListDanglingImages 列出所有悬空 image
+//
+//   - 功能与命令 `docker images --filter dangling=true` 一样
+//
+// 返回：
+//   - image 列表
+//   - 错误信息
+func ListDanglingImages() ([]image.Summary, error) {
+	return docker.ImageList(ctx, image.ListOptions{Filters: filters.NewArgs(filters.Arg("dangling", "true"))})
+}
+
+// ListContainers 列出所有容器，包括已停止的
+//
+//   - 功能与命令 `docker ps --all` 一样
+//
+// 返回：
+//   - 容器列表
+//   - 错误信息
+func ListContainers() ([]types.Container, error) {
+	return docker.ContainerList(ctx, container.ListOptions{All: true})
+}
+
+// VolumeUsers 统计每个 volume 被哪些容器使用
+//
+// 返回：
+//   - volume 名到使用它的容器名的映射
+//   - 错误信息
+func VolumeUsers() (map[string][]string, error) {
+	containers, err := ListContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string][]string)
+	for _, container := range containers {
+		name := container.ID[:12]
+		if len(container.Names) != 0 {
+			name = strings.TrimPrefix(container.Names[0], "/")
+		}
+		for _, mountPoint := range container.Mounts {
+			if mountPoint.Type == mount.TypeVolume {
+				users[mountPoint.Name] = append(users[mountPoint.Name], name)
+			}
+		}
+	}
+
+	return users, nil
+}
+
+// RemoveImage 删除 image
+//
+//   - 功能与命令 `docker rmi <imageName>` 一样，imageName 为 Repository:Tag 时仅删除该 Tag
+//
+// 参数：
+//   - imageName: image 的 Repository(:Tag) 或 ID
+//
+// 返回：
+//   - 错误信息
+func RemoveImage(imageName string) error {
+	_, err := docker.ImageRemove(ctx, imageName, image.RemoveOptions{PruneChildren: true})
+	return err
+}
+
+// RemoveVolume 删除 volume
+//
+//   - 功能与命令 `docker volume rm <volumeName>` 一样
+//
+// 参数：
+//   - volumeName: volume 名
+//
+// 返回：
+//   - 错误信息
+func RemoveVolume(volumeName string) error {
+	return docker.VolumeRemove(ctx, volumeName, false)
+}
+
 // dockerClient 创建 docker 客户端
 //
 // 返回：
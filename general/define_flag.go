@@ -14,6 +14,12 @@ var (
 )
 
 var (
-	PackFlag = "📦"  // 信息符号 - 打包完成
-	LoadFlag = "🗃️" // 信息符号 - 加载完成
+	PackFlag    = "📦"  // 信息符号 - 打包完成
+	LoadFlag    = "🗃️" // 信息符号 - 加载完成
+	RemoveFlag  = "🗑️" // 信息符号 - 删除完成
+	PushFlag    = "🚀"  // 信息符号 - 推送完成
+	InspectFlag = "🔍"  // 信息符号 - 解析完成
+	CloneFlag   = "📋"  // 信息符号 - 复制完成
+	SendFlag    = "🚚"  // 信息符号 - 传输完成
+	ClockFlag   = "⏰"  // 信息符号 - 定时任务
 )
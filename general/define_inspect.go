@@ -0,0 +1,366 @@
+/*
+File: define_inspect.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 13:15:02
+
+Description: 离线解析 image 和 volume 存档
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	maxMetadataSize  = 4 << 20  // 解析存档时缓存内容的条目的最大大小，用于读取 JSON 元数据
+	maxMetadataTotal = 64 << 20 // 解析存档时缓存内容的总大小上限
+)
+
+// 存档类型
+const (
+	ArchiveKindImage  = "image"  // docker save 存档
+	ArchiveKindVolume = "volume" // volume 存档
+)
+
+// ArchiveInfo 存档信息
+type ArchiveInfo struct {
+	Kind   string             // 存档类型
+	Images []ImageArchiveInfo // Kind 为 image 时有效
+	Volume VolumeArchiveInfo  // Kind 为 volume 时有效
+}
+
+// ImageArchiveInfo docker save 存档中单个 image 的信息
+type ImageArchiveInfo struct {
+	RepoTags     []string   // Repository:Tag
+	ID           string     // image ID，即配置文件的摘要
+	Architecture string     // 架构
+	OS           string     // 操作系统
+	Variant      string     // 架构变体
+	Created      *time.Time // 创建时间
+	Entrypoint   []string   // 入口点
+	Cmd          []string   // 默认命令
+	Env          []string   // 环境变量
+	Labels       map[string]string
+	ExposedPorts []string // 暴露的端口
+	Layers       []ArchiveLayer
+}
+
+// ArchiveLayer 存档中的 image 层
+type ArchiveLayer struct {
+	Path      string // 在存档中的路径
+	DiffID    string // 未压缩内容的摘要
+	Size      int64  // 在存档中的大小
+	CreatedBy string // 创建该层的命令
+}
+
+// VolumeArchiveInfo volume 存档信息
+type VolumeArchiveInfo struct {
+	Files          int             // 普通文件数量
+	Directories    int             // 文件夹数量
+	Symlinks       int             // 符号链接数量
+	TotalSize      int64           // 普通文件总大小
+	TopDirectories []DirectorySize // 顶层文件夹，按大小从大到小排序
+}
+
+// DirectorySize 文件夹大小
+type DirectorySize struct {
+	Name  string // 文件夹名，根目录下的文件归入 '.'
+	Files int    // 普通文件数量
+	Size  int64  // 普通文件总大小
+}
+
+// NewDecompressReader 根据内容自动解压
+//
+//   - 支持 gzip 压缩和未压缩的数据
+//
+// 参数：
+//   - reader: 原始数据
+//
+// 返回：
+//   - 解压后的数据
+//   - 错误信息
+func NewDecompressReader(reader io.Reader) (io.ReadCloser, error) {
+	bufferedReader := bufio.NewReader(reader)
+	magic, err := bufferedReader.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return gzip.NewReader(bufferedReader)
+	}
+	return io.NopCloser(bufferedReader), nil
+}
+
+// OpenArchive 打开存档文件并自动解压
+//
+// 参数：
+//   - archiveFile: 存档文件或远程存档的 URL
+//
+// 返回：
+//   - 解压后的 tar 流，关闭时同时关闭文件
+//   - 错误信息
+func OpenArchive(archiveFile string) (io.ReadCloser, error) {
+	file, err := OpenLocation(archiveFile)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := NewDecompressReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, closerFunc(func() error {
+		reader.Close()
+		return file.Close()
+	})}, nil
+}
+
+// closerFunc 将函数转换为 io.Closer
+type closerFunc func() error
+
+func (function closerFunc) Close() error {
+	return function()
+}
+
+// CleanArchivePath 规范化存档中的路径，去除开头的 './' 和 '/'，根目录返回空
+//
+// 参数：
+//   - name: 存档中的路径
+//
+// 返回：
+//   - 规范化后的路径
+func CleanArchivePath(name string) string {
+	cleaned := path.Clean("/" + name)
+	return strings.TrimPrefix(cleaned, "/")
+}
+
+// isMetadataPath 判断存档中的路径是否可能是 docker save 存档的元数据
+//
+//   - 元数据位于存档根目录（manifest.json、repositories、旧格式的 '<ID>.json'）或 blobs 文件夹中
+func isMetadataPath(name string) bool {
+	if strings.HasPrefix(name, ocispec.ImageBlobsDir+"/") {
+		return true
+	}
+	return !strings.Contains(name, "/") && (name == "repositories" || strings.HasSuffix(name, ".json"))
+}
+
+// readMetadata 读取可能是 JSON 元数据的条目
+//
+//   - 只读取以 '{' 或 '[' 开头的内容，layer 等二进制内容不会被读入内存
+//
+// 参数：
+//   - reader: 条目内容
+//
+// 返回：
+//   - 条目内容，不是 JSON 时为 nil
+//   - 错误信息
+func readMetadata(reader io.Reader) ([]byte, error) {
+	bufferedReader := bufio.NewReader(reader)
+	first, err := bufferedReader.Peek(1)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if first[0] != '{' && first[0] != '[' {
+		return nil, nil
+	}
+	return io.ReadAll(bufferedReader)
+}
+
+// imageManifests 根据存档中的条目判断是否为 docker save 存档
+//
+//   - manifest.json 可以解析，且其引用的配置文件和所有层都在存档中时才视为 docker save 存档
+//
+// 参数：
+//   - sizes: 存档中的条目及其大小
+//   - contents: 已缓存内容的条目
+//
+// 返回：
+//   - manifest.json 的内容
+//   - 是否为 docker save 存档
+func imageManifests(sizes map[string]int64, contents map[string][]byte) ([]DockerArchiveManifest, bool) {
+	manifestData, ok := contents["manifest.json"]
+	if !ok {
+		return nil, false
+	}
+	var manifests []DockerArchiveManifest
+	if err := json.Unmarshal(manifestData, &manifests); err != nil || len(manifests) == 0 {
+		return nil, false
+	}
+	for _, entry := range manifests {
+		if _, ok := contents[CleanArchivePath(entry.Config)]; entry.Config == "" || !ok {
+			return nil, false
+		}
+		for _, layer := range entry.Layers {
+			if _, ok := sizes[CleanArchivePath(layer)]; !ok {
+				return nil, false
+			}
+		}
+	}
+	return manifests, true
+}
+
+// InspectArchive 离线解析存档文件
+//
+//   - manifest.json 及其引用的配置文件和层都在存档中的视为 docker save 存档，否则视为 volume 存档
+//   - 只缓存可能是 JSON 元数据的小条目，总大小受 maxMetadataTotal 限制
+//
+// 参数：
+//   - archiveFile: 存档文件
+//
+// 返回：
+//   - 存档信息
+//   - 错误信息
+func InspectArchive(archiveFile string) (ArchiveInfo, error) {
+	var info ArchiveInfo
+
+	reader, err := OpenArchive(archiveFile)
+	if err != nil {
+		return info, err
+	}
+	defer reader.Close()
+
+	var (
+		sizes       = make(map[string]int64)  // 条目大小
+		contents    = make(map[string][]byte) // 小条目的内容
+		cached      int64                     // 已缓存内容的总大小
+		directories = make(map[string]*DirectorySize)
+	)
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return info, err
+		}
+
+		name := CleanArchivePath(header.Name)
+		sizes[name] = header.Size
+		if header.Typeflag == tar.TypeReg && header.Size <= maxMetadataSize && cached+header.Size <= maxMetadataTotal && isMetadataPath(name) {
+			data, err := readMetadata(tarReader)
+			if err != nil {
+				return info, err
+			}
+			if data != nil {
+				contents[name] = data
+				cached += int64(len(data))
+			}
+		}
+
+		// volume 统计
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if name != "" {
+				info.Volume.Directories++
+			}
+		case tar.TypeSymlink:
+			info.Volume.Symlinks++
+		case tar.TypeReg:
+			info.Volume.Files++
+			info.Volume.TotalSize += header.Size
+			top := "."
+			if first, _, found := strings.Cut(name, "/"); found {
+				top = first
+			}
+			if directories[top] == nil {
+				directories[top] = &DirectorySize{Name: top}
+			}
+			directories[top].Files++
+			directories[top].Size += header.Size
+		}
+	}
+
+	manifests, isImage := imageManifests(sizes, contents)
+	if !isImage {
+		info.Kind = ArchiveKindVolume
+		for _, directory := range directories {
+			info.Volume.TopDirectories = append(info.Volume.TopDirectories, *directory)
+		}
+		sort.SliceStable(info.Volume.TopDirectories, func(i, j int) bool {
+			return info.Volume.TopDirectories[i].Size > info.Volume.TopDirectories[j].Size
+		})
+		return info, nil
+	}
+
+	info.Kind = ArchiveKindImage
+
+	// 旧格式的 repositories 文件：{"repo": {"tag": "layerID"}}，仅在存档只有一个 image 时用于补充 Tag
+	var legacyTags []string
+	if repositoriesData, ok := contents["repositories"]; ok && len(manifests) == 1 {
+		var repositories map[string]map[string]string
+		if err := json.Unmarshal(repositoriesData, &repositories); err == nil {
+			for repo, tags := range repositories {
+				for tag := range tags {
+					legacyTags = append(legacyTags, repo+":"+tag)
+				}
+			}
+			sort.Strings(legacyTags)
+		}
+	}
+
+	for _, entry := range manifests {
+		configData := contents[CleanArchivePath(entry.Config)]
+		var config ocispec.Image
+		if err := json.Unmarshal(configData, &config); err != nil {
+			return info, err
+		}
+
+		imageInfo := ImageArchiveInfo{
+			RepoTags:     entry.RepoTags,
+			ID:           digest.FromBytes(configData).String(),
+			Architecture: config.Architecture,
+			OS:           config.OS,
+			Variant:      config.Variant,
+			Created:      config.Created,
+			Entrypoint:   config.Config.Entrypoint,
+			Cmd:          config.Config.Cmd,
+			Env:          config.Config.Env,
+			Labels:       config.Config.Labels,
+		}
+		if len(imageInfo.RepoTags) == 0 {
+			imageInfo.RepoTags = legacyTags
+		}
+		for port := range config.Config.ExposedPorts {
+			imageInfo.ExposedPorts = append(imageInfo.ExposedPorts, port)
+		}
+		sort.Strings(imageInfo.ExposedPorts)
+
+		createdBy := LayerCreatedBy(config)
+		for index, layer := range entry.Layers {
+			archiveLayer := ArchiveLayer{Path: layer, Size: sizes[CleanArchivePath(layer)]}
+			if index < len(config.RootFS.DiffIDs) {
+				archiveLayer.DiffID = config.RootFS.DiffIDs[index].String()
+			}
+			if index < len(createdBy) {
+				archiveLayer.CreatedBy = createdBy[index]
+			}
+			imageInfo.Layers = append(imageInfo.Layers, archiveLayer)
+		}
+
+		info.Images = append(info.Images, imageInfo)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,101 @@
+/*
+File: define_inspect_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 11:26:48
+
+Description: 离线解析存档测试
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestArchive 将条目写入临时存档文件，返回文件路径
+func writeTestArchive(t *testing.T, entries []tarEntry) string {
+	t.Helper()
+	archiveFile := filepath.Join(t.TempDir(), "archive.tar")
+	file, err := os.Create(archiveFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, buildTar(t, entries)); err != nil {
+		t.Fatal(err)
+	}
+	return archiveFile
+}
+
+func TestInspectArchiveKind(t *testing.T) {
+	const (
+		config   = `{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":["sha256:1111111111111111111111111111111111111111111111111111111111111111"]}}`
+		manifest = `[{"Config":"blobs/sha256/aaaa","RepoTags":["app:1.0"],"Layers":["blobs/sha256/bbbb"]}]`
+	)
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		want    string
+	}{
+		{"docker save archive", []tarEntry{
+			{name: "blobs/", typeflag: tar.TypeDir},
+			{name: "blobs/sha256/aaaa", typeflag: tar.TypeReg, content: config},
+			{name: "blobs/sha256/bbbb", typeflag: tar.TypeReg, content: strings.Repeat("\x00", 1024)},
+			{name: "manifest.json", typeflag: tar.TypeReg, content: manifest},
+		}, ArchiveKindImage},
+		{"volume holding a manifest.json", []tarEntry{
+			{name: "./", typeflag: tar.TypeDir},
+			{name: "./manifest.json", typeflag: tar.TypeReg, content: `{"name":"web","version":"1.0"}`},
+			{name: "./index.html", typeflag: tar.TypeReg, content: "<html></html>"},
+		}, ArchiveKindVolume},
+		{"volume holding a docker manifest without its layers", []tarEntry{
+			{name: "./", typeflag: tar.TypeDir},
+			{name: "./manifest.json", typeflag: tar.TypeReg, content: manifest},
+			{name: "./blobs/sha256/aaaa", typeflag: tar.TypeReg, content: config},
+		}, ArchiveKindVolume},
+		{"nested manifest.json", []tarEntry{
+			{name: "app/manifest.json", typeflag: tar.TypeReg, content: manifest},
+		}, ArchiveKindVolume},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, err := InspectArchive(writeTestArchive(t, test.entries))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Kind != test.want {
+				t.Fatalf("InspectArchive kind = %s, want %s", info.Kind, test.want)
+			}
+			if info.Kind == ArchiveKindImage {
+				if len(info.Images) != 1 || info.Images[0].Architecture != "amd64" || len(info.Images[0].Layers) != 1 || info.Images[0].Layers[0].Size != 1024 {
+					t.Errorf("InspectArchive images = %+v", info.Images)
+				}
+			}
+		})
+	}
+}
+
+func TestReadMetadata(t *testing.T) {
+	tests := map[string]bool{
+		`{"a":1}`:            true,
+		`[{"Config":"x"}]`:   true,
+		"\x1f\x8b\x08\x00":   false,
+		"layer.tar contents": false,
+		"":                   false,
+	}
+	for content, wantRead := range tests {
+		data, err := readMetadata(strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (data != nil) != wantRead || (wantRead && string(data) != content) {
+			t.Errorf("readMetadata(%q) = %q, want read %v", content, data, wantRead)
+		}
+	}
+}
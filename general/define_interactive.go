@@ -0,0 +1,64 @@
+/*
+File: define_interactive.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 10:05:18
+
+Description: 与用户交互
+*/
+
+package general
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+// AskUser 询问用户并获取回答
+//
+// 参数：
+//   - question: 问题
+//   - expectedAnswers: 允许的回答，第一个为默认回答（直接回车时使用）
+//
+// 返回：
+//   - 用户的回答（小写）
+//   - 错误信息
+func AskUser(question string, expectedAnswers []string) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		color.Printf("%s (%s): ", question, strings.Join(expectedAnswers, "/"))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+
+		answer := strings.ToLower(strings.TrimSpace(input))
+		if answer == "" && len(expectedAnswers) != 0 {
+			return strings.ToLower(expectedAnswers[0]), nil
+		}
+		for _, expected := range expectedAnswers {
+			if answer == strings.ToLower(expected) {
+				return answer, nil
+			}
+		}
+	}
+}
+
+// Confirm 请求用户确认，默认回答为否
+//
+// 参数：
+//   - question: 问题
+//
+// 返回：
+//   - 用户是否确认
+//   - 错误信息
+func Confirm(question string) (bool, error) {
+	answer, err := AskUser(question, []string{"n", "y"})
+	if err != nil {
+		return false, err
+	}
+	return answer == "y", nil
+}
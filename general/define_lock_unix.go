@@ -0,0 +1,43 @@
+//go:build !windows
+
+/*
+File: define_lock_unix.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 13:20:34
+
+Description: 文件锁（Unix）
+*/
+
+package general
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile 对已打开的文件加排它锁，锁随文件描述符关闭而释放
+//
+// 参数：
+//   - file: 已打开的文件
+//   - wait: 是否等待其他持有者释放锁
+//
+// 返回：
+//   - 错误信息，不等待且锁被占用时返回 errLockBusy
+func lockFile(file *os.File, wait bool) error {
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	for {
+		err := syscall.Flock(int(file.Fd()), how)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return errLockBusy
+		}
+		return err
+	}
+}
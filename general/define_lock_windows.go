@@ -0,0 +1,40 @@
+//go:build windows
+
+/*
+File: define_lock_windows.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 13:20:34
+
+Description: 文件锁（Windows）
+*/
+
+package general
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile 对已打开的文件加排它锁，锁随文件句柄关闭而释放
+//
+// 参数：
+//   - file: 已打开的文件
+//   - wait: 是否等待其他持有者释放锁
+//
+// 返回：
+//   - 错误信息，不等待且锁被占用时返回 errLockBusy
+func lockFile(file *os.File, wait bool) error {
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK)
+	if !wait {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+	err := windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLockBusy
+	}
+	return err
+}
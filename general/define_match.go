@@ -0,0 +1,171 @@
+/*
+File: define_match.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 09:12:36
+
+Description: 名称匹配（精确、通配符、正则表达式）
+*/
+
+package general
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MatchOption 名称匹配选项
+type MatchOption struct {
+	Regex   bool     // 是否将模式视为正则表达式，否则视为 shell 通配符
+	Exclude []string // 排除模式，匹配任意一个即被排除
+}
+
+// IsGlobPattern 判断字符串是否包含 shell 通配符
+//
+// 参数：
+//   - pattern: 待判断的字符串
+//
+// 返回：
+//   - 包含通配符返回 true，否则返回 false
+func IsGlobPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// MatchName 判断目标字符串是否匹配指定模式
+//
+//   - 正则表达式模式下使用 Go 正则语法，未锚定时匹配子串
+//   - 通配符模式下使用 shell 通配符语法，需完整匹配，其中 '*' 也匹配 '/'
+//
+// 参数：
+//   - pattern: 匹配模式
+//   - target: 目标字符串
+//   - regex: 是否将 pattern 视为正则表达式
+//
+// 返回：
+//   - 是否匹配
+//   - 错误信息
+func MatchName(pattern, target string, regex bool) (bool, error) {
+	if !regex {
+		pattern = globToRegexp(pattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(target), nil
+}
+
+// globToRegexp 将 shell 通配符转换为锚定的正则表达式
+//
+// 参数：
+//   - glob: shell 通配符
+//
+// 返回：
+//   - 正则表达式
+func globToRegexp(glob string) string {
+	var builder strings.Builder
+	builder.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch char := glob[i]; char {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 { // 没有闭合的 '[' 按字面处理
+				builder.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				builder.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
+
+// MatchAny 判断目标字符串是否匹配任意一个模式
+//
+// 参数：
+//   - patterns: 匹配模式切片
+//   - target: 目标字符串
+//   - regex: 是否将 patterns 视为正则表达式
+//
+// 返回：
+//   - 是否匹配
+//   - 错误信息
+func MatchAny(patterns []string, target string, regex bool) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := MatchName(pattern, target, regex)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SplitRepoTag 将 image 引用拆分为 Repository 和 Tag
+//
+//   - 以最后一个 ':' 拆分，且 ':' 之后不能包含 '/'，以兼容带端口的 registry 地址（例如 'localhost:5000/app:1.0'）
+//
+// 参数：
+//   - reference: image 引用，例如 'repo:tag'
+//
+// 返回：
+//   - Repository
+//   - Tag，不存在时为空
+func SplitRepoTag(reference string) (string, string) {
+	index := strings.LastIndex(reference, ":")
+	if index == -1 || strings.Contains(reference[index+1:], "/") {
+		return reference, ""
+	}
+	return reference[:index], reference[index+1:]
+}
+
+// PathFilter 返回按通配符筛选存档路径的函数
+//
+//   - 路径本身或其任一上级文件夹匹配 include 时保留，include 为空时全部保留
+//   - 路径本身或其任一上级文件夹匹配 exclude 时排除，优先于 include
+//   - 根目录始终保留
+//
+// 参数：
+//   - include: 包含的通配符
+//   - exclude: 排除的通配符
+//
+// 返回：
+//   - 判断函数，参数为规范化后的路径
+func PathFilter(include []string, exclude []string) func(name string) bool {
+	return func(name string) bool {
+		if name == "" {
+			return true
+		}
+
+		// 依次检查路径本身及其上级文件夹
+		included := len(include) == 0
+		for current := name; current != "."; current = path.Dir(current) {
+			if excluded, _ := MatchAny(exclude, current, false); excluded {
+				return false
+			}
+			if !included {
+				included, _ = MatchAny(include, current, false)
+			}
+		}
+		return included
+	}
+}
@@ -10,10 +10,37 @@ Description: 定义输出信息及其格式
 package general
 
 var (
-	ReferenceNotExistMessage = "Reference does not exist"      // 输出文本 - 引用不存在
-	NoSuchImageMessage       = "No such image"                 // 输出文本 - 无此镜像
-	NoSuchVolumeMessage      = "No such volume"                // 输出文本 - 无此存储卷
-	NotVolumeArchiveMessage  = "Not a volume archive file"     // 输出文本 - 不是存储卷存档
-	VolumeExistMessage       = "Volume already exists"         // 输出文本 - 存储卷已存在
-	SpecifyMessage           = "Please specify the %s to %s\n" // 输出文本 - 请求指示
+	ReferenceNotExistMessage = "Reference does not exist"                                             // 输出文本 - 引用不存在
+	NoSuchImageMessage       = "No such image"                                                        // 输出文本 - 无此镜像
+	AmbiguousIDMessage       = "Ambiguous ID prefix matches %d images"                                // 输出文本 - ID 前缀有歧义
+	NoSuchVolumeMessage      = "No such volume"                                                       // 输出文本 - 无此存储卷
+	NotVolumeArchiveMessage  = "Not a volume archive file"                                            // 输出文本 - 不是存储卷存档
+	VolumeExistMessage       = "Volume already exists"                                                // 输出文本 - 存储卷已存在
+	UntaggedImageMessage     = "Image has no repository and tag"                                      // 输出文本 - 镜像没有标签
+	RemovedMessage           = "Removed"                                                              // 输出文本 - 已删除
+	NothingToPruneMessage    = "No %s to prune"                                                       // 输出文本 - 无可清理对象
+	DryRunMessage            = "Dry run: %d %s would be removed"                                      // 输出文本 - 预览模式
+	UnsupportedFormatMessage = "Unsupported format: %s"                                               // 输出文本 - 不支持的格式
+	NoSuchPathMessage        = "No matching path in archive"                                          // 输出文本 - 存档中无匹配路径
+	VerifyFailedMessage      = "Verification failed, contents differ"                                 // 输出文本 - 校验失败
+	VolumeInUseMessage       = "Volume is in use by %s"                                               // 输出文本 - 存储卷正在使用
+	IdenticalMessage         = "No differences in %s"                                                 // 输出文本 - 无差异
+	FlaggedLayersMessage     = "%d layers are large or may waste space, see the Hints column"         // 输出文本 - 层提示
+	NoSuchPlatformMessage    = "Platform %s not found, available: %s"                                 // 输出文本 - 无此平台
+	NoSuchContextMessage     = "No such docker context: %s"                                           // 输出文本 - 无此 context
+	NoSuchProfileMessage     = "No such profile: %s"                                                  // 输出文本 - 无此 profile
+	ConfigExistMessage       = "Config file already exists, use '--force' to overwrite"               // 输出文本 - 配置文件已存在
+	ConfigValidMessage       = "Config file is valid"                                                 // 输出文本 - 配置文件有效
+	NameCollisionMessage     = "All would be saved to %s, nothing saved, adjust the name template"    // 输出文本 - 存档文件名重复
+	NoSuchJobMessage         = "No such job: %s"                                                      // 输出文本 - 无此定时任务
+	NoJobsMessage            = "No jobs in %s"                                                        // 输出文本 - 无定时任务
+	NoCatalogRecordMessage   = "No matching records in the catalog"                                   // 输出文本 - 目录中无记录
+	NoMissingArchiveMessage  = "All cataloged archives exist"                                         // 输出文本 - 存档均存在
+	LocalOutputOnlyMessage   = "Format %s can only be saved to a local output directory"              // 输出文本 - 只能保存到本地
+	SplitUnsupportedMessage  = "Format %s can not be split into parts"                                // 输出文本 - 不支持分卷
+	JobIncompleteMessage     = "Not all saved, backup set left incomplete and retention skipped"      // 输出文本 - 定时任务未全部完成
+	LayoutPathExistsMessage  = "Path exists and is not an OCI image layout, refusing to overwrite it" // 输出文本 - 保存路径已被占用
+	ChunksReusedMessage      = "Reused %d of %d chunks from an interrupted save"                      // 输出文本 - 复用已上传的分块
+	IncompleteChunksMessage  = "Chunked archive is incomplete, save it again to resume"               // 输出文本 - 分块存档不完整
+	SpecifyMessage           = "Please specify the %s to %s\n"                                        // 输出文本 - 请求指示
 )
@@ -0,0 +1,220 @@
+/*
+File: define_naming.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 19:12:27
+
+Description: 根据模板生成存档文件名
+*/
+
+package general
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// invalidFileNameChars 在常见文件系统中不能用于文件名的字符
+const invalidFileNameChars = `/\:*?"<>|`
+
+// ImageNameData image 存档文件名模板可用的字段，均已转义
+type ImageNameData struct {
+	Repo     string // Repository，'/' 等字符替换为 '-'
+	Tag      string // Tag
+	ID       string // 短 ID
+	Platform string // 保存的平台，例如 'linux-arm64'，未指定时为空
+	Date     string // 日期，格式为 20060102
+	Time     string // 时间，格式为 150405
+}
+
+// VolumeNameData volume 存档文件名模板可用的字段，均已转义
+type VolumeNameData struct {
+	Name     string // volume 名
+	Identity string // 配置项 volume_identity 的值
+	Date     string // 日期，格式为 20060102
+	Time     string // 时间，格式为 150405
+}
+
+// SanitizeFileName 转义文件名中不安全的字符
+//
+//   - 路径分隔符、Windows 保留字符和控制字符替换为 '-'
+//   - '.' 和 '..' 替换为同样长度的 '-'，避免跳出输出文件夹
+//
+// 参数：
+//   - name: 原文件名
+//
+// 返回：
+//   - 转义后的文件名
+func SanitizeFileName(name string) string {
+	var builder strings.Builder
+	for _, char := range name {
+		if char < 0x20 || char == 0x7f || strings.ContainsRune(invalidFileNameChars, char) {
+			builder.WriteRune('-')
+			continue
+		}
+		builder.WriteRune(char)
+	}
+	sanitized := strings.TrimSpace(builder.String())
+	if sanitized == "." || sanitized == ".." {
+		sanitized = strings.Repeat("-", len(sanitized))
+	}
+	return sanitized
+}
+
+// NameField 返回命名模板字段的值，为空时返回占位符
+//
+// 参数：
+//   - value: 字段的值
+//   - placeholder: 占位符
+//
+// 返回：
+//   - 字段的值或占位符
+func NameField(value string, placeholder string) string {
+	if value == "" {
+		return placeholder
+	}
+	return value
+}
+
+// RenderName 使用 Go 模板生成存档文件名
+//
+//   - 模板中的 '/' 用于创建子文件夹，生成的路径必须位于输出文件夹中
+//
+// 参数：
+//   - nameTemplate: Go 模板，例如 '{{.Repo}}/{{.Tag}}-{{.Date}}'
+//   - data: 模板字段
+//
+// 返回：
+//   - 相对于输出文件夹的文件路径
+//   - 错误信息
+func RenderName(nameTemplate string, data any) (string, error) {
+	parsed, err := template.New("name").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	if err := parsed.Execute(&buffer, data); err != nil {
+		return "", err
+	}
+
+	name := filepath.Clean(filepath.FromSlash(buffer.String()))
+	if name == "." || name == ".." || filepath.IsAbs(name) || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("name template %q must produce a relative file name, got %q", nameTemplate, buffer.String())
+	}
+	return name, nil
+}
+
+// volume 存档文件名模板中字段的占位标记，用于从存档路径反向解析 volume 名
+const (
+	volumeNameMarker = "\x00name\x00"
+	volumeDateMarker = "\x00date\x00"
+	volumeTimeMarker = "\x00time\x00"
+)
+
+// CheckVolumeTemplate 检查 volume 存档文件名模板
+//
+//   - 模板必须包含 {{.Name}}，加载存档时从路径中解析 volume 名
+//
+// 参数：
+//   - nameTemplate: Go 模板
+//   - identity: 配置项 volume_identity 的值
+//
+// 返回：
+//   - 错误信息
+func CheckVolumeTemplate(nameTemplate string, identity string) error {
+	sample := VolumeNameData{Name: volumeNameMarker, Identity: identity, Date: volumeDateMarker, Time: volumeTimeMarker}
+	name, err := RenderName(nameTemplate, sample)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(name, volumeNameMarker) {
+		return fmt.Errorf("name template %q must contain {{.Name}}", nameTemplate)
+	}
+	return nil
+}
+
+// VolumeNameFromPath 按 volume 存档文件名模板从存档路径中解析 volume 名
+//
+//   - 模板中的 '/' 对应存档路径中最后几级文件夹，{{.Name}} 可以位于文件夹名中
+//
+// 参数：
+//   - nameTemplate: 保存存档时使用的 Go 模板
+//   - identity: 配置项 volume_identity 的值
+//   - extension: 存档扩展名，模板生成的文件名不以其结尾时会被追加
+//   - filePath: 存档路径或 URL
+//
+// 返回：
+//   - volume 名
+//   - 路径是否符合模板
+func VolumeNameFromPath(nameTemplate string, identity string, extension string, filePath string) (string, bool) {
+	sample := VolumeNameData{Name: volumeNameMarker, Identity: SanitizeFileName(identity), Date: volumeDateMarker, Time: volumeTimeMarker}
+	rendered, err := RenderName(nameTemplate, sample)
+	if err != nil {
+		return "", false
+	}
+	rendered = filepath.ToSlash(rendered)
+	if !strings.HasSuffix(rendered, extension) {
+		rendered += extension
+	}
+
+	// 第一个 {{.Name}} 作为捕获组，其余的只需匹配
+	pattern := regexp.QuoteMeta(rendered)
+	pattern = strings.Replace(pattern, volumeNameMarker, `([^/]+?)`, 1)
+	pattern = strings.ReplaceAll(pattern, volumeNameMarker, `[^/]+?`)
+	pattern = strings.ReplaceAll(pattern, volumeDateMarker, `\d{8}`)
+	pattern = strings.ReplaceAll(pattern, volumeTimeMarker, `\d{6}`)
+	matcher, err := regexp.Compile("^" + pattern + "$")
+	if err != nil || matcher.NumSubexp() != 1 {
+		return "", false
+	}
+
+	// 只比较与模板层级相同的最后几级路径
+	parts := strings.Split(filepath.ToSlash(filePath), "/")
+	depth := strings.Count(rendered, "/") + 1
+	if len(parts) < depth {
+		return "", false
+	}
+	match := matcher.FindStringSubmatch(strings.Join(parts[len(parts)-depth:], "/"))
+	if match == nil || match[1] == "" {
+		return "", false
+	}
+	return match[1], true
+}
+
+// NameCollisions 找出会写入同一文件的条目
+//
+//   - 不区分大小写比较，以兼容 macOS 和 Windows 的文件系统
+//
+// 参数：
+//   - items: 条目名称
+//   - names: 条目将要写入的文件路径，与 items 按顺序一一对应
+//
+// 返回：
+//   - 文件路径和写入该路径的条目名称，只包含多个条目写入同一文件的情况
+func NameCollisions(items []string, names []string) map[string][]string {
+	var (
+		firstName = make(map[string]string)   // 规范化后的路径对应的原路径
+		owners    = make(map[string][]string) // 原路径对应的条目
+	)
+	for index, name := range names {
+		key := strings.ToLower(filepath.Clean(name))
+		if first, ok := firstName[key]; ok {
+			name = first
+		} else {
+			firstName[key] = name
+		}
+		owners[name] = append(owners[name], items[index])
+	}
+
+	collisions := make(map[string][]string)
+	for name, items := range owners {
+		if len(items) > 1 {
+			collisions[name] = items
+		}
+	}
+	return collisions
+}
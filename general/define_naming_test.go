@@ -0,0 +1,54 @@
+/*
+File: define_naming_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 17:14:36
+
+Description: 存档文件名模板测试
+*/
+
+package general
+
+import "testing"
+
+func TestCheckVolumeTemplate(t *testing.T) {
+	tests := map[string]bool{
+		"{{.Name}}_{{.Identity}}":       true,
+		"{{.Name}}/{{.Date}}-{{.Time}}": true,
+		"backup/{{.Date}}/{{.Name}}":    true,
+		"{{.Date}}-{{.Time}}":           false,
+		"{{.Identity}}/{{.Date}}":       false,
+		"{{.Name}":                      false,
+		"{{.Missing}}/{{.Name}}":        false,
+		"../{{.Name}}":                  false,
+	}
+	for nameTemplate, valid := range tests {
+		if err := CheckVolumeTemplate(nameTemplate, "volume"); (err == nil) != valid {
+			t.Errorf("CheckVolumeTemplate(%q) = %v, want valid %v", nameTemplate, err, valid)
+		}
+	}
+}
+
+func TestVolumeNameFromPath(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		name     string
+		ok       bool
+	}{
+		{"{{.Name}}/{{.Date}}-{{.Time}}", "/backup/data/20261020-171436.tar.gz", "data", true},
+		{"{{.Name}}/{{.Date}}-{{.Time}}", "s3://bucket/backup/my_db/20261020-171436.tar.gz", "my_db", true},
+		{"{{.Name}}/{{.Date}}-{{.Time}}", "20261020-171436.tar.gz", "", false},
+		{"{{.Name}}/{{.Date}}-{{.Time}}", "/backup/data/latest.tar.gz", "", false},
+		{"{{.Name}}_{{.Identity}}-{{.Date}}", "/backup/my_db_volume-20261020.tar.gz", "my_db", true},
+		{"{{.Date}}/{{.Name}}.tar.gz", "/backup/20261020/data.tar.gz", "data", true},
+		{"backup/{{.Name}}/{{.Name}}-{{.Time}}", "/srv/backup/data/data-171436.tar.gz", "data", true},
+		{"{{.Name}}_{{.Identity}}", "/backup/data_other.tar.gz", "", false},
+	}
+	for _, test := range tests {
+		name, ok := VolumeNameFromPath(test.template, "volume", ".tar.gz", test.path)
+		if name != test.name || ok != test.ok {
+			t.Errorf("VolumeNameFromPath(%q, %q) = %q, %v, want %q, %v", test.template, test.path, name, ok, test.name, test.ok)
+		}
+	}
+}
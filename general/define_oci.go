@@ -0,0 +1,650 @@
+/*
+File: define_oci.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 12:08:44
+
+Description: OCI image layout 与 docker save 存档格式的相互转换
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	containerdImageNameAnnotation = "io.containerd.image.name"                                  // containerd 用于保存完整 image 名的注解
+	dockerManifestListMediaType   = "application/vnd.docker.distribution.manifest.list.v2+json" // docker manifest list 的媒体类型
+)
+
+// layoutWriter OCI image layout 的写入目标，可以是文件夹或 tar 存档
+type layoutWriter interface {
+	// writeFile 写入一个文件，name 为相对于 layout 根的路径
+	writeFile(name string, size int64, reader io.Reader) error
+	// Close 完成写入
+	Close() error
+}
+
+// 写入到文件夹的 OCI image layout
+type folderLayoutWriter struct {
+	folderPath string
+}
+
+func (writer folderLayoutWriter) writeFile(name string, size int64, reader io.Reader) error {
+	file, err := ReCreateFile(filepath.Join(writer.folderPath, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func (writer folderLayoutWriter) Close() error {
+	return nil
+}
+
+// 写入到 tar 存档的 OCI image layout
+type tarLayoutWriter struct {
+	file      *os.File
+	tarWriter *tar.Writer
+}
+
+func (writer tarLayoutWriter) writeFile(name string, size int64, reader io.Reader) error {
+	return writeTarFile(writer.tarWriter, name, size, reader)
+}
+
+func (writer tarLayoutWriter) Close() error {
+	if err := writer.tarWriter.Close(); err != nil {
+		writer.file.Close()
+		return err
+	}
+	return writer.file.Close()
+}
+
+// IsOCILayout 判断路径是否为需要转换才能加载的 OCI image layout
+//
+//   - 文件夹中包含 oci-layout 文件
+//   - tar 存档中包含 oci-layout 但不包含 manifest.json（同时包含两者的是 docker 25 及以后的 docker save 存档，可直接加载）
+//
+// 参数：
+//   - layoutPath: 文件夹或 tar 存档路径
+//
+// 返回：
+//   - 是否为 OCI image layout
+func IsOCILayout(layoutPath string) bool {
+	info, err := os.Stat(layoutPath)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return FileExist(filepath.Join(layoutPath, ocispec.ImageLayoutFile))
+	}
+
+	names, err := TarEntryNames(layoutPath)
+	if err != nil {
+		return false
+	}
+	return SliceContains(names, ocispec.ImageLayoutFile) && !SliceContains(names, "manifest.json")
+}
+
+// blobPath 返回 blob 在 layout 中的路径
+func blobPath(dgst digest.Digest) string {
+	return fmt.Sprintf("%s/%s/%s", ocispec.ImageBlobsDir, dgst.Algorithm(), dgst.Encoded())
+}
+
+// detectLayerMediaType 根据文件头判断 layer 的媒体类型
+//
+// 参数：
+//   - filePath: layer 文件路径
+//
+// 返回：
+//   - 媒体类型
+//   - 错误信息
+func detectLayerMediaType(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(file, magic); err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return ocispec.MediaTypeImageLayerGzip, nil
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return ocispec.MediaTypeImageLayerZstd, nil
+	default:
+		return ocispec.MediaTypeImageLayer, nil
+	}
+}
+
+// copyBlob 计算文件的摘要并作为 blob 写入 layout，已写入的 blob 不重复写入
+//
+// 参数：
+//   - writer: layout 写入目标
+//   - filePath: 文件路径
+//   - mediaType: 媒体类型
+//   - written: 已写入的 blob
+//
+// 返回：
+//   - blob 描述符
+//   - 错误信息
+func copyBlob(writer layoutWriter, filePath, mediaType string, written map[digest.Digest]bool) (ocispec.Descriptor, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer file.Close()
+
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(digester.Hash(), file)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	descriptor := ocispec.Descriptor{MediaType: mediaType, Digest: digester.Digest(), Size: size}
+
+	if !written[descriptor.Digest] {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return descriptor, err
+		}
+		if err := writer.writeFile(blobPath(descriptor.Digest), size, file); err != nil {
+			return descriptor, err
+		}
+		written[descriptor.Digest] = true
+	}
+
+	return descriptor, nil
+}
+
+// writeJSONBlob 将对象序列化为 JSON 并作为 blob 写入 layout
+//
+// 参数：
+//   - writer: layout 写入目标
+//   - value: 待序列化的对象
+//   - mediaType: 媒体类型
+//
+// 返回：
+//   - blob 描述符
+//   - 错误信息
+func writeJSONBlob(writer layoutWriter, value any, mediaType string) (ocispec.Descriptor, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	descriptor := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+	return descriptor, writer.writeFile(blobPath(descriptor.Digest), descriptor.Size, bytes.NewReader(data))
+}
+
+// ReadDockerArchiveManifest 读取已解包的 docker save 存档中的 manifest.json
+//
+// 参数：
+//   - folderPath: 解包后的文件夹路径
+//
+// 返回：
+//   - manifest.json 条目切片
+//   - 错误信息
+func ReadDockerArchiveManifest(folderPath string) ([]DockerArchiveManifest, error) {
+	data, err := os.ReadFile(filepath.Join(folderPath, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	var manifests []DockerArchiveManifest
+	err = json.Unmarshal(data, &manifests)
+	return manifests, err
+}
+
+// dockerArchiveToOCI 将已解包的 docker save 存档转换为 OCI image layout
+//
+// 参数：
+//   - folderPath: 解包后的 docker save 存档文件夹路径
+//   - writer: layout 写入目标
+//
+// 返回：
+//   - 错误信息
+func dockerArchiveToOCI(folderPath string, writer layoutWriter) error {
+	manifests, err := ReadDockerArchiveManifest(folderPath)
+	if err != nil {
+		return err
+	}
+
+	written := make(map[digest.Digest]bool) // 已写入的 blob
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+	}
+
+	for _, entry := range manifests {
+		// 配置
+		configPath, err := safeJoin(folderPath, entry.Config)
+		if err != nil {
+			return err
+		}
+		configDescriptor, err := copyBlob(writer, configPath, ocispec.MediaTypeImageConfig, written)
+		if err != nil {
+			return err
+		}
+		configData, err := os.ReadFile(configPath)
+		if err != nil {
+			return err
+		}
+		var config ocispec.Image
+		if err := json.Unmarshal(configData, &config); err != nil {
+			return err
+		}
+
+		// 层
+		manifest := ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    configDescriptor,
+			Layers:    []ocispec.Descriptor{},
+		}
+		for _, layer := range entry.Layers {
+			layerPath, err := safeJoin(folderPath, layer)
+			if err != nil {
+				return err
+			}
+			mediaType, err := detectLayerMediaType(layerPath)
+			if err != nil {
+				return err
+			}
+			layerDescriptor, err := copyBlob(writer, layerPath, mediaType, written)
+			if err != nil {
+				return err
+			}
+			manifest.Layers = append(manifest.Layers, layerDescriptor)
+		}
+
+		manifestDescriptor, err := writeJSONBlob(writer, manifest, ocispec.MediaTypeImageManifest)
+		if err != nil {
+			return err
+		}
+		manifestDescriptor.Platform = &ocispec.Platform{
+			Architecture: config.Architecture,
+			OS:           config.OS,
+			Variant:      config.Variant,
+		}
+
+		// 每个 Tag 一个索引条目
+		if len(entry.RepoTags) == 0 {
+			index.Manifests = append(index.Manifests, manifestDescriptor)
+			continue
+		}
+		for _, repoTag := range entry.RepoTags {
+			taggedDescriptor := manifestDescriptor
+			taggedDescriptor.Annotations = map[string]string{containerdImageNameAnnotation: repoTag}
+			if named, err := reference.ParseNormalizedNamed(repoTag); err == nil {
+				taggedDescriptor.Annotations[containerdImageNameAnnotation] = named.String()
+				if tagged, ok := reference.TagNameOnly(named).(reference.Tagged); ok {
+					taggedDescriptor.Annotations[ocispec.AnnotationRefName] = tagged.Tag()
+				}
+			}
+			index.Manifests = append(index.Manifests, taggedDescriptor)
+		}
+	}
+
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := writer.writeFile(ocispec.ImageIndexFile, int64(len(indexData)), bytes.NewReader(indexData)); err != nil {
+		return err
+	}
+	layoutData, err := json.Marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	return writer.writeFile(ocispec.ImageLayoutFile, int64(len(layoutData)), bytes.NewReader(layoutData))
+}
+
+// SaveImageOCI 将指定 image 保存为 OCI image layout
+//
+//   - 先以 docker save 格式取出 image，再转换为 OCI image layout，可被 skopeo、crane、containerd 等工具使用
+//   - 转换基于 manifest.json，所以每个 image 只包含一个平台，未指定平台时为 docker service 的默认平台
+//
+// 参数：
+//   - imageName: image 的 Repository(:Tag) 或 ID
+//   - layoutPath: 保存路径，文件夹或 tar 存档
+//   - archive: 是否保存为 tar 存档，否则保存为文件夹
+//   - platform: 平台，例如 'linux/arm64'，为空时使用 docker service 的默认平台
+//
+// 返回：
+//   - 错误信息
+func SaveImageOCI(imageName string, layoutPath string, archive bool, platform string) error {
+	// 检索指定 image 为 io.ReadCloser
+	reader, err := docker.ImageSave(ctx, []string{imageName})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// 解包到临时文件夹
+	tempDir, err := os.MkdirTemp("", "wocker-oci-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	if err := ExtractTar(reader, tempDir); err != nil {
+		return err
+	}
+	if platform != "" {
+		if err := FilterArchivePlatform(tempDir, platform); err != nil {
+			return err
+		}
+	}
+
+	// 创建写入目标，文件夹先写入同级的临时文件夹，完成后再替换
+	if err := checkLayoutTarget(layoutPath, archive); err != nil {
+		return err
+	}
+	var (
+		writer     layoutWriter
+		tempLayout string // 写入中的临时文件夹
+	)
+	if archive {
+		file, err := ReCreateFile(layoutPath)
+		if err != nil {
+			return err
+		}
+		writer = tarLayoutWriter{file: file, tarWriter: tar.NewWriter(file)}
+	} else {
+		if err := CreateFolder(filepath.Dir(layoutPath)); err != nil {
+			return err
+		}
+		if tempLayout, err = os.MkdirTemp(filepath.Dir(layoutPath), "."+filepath.Base(layoutPath)+".tmp-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempLayout)
+		writer = folderLayoutWriter{folderPath: tempLayout}
+	}
+
+	if err := dockerArchiveToOCI(tempDir, writer); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil || archive {
+		return err
+	}
+	// 临时文件夹仅所有者可访问，改为与普通文件夹一致的权限
+	if err := os.Chmod(tempLayout, 0755); err != nil {
+		return err
+	}
+	if err := DeleteFile(layoutPath); err != nil {
+		return err
+	}
+	return os.Rename(tempLayout, layoutPath)
+}
+
+// checkLayoutTarget 检查 OCI image layout 的保存路径，拒绝覆盖与之无关的文件夹
+//
+//   - 保存为文件夹时，路径可以不存在、是空文件夹或之前保存的 OCI image layout 文件夹
+//   - 保存为 tar 存档时，路径不能是文件夹
+//
+// 参数：
+//   - layoutPath: 保存路径
+//   - archive: 是否保存为 tar 存档
+//
+// 返回：
+//   - 错误信息
+func checkLayoutTarget(layoutPath string, archive bool) error {
+	info, err := os.Stat(layoutPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if archive {
+		if info.IsDir() {
+			return fmt.Errorf("%s: %s", layoutPath, LayoutPathExistsMessage)
+		}
+		return nil
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(layoutPath)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 || IsOCILayout(layoutPath) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %s", layoutPath, LayoutPathExistsMessage)
+}
+
+// readJSONBlob 读取 layout 中的 JSON blob
+//
+// 参数：
+//   - folderPath: layout 文件夹路径
+//   - descriptor: blob 描述符
+//   - value: 用于接收 JSON 内容的对象
+//
+// 返回：
+//   - 错误信息
+func readJSONBlob(folderPath string, descriptor ocispec.Descriptor, value any) error {
+	data, err := os.ReadFile(filepath.Join(folderPath, filepath.FromSlash(blobPath(descriptor.Digest))))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// matchPlatform 判断描述符的平台是否与指定平台一致
+//
+// 参数：
+//   - descriptor: 描述符
+//   - platform: 平台，例如 'linux/amd64'、'linux/arm/v7'
+//
+// 返回：
+//   - 是否一致，描述符没有平台信息时返回 false
+func matchPlatform(descriptor ocispec.Descriptor, platform string) bool {
+	if descriptor.Platform == nil {
+		return false
+	}
+	return platformMatches(*descriptor.Platform, platform)
+}
+
+// resolveManifest 将描述符解析为单一平台的 manifest
+//
+//   - 描述符指向索引时，选取与当前平台一致的 manifest，没有一致的则选取第一个
+//
+// 参数：
+//   - folderPath: layout 文件夹路径
+//   - descriptor: manifest 或索引的描述符
+//
+// 返回：
+//   - manifest
+//   - 错误信息
+func resolveManifest(folderPath string, descriptor ocispec.Descriptor) (ocispec.Manifest, error) {
+	var manifest ocispec.Manifest
+
+	if isIndex(descriptor) {
+		var index ocispec.Index
+		if err := readJSONBlob(folderPath, descriptor, &index); err != nil {
+			return manifest, err
+		}
+		if len(index.Manifests) == 0 {
+			return manifest, fmt.Errorf("empty image index: %s", descriptor.Digest)
+		}
+		selected := index.Manifests[0]
+		for _, candidate := range index.Manifests {
+			if matchPlatform(candidate, runtime.GOOS+"/"+runtime.GOARCH) {
+				selected = candidate
+				break
+			}
+		}
+		return resolveManifest(folderPath, selected)
+	}
+
+	err := readJSONBlob(folderPath, descriptor, &manifest)
+	return manifest, err
+}
+
+// ociImageName 从索引条目的注解中获取完整的 image 引用
+//
+// 参数：
+//   - descriptor: 索引条目
+//
+// 返回：
+//   - 完整的 image 引用，无法确定时为空
+func ociImageName(descriptor ocispec.Descriptor) string {
+	if name := descriptor.Annotations[containerdImageNameAnnotation]; name != "" {
+		return name
+	}
+	// org.opencontainers.image.ref.name 通常只是 Tag，仅在其本身是完整引用时使用
+	if name := descriptor.Annotations[ocispec.AnnotationRefName]; strings.ContainsAny(name, "/:") {
+		if _, err := reference.ParseNormalizedNamed(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// ociToDockerArchive 将 OCI image layout 转换为 docker save 存档流
+//
+// 参数：
+//   - folderPath: layout 文件夹路径
+//   - writer: docker save 存档的写入目标
+//
+// 返回：
+//   - 错误信息
+func ociToDockerArchive(folderPath string, writer io.Writer) error {
+	var index ocispec.Index
+	indexData, err := os.ReadFile(filepath.Join(folderPath, ocispec.ImageIndexFile))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return err
+	}
+
+	tarWriter := tar.NewWriter(writer)
+	written := make(map[string]bool) // 已写入的 blob
+
+	// 写入 blob
+	writeBlob := func(descriptor ocispec.Descriptor) (string, error) {
+		name := blobPath(descriptor.Digest)
+		if written[name] {
+			return name, nil
+		}
+		file, err := os.Open(filepath.Join(folderPath, filepath.FromSlash(name)))
+		if err != nil {
+			return name, err
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			return name, err
+		}
+		written[name] = true
+		return name, writeTarFile(tarWriter, name, info.Size(), file)
+	}
+
+	var manifests []DockerArchiveManifest
+	entries := make(map[digest.Digest]int) // manifest 摘要到 manifest.json 条目的索引，同一 image 的多个 Tag 合并
+	for _, descriptor := range index.Manifests {
+		manifest, err := resolveManifest(folderPath, descriptor)
+		if err != nil {
+			return err
+		}
+
+		position, ok := entries[manifest.Config.Digest]
+		if !ok {
+			entry := DockerArchiveManifest{RepoTags: []string{}}
+			if entry.Config, err = writeBlob(manifest.Config); err != nil {
+				return err
+			}
+			for _, layer := range manifest.Layers {
+				layerName, err := writeBlob(layer)
+				if err != nil {
+					return err
+				}
+				entry.Layers = append(entry.Layers, layerName)
+			}
+			manifests = append(manifests, entry)
+			position = len(manifests) - 1
+			entries[manifest.Config.Digest] = position
+		}
+
+		if name := ociImageName(descriptor); name != "" {
+			if named, err := reference.ParseNormalizedNamed(name); err == nil {
+				manifests[position].RepoTags = append(manifests[position].RepoTags, reference.FamiliarString(reference.TagNameOnly(named)))
+			}
+		}
+	}
+
+	manifestData, err := json.Marshal(manifests)
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tarWriter, "manifest.json", int64(len(manifestData)), bytes.NewReader(manifestData)); err != nil {
+		return err
+	}
+
+	return tarWriter.Close()
+}
+
+// LoadImageOCI 从 OCI image layout 加载 image
+//
+//   - 将 OCI image layout 转换为 docker save 存档流后加载
+//
+// 参数：
+//   - layoutPath: layout 文件夹或 tar 存档路径
+//
+// 返回：
+//   - docker service 是否返回错误信息
+//   - docker service 的返回信息
+//   - 错误信息
+func LoadImageOCI(layoutPath string) (bool, []string, error) {
+	folderPath := layoutPath
+
+	// tar 存档先解包到临时文件夹
+	info, err := os.Stat(layoutPath)
+	if err != nil {
+		return false, nil, err
+	}
+	if !info.IsDir() {
+		tempDir, err := os.MkdirTemp("", "wocker-oci-")
+		if err != nil {
+			return false, nil, err
+		}
+		defer os.RemoveAll(tempDir)
+
+		file, err := os.Open(layoutPath)
+		if err != nil {
+			return false, nil, err
+		}
+		defer file.Close()
+		if err := ExtractTar(file, tempDir); err != nil {
+			return false, nil, err
+		}
+		folderPath = tempDir
+	}
+
+	// 边转换边加载
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(ociToDockerArchive(folderPath, pipeWriter))
+	}()
+	defer pipeReader.Close()
+
+	return loadImage(docker, pipeReader)
+}
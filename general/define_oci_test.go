@@ -0,0 +1,60 @@
+/*
+File: define_oci_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 11:04:36
+
+Description: OCI image layout 保存路径检查测试
+*/
+
+package general
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLayoutTarget(t *testing.T) {
+	folder := t.TempDir()
+	var (
+		missing = filepath.Join(folder, "missing")
+		empty   = filepath.Join(folder, "empty")
+		layout  = filepath.Join(folder, "layout")
+		other   = filepath.Join(folder, "other")
+		file    = filepath.Join(folder, "image.tar")
+	)
+	for _, dir := range []string{empty, layout, other} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{filepath.Join(layout, "oci-layout"), filepath.Join(other, "notes.txt"), file} {
+		if err := os.WriteFile(name, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		layoutPath string
+		archive    bool
+		wantErr    bool
+	}{
+		{"missing folder", missing, false, false},
+		{"empty folder", empty, false, false},
+		{"previous layout", layout, false, false},
+		{"unrelated folder", other, false, true},
+		{"file as folder", file, false, true},
+		{"missing archive", missing, true, false},
+		{"existing archive", file, true, false},
+		{"folder as archive", other, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := checkLayoutTarget(test.layoutPath, test.archive); (err != nil) != test.wantErr {
+				t.Errorf("checkLayoutTarget(%q, %v) error = %v, want error %v", test.layoutPath, test.archive, err, test.wantErr)
+			}
+		})
+	}
+}
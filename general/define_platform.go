@@ -0,0 +1,388 @@
+/*
+File: define_platform.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:42:05
+
+Description: 多平台 image 的识别和筛选
+*/
+
+package general
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/client"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	manifestsAPIVersion        = "1.47"                        // 支持在 image 列表中返回各平台 manifest 的最低 API 版本
+	attestationReferenceDigest = "vnd.docker.reference.digest" // 证明 manifest 指向的 image manifest 摘要
+	attestationManifestKind    = "attestation"                 // 证明 manifest 的类型
+)
+
+// imageSummaryWithManifests 带 manifest 列表的 image 摘要，API 1.47 起由 `GET /images/json?manifests=1` 返回
+type imageSummaryWithManifests struct {
+	ID        string `json:"Id"`
+	Manifests []struct {
+		Kind      string `json:"Kind"`
+		Available bool   `json:"Available"`
+		ImageData *struct {
+			Platform ocispec.Platform `json:"Platform"`
+		} `json:"ImageData"`
+	} `json:"Manifests"`
+}
+
+// FormatPlatform 将平台格式化为 'os/arch[/variant]'
+//
+// 参数：
+//   - platform: 平台
+//
+// 返回：
+//   - 格式化的平台
+func FormatPlatform(platform ocispec.Platform) string {
+	formatted := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		formatted += "/" + platform.Variant
+	}
+	return formatted
+}
+
+// platformMatches 判断平台是否与 'os/arch[/variant]' 一致，未指定 variant 时不比较 variant
+//
+// 参数：
+//   - platform: 平台
+//   - expected: 期望的平台，例如 'linux/amd64'、'linux/arm/v7'
+//
+// 返回：
+//   - 是否一致
+func platformMatches(platform ocispec.Platform, expected string) bool {
+	parts := strings.Split(expected, "/")
+	if len(parts) < 2 || parts[0] != platform.OS || parts[1] != platform.Architecture {
+		return false
+	}
+	return len(parts) < 3 || parts[2] == platform.Variant
+}
+
+// dockerAPIGet 直接请求 docker API 并解析 JSON 响应，用于客户端库尚未支持的参数
+//
+// 参数：
+//   - apiPath: 不含版本前缀的 API 路径，例如 '/images/json'
+//   - value: 用于接收 JSON 响应的对象
+//
+// 返回：
+//   - 错误信息
+func dockerAPIGet(apiPath string, value any) error {
+	hostURL, err := client.ParseHostURL(docker.DaemonHost())
+	if err != nil {
+		return err
+	}
+
+	scheme, host := "http", hostURL.Host
+	if hostURL.Scheme == "unix" || hostURL.Scheme == "npipe" {
+		host = client.DummyHost
+	}
+	if transport, ok := docker.HTTPClient().Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		scheme = "https"
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s%s/v%s%s", scheme, host, hostURL.Path, docker.ClientVersion(), apiPath), nil)
+	if err != nil {
+		return err
+	}
+	response, err := docker.HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", apiPath, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(value)
+}
+
+// ListImagePlatforms 获取每个 image 包含的平台
+//
+//   - docker service 支持 API 1.47 及以上时，返回 containerd image store 中每个 image 本地可用的全部平台
+//   - 否则返回 `docker image inspect` 中的平台，即每个 image 只有一个平台
+//
+// 返回：
+//   - image ID 到平台切片的映射，平台格式为 'os/arch[/variant]'，已排序
+//   - 错误信息
+func ListImagePlatforms() (map[string][]string, error) {
+	platforms := make(map[string][]string)
+
+	docker.NegotiateAPIVersion(ctx)
+	if versions.GreaterThanOrEqualTo(docker.ClientVersion(), manifestsAPIVersion) {
+		var summaries []imageSummaryWithManifests
+		if err := dockerAPIGet("/images/json?all=1&manifests=1", &summaries); err == nil {
+			for _, summary := range summaries {
+				for _, manifest := range summary.Manifests {
+					if manifest.Kind == attestationManifestKind || !manifest.Available || manifest.ImageData == nil {
+						continue
+					}
+					formatted := FormatPlatform(manifest.ImageData.Platform)
+					if !SliceContains(platforms[summary.ID], formatted) {
+						platforms[summary.ID] = append(platforms[summary.ID], formatted)
+					}
+				}
+				sort.Strings(platforms[summary.ID])
+			}
+		}
+	}
+
+	// 不支持 manifest 列表或 image 不在 containerd image store 中时，使用 inspect 的结果
+	images, err := ListImages()
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range images {
+		if len(platforms[image.ID]) > 0 {
+			continue
+		}
+		info, err := InspectImage(image.ID)
+		if err != nil {
+			return nil, err
+		}
+		platforms[image.ID] = []string{FormatPlatform(ocispec.Platform{OS: info.Os, Architecture: info.Architecture, Variant: info.Variant})}
+	}
+
+	return platforms, nil
+}
+
+// ImagePlatforms 获取指定 image 包含的平台
+//
+// 参数：
+//   - imageName: image 的引用或 ID
+//
+// 返回：
+//   - 平台切片，格式为 'os/arch[/variant]'
+//   - 错误信息
+func ImagePlatforms(imageName string) ([]string, error) {
+	info, err := InspectImage(imageName)
+	if err != nil {
+		return nil, err
+	}
+	platforms, err := ListImagePlatforms()
+	if err != nil {
+		return nil, err
+	}
+	return platforms[info.ID], nil
+}
+
+// descriptorPlatform 获取 manifest 描述符对应的平台，描述符没有平台信息时读取 image 配置
+//
+// 参数：
+//   - folderPath: layout 文件夹路径
+//   - descriptor: manifest 描述符
+//
+// 返回：
+//   - 平台
+//   - 错误信息
+func descriptorPlatform(folderPath string, descriptor ocispec.Descriptor) (ocispec.Platform, error) {
+	if descriptor.Platform != nil {
+		return *descriptor.Platform, nil
+	}
+	var manifest ocispec.Manifest
+	if err := readJSONBlob(folderPath, descriptor, &manifest); err != nil {
+		return ocispec.Platform{}, err
+	}
+	var config ocispec.Image
+	if err := readJSONBlob(folderPath, manifest.Config, &config); err != nil {
+		return ocispec.Platform{}, err
+	}
+	return config.Platform, nil
+}
+
+// isIndex 判断描述符是否指向索引
+func isIndex(descriptor ocispec.Descriptor) bool {
+	return descriptor.MediaType == ocispec.MediaTypeImageIndex || descriptor.MediaType == dockerManifestListMediaType
+}
+
+// FilterArchivePlatform 只保留已解包的 docker save 存档中指定平台的内容
+//
+//   - 改写 index.json 及其引用的索引，只保留指定平台的 manifest 及其证明 manifest
+//   - 改写 manifest.json，使仅支持 manifest.json 的 docker service 加载指定平台
+//   - 删除不再被引用的 blob
+//
+// 参数：
+//   - folderPath: 解包后的 docker save 存档文件夹路径
+//   - platform: 平台，例如 'linux/amd64'、'linux/arm/v7'
+//
+// 返回：
+//   - 错误信息，存档中的某个 image 不包含指定平台时同样返回错误
+func FilterArchivePlatform(folderPath string, platform string) error {
+	indexFile := filepath.Join(folderPath, ocispec.ImageIndexFile)
+	var index ocispec.Index
+	indexData, err := os.ReadFile(indexFile)
+	if err != nil {
+		return fmt.Errorf("archive without %s does not support platform selection: %w", ocispec.ImageIndexFile, err)
+	}
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return err
+	}
+
+	writer := folderLayoutWriter{folderPath: folderPath}
+	var selected []ocispec.Descriptor // 每个 image 选中的 manifest，与 index.Manifests 一一对应
+	for position, descriptor := range index.Manifests {
+		// 单平台 image 只需检查平台
+		if !isIndex(descriptor) {
+			imagePlatform, err := descriptorPlatform(folderPath, descriptor)
+			if err != nil {
+				return err
+			}
+			if !platformMatches(imagePlatform, platform) {
+				return fmt.Errorf(NoSuchPlatformMessage, platform, FormatPlatform(imagePlatform))
+			}
+			selected = append(selected, descriptor)
+			continue
+		}
+
+		// 多平台 image 筛选索引
+		var nested ocispec.Index
+		if err := readJSONBlob(folderPath, descriptor, &nested); err != nil {
+			return err
+		}
+		var (
+			kept      []ocispec.Descriptor
+			keptSet   = make(map[digest.Digest]bool)
+			available []string
+		)
+		for _, candidate := range nested.Manifests {
+			if candidate.Annotations[attestationReferenceDigest] != "" {
+				continue
+			}
+			candidatePlatform, err := descriptorPlatform(folderPath, candidate)
+			if err != nil {
+				// 本地不存在的平台没有 blob，跳过
+				continue
+			}
+			available = append(available, FormatPlatform(candidatePlatform))
+			if platformMatches(candidatePlatform, platform) {
+				kept = append(kept, candidate)
+				keptSet[candidate.Digest] = true
+			}
+		}
+		if len(kept) == 0 {
+			return fmt.Errorf(NoSuchPlatformMessage, platform, strings.Join(available, ", "))
+		}
+		// 保留指向选中 manifest 的证明 manifest
+		for _, candidate := range nested.Manifests {
+			if keptSet[digest.Digest(candidate.Annotations[attestationReferenceDigest])] {
+				kept = append(kept, candidate)
+			}
+		}
+		selected = append(selected, kept[0])
+
+		nested.Manifests = kept
+		rewritten, err := writeJSONBlob(writer, nested, descriptor.MediaType)
+		if err != nil {
+			return err
+		}
+		index.Manifests[position].Digest, index.Manifests[position].Size = rewritten.Digest, rewritten.Size
+	}
+
+	if indexData, err = json.Marshal(index); err != nil {
+		return err
+	}
+	if err := os.WriteFile(indexFile, indexData, 0644); err != nil {
+		return err
+	}
+
+	// 改写 manifest.json，条目与 index.json 中的 image 按顺序对应
+	manifests, err := ReadDockerArchiveManifest(folderPath)
+	if err != nil {
+		return err
+	}
+	for position := range manifests {
+		if position >= len(selected) {
+			break
+		}
+		var manifest ocispec.Manifest
+		if err := readJSONBlob(folderPath, selected[position], &manifest); err != nil {
+			return err
+		}
+		manifests[position].Config = blobPath(manifest.Config.Digest)
+		manifests[position].Layers = nil
+		for _, layer := range manifest.Layers {
+			manifests[position].Layers = append(manifests[position].Layers, blobPath(layer.Digest))
+		}
+	}
+	manifestData, err := json.Marshal(manifests)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(folderPath, "manifest.json"), manifestData, 0644); err != nil {
+		return err
+	}
+
+	return removeUnreferencedBlobs(folderPath, index, manifests)
+}
+
+// removeUnreferencedBlobs 删除 layout 中不再被 index.json 和 manifest.json 引用的 blob
+//
+// 参数：
+//   - folderPath: layout 文件夹路径
+//   - index: index.json 的内容
+//   - manifests: manifest.json 的内容
+//
+// 返回：
+//   - 错误信息
+func removeUnreferencedBlobs(folderPath string, index ocispec.Index, manifests []DockerArchiveManifest) error {
+	referenced := make(map[string]bool) // 被引用的 blob 路径
+
+	// 遍历索引和 manifest，本地不存在的 blob 不再深入
+	var walk func(descriptor ocispec.Descriptor)
+	walk = func(descriptor ocispec.Descriptor) {
+		referenced[blobPath(descriptor.Digest)] = true
+		if isIndex(descriptor) {
+			var nested ocispec.Index
+			if readJSONBlob(folderPath, descriptor, &nested) == nil {
+				for _, child := range nested.Manifests {
+					walk(child)
+				}
+			}
+			return
+		}
+		var manifest ocispec.Manifest
+		if readJSONBlob(folderPath, descriptor, &manifest) == nil && manifest.Config.Digest != "" {
+			referenced[blobPath(manifest.Config.Digest)] = true
+			for _, layer := range manifest.Layers {
+				referenced[blobPath(layer.Digest)] = true
+			}
+		}
+	}
+	for _, descriptor := range index.Manifests {
+		walk(descriptor)
+	}
+	for _, entry := range manifests {
+		referenced[CleanArchivePath(entry.Config)] = true
+		for _, layer := range entry.Layers {
+			referenced[CleanArchivePath(layer)] = true
+		}
+	}
+
+	blobsDir := filepath.Join(folderPath, ocispec.ImageBlobsDir)
+	return filepath.WalkDir(blobsDir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(folderPath, filePath)
+		if err != nil {
+			return err
+		}
+		if !referenced[filepath.ToSlash(relPath)] {
+			return os.Remove(filePath)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,341 @@
+/*
+File: define_registry.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:31:09
+
+Description: 与 image registry 交互
+*/
+
+package general
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/moby/term"
+)
+
+const dockerHubAuthKey = "https://index.docker.io/v1/" // Docker Hub 在 docker 配置文件中的键名
+
+// docker 配置文件中与认证相关的部分
+type dockerConfigFile struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`       // 直接保存的认证信息
+	CredsStore  string                      `json:"credsStore"`  // 默认的凭据助手
+	CredHelpers map[string]string           `json:"credHelpers"` // 按 registry 指定的凭据助手
+}
+
+// docker 配置文件中单个 registry 的认证信息
+type dockerConfigAuth struct {
+	Auth          string `json:"auth"` // base64 编码的 'username:password'
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// 凭据助手的输出
+type credentialHelperOutput struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// DockerConfigDir 返回 docker 客户端配置文件夹
+//
+//   - 优先使用环境变量 DOCKER_CONFIG，否则为 ~/.docker
+//
+// 返回：
+//   - 配置文件夹路径
+func DockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".docker")
+}
+
+// RegistryDomain 获取 image 引用所属的 registry 域名
+//
+// 参数：
+//   - imageRef: image 引用，例如 'localhost:5000/app:1.0'
+//
+// 返回：
+//   - registry 域名，Docker Hub 为 'docker.io'
+//   - 错误信息
+func RegistryDomain(imageRef string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return "", err
+	}
+	return reference.Domain(named), nil
+}
+
+// RetargetReference 将 image 引用改写到指定 registry 前缀之下
+//
+//   - 保留原引用的路径、Tag 和 digest，丢弃原 registry 域名，例如 'nginx:1.27' + 'localhost:5000/backup' -> 'localhost:5000/backup/library/nginx:1.27'
+//   - 既没有 Tag 也没有 digest 时使用 'latest'
+//
+// 参数：
+//   - imageRef: image 引用
+//   - prefix: registry 前缀，例如 'localhost:5000/backup'
+//
+// 返回：
+//   - 改写后的引用
+//   - 错误信息
+func RetargetReference(imageRef, prefix string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return "", err
+	}
+	if prefix = strings.TrimSuffix(prefix, "/"); prefix == "" {
+		return "", fmt.Errorf("empty registry prefix")
+	}
+
+	target := prefix + "/" + reference.Path(named)
+	digested, hasDigest := named.(reference.Digested)
+	if !hasDigest {
+		named = reference.TagNameOnly(named)
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		target += ":" + tagged.Tag()
+	}
+	if hasDigest {
+		target += "@" + digested.Digest().String()
+	}
+
+	// 校验改写后的引用，前缀不合法时返回错误
+	if _, err := reference.ParseNormalizedNamed(target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// IsDigestReference 判断 image 引用是否包含 digest，包含 digest 的引用无法用于添加标签
+//
+// 参数：
+//   - imageRef: image 引用
+//
+// 返回：
+//   - 是否包含 digest
+func IsDigestReference(imageRef string) bool {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return false
+	}
+	_, ok := named.(reference.Digested)
+	return ok
+}
+
+// LocalImageID 获取引用在本地指向的 image ID
+//
+// 参数：
+//   - imageRef: image 引用
+//
+// 返回：
+//   - image ID，本地不存在该引用时为空
+//   - 错误信息
+func LocalImageID(imageRef string) (string, error) {
+	info, err := InspectImage(imageRef)
+	if client.IsErrNotFound(err) {
+		return "", nil
+	}
+	return info.ID, err
+}
+
+// RegistryAuth 获取访问 image 所属 registry 的认证信息
+//
+//   - 按 docker 的规则读取 docker 配置文件，依次使用 credHelpers、credsStore 和 auths
+//   - 没有找到认证信息时返回空认证，匿名访问
+//
+// 参数：
+//   - imageRef: image 引用
+//
+// 返回：
+//   - base64 编码的认证信息，可直接用于 push/pull
+//   - 错误信息
+func RegistryAuth(imageRef string) (string, error) {
+	domain, err := RegistryDomain(imageRef)
+	if err != nil {
+		return "", err
+	}
+	serverAddress := domain
+	if domain == "docker.io" {
+		serverAddress = dockerHubAuthKey
+	}
+
+	authConfig := registry.AuthConfig{ServerAddress: serverAddress}
+
+	data, err := os.ReadFile(filepath.Join(DockerConfigDir(), "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return registry.EncodeAuthConfig(authConfig)
+		}
+		return "", err
+	}
+	var config dockerConfigFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", err
+	}
+
+	// 凭据助手
+	helper := config.CredsStore
+	if name, ok := config.CredHelpers[serverAddress]; ok {
+		helper = name
+	}
+	if helper != "" {
+		output, err := runCredentialHelper(helper, serverAddress)
+		if err != nil {
+			return "", err
+		}
+		if output.Username == "<token>" {
+			authConfig.IdentityToken = output.Secret
+		} else {
+			authConfig.Username = output.Username
+			authConfig.Password = output.Secret
+		}
+		return registry.EncodeAuthConfig(authConfig)
+	}
+
+	// 直接保存在配置文件中的认证信息，键名可能带有协议头
+	for key, auth := range config.Auths {
+		if key != serverAddress && strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://") != serverAddress {
+			continue
+		}
+		authConfig.Username = auth.Username
+		authConfig.Password = auth.Password
+		authConfig.IdentityToken = auth.IdentityToken
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return "", err
+			}
+			authConfig.Username, authConfig.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		break
+	}
+
+	return registry.EncodeAuthConfig(authConfig)
+}
+
+// runCredentialHelper 调用 docker 凭据助手获取凭据
+//
+//   - 功能与命令 `echo <serverAddress> | docker-credential-<helper> get` 一样
+//
+// 参数：
+//   - helper: 凭据助手名，例如 'pass'、'secretservice'
+//   - serverAddress: registry 地址
+//
+// 返回：
+//   - 凭据
+//   - 错误信息
+func runCredentialHelper(helper, serverAddress string) (credentialHelperOutput, error) {
+	var output credentialHelperOutput
+
+	command := exec.Command("docker-credential-"+helper, "get")
+	command.Stdin = strings.NewReader(serverAddress)
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		message := strings.TrimSpace(stdout.String() + stderr.String())
+		// 凭据助手中没有该 registry 的凭据，匿名访问
+		if strings.Contains(message, "credentials not found") {
+			return output, nil
+		}
+		return output, fmt.Errorf("docker-credential-%s: %s", helper, message)
+	}
+
+	err := json.Unmarshal(stdout.Bytes(), &output)
+	return output, err
+}
+
+// displayJSONMessages 将 docker service 返回的 JSON 消息流输出到终端
+//
+// 参数：
+//   - response: docker service 返回的 JSON 消息流
+//
+// 返回：
+//   - 错误信息，消息流中包含错误时同样返回
+func displayJSONMessages(response io.Reader) error {
+	fd, isTerminal := term.GetFdInfo(os.Stdout)
+	return jsonmessage.DisplayJSONMessagesStream(response, os.Stdout, fd, isTerminal, nil)
+}
+
+// PushImage 为 image 添加新标签并推送到 registry
+//
+//   - 功能与命令 `docker tag <source> <target> && docker push <target>` 一样
+//
+// 参数：
+//   - source: 本地 image 的 Repository:Tag 或 ID
+//   - target: 推送目标的完整引用，例如 'localhost:5000/backup/app:1.0'
+//
+// 返回：
+//   - 错误信息
+func PushImage(source, target string) error {
+	if err := TagImage(source, target); err != nil {
+		return err
+	}
+
+	auth, err := RegistryAuth(target)
+	if err != nil {
+		return err
+	}
+
+	response, err := docker.ImagePush(ctx, target, image.PushOptions{RegistryAuth: auth})
+	if err != nil {
+		return err
+	}
+	defer response.Close()
+
+	return displayJSONMessages(response)
+}
+
+// PullImage 从 registry 拉取 image
+//
+//   - 功能与命令 `docker pull <imageRef>` 一样
+//
+// 参数：
+//   - imageRef: image 引用
+//
+// 返回：
+//   - 错误信息
+func PullImage(imageRef string) error {
+	auth, err := RegistryAuth(imageRef)
+	if err != nil {
+		return err
+	}
+
+	response, err := docker.ImagePull(ctx, imageRef, image.PullOptions{RegistryAuth: auth})
+	if err != nil {
+		return err
+	}
+	defer response.Close()
+
+	return displayJSONMessages(response)
+}
+
+// TagImage 为 image 添加标签
+//
+//   - 功能与命令 `docker tag <source> <target>` 一样
+//
+// 参数：
+//   - source: 本地 image 的 Repository:Tag 或 ID
+//   - target: 新的引用
+//
+// 返回：
+//   - 错误信息
+func TagImage(source, target string) error {
+	return docker.ImageTag(ctx, source, target)
+}
@@ -0,0 +1,174 @@
+/*
+File: define_registry_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 10:31:05
+
+Description: image 引用改写和 registry 认证测试
+*/
+
+package general
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/registry"
+)
+
+func TestRetargetReference(t *testing.T) {
+	const digest = "sha256:4d0d1ba1ec5d4e1c5f53e9b9f9c2b7a3f0a8b9c1d2e3f4a5b6c7d8e9f0a1b2c3"
+	tests := []struct {
+		name     string
+		imageRef string
+		prefix   string
+		want     string
+		wantErr  bool
+	}{
+		{"official image", "nginx:1.27", "localhost:5000/backup", "localhost:5000/backup/library/nginx:1.27", false},
+		{"default tag", "nginx", "localhost:5000/backup", "localhost:5000/backup/library/nginx:latest", false},
+		{"trailing slash", "nginx:1.27", "localhost:5000/backup/", "localhost:5000/backup/library/nginx:1.27", false},
+		{"drop source registry", "ghcr.io/owner/app:2.0", "registry.local", "registry.local/owner/app:2.0", false},
+		{"digest only", "app@" + digest, "localhost:5000", "localhost:5000/library/app@" + digest, false},
+		{"tag and digest", "owner/app:1.0@" + digest, "localhost:5000", "localhost:5000/owner/app:1.0@" + digest, false},
+		{"invalid reference", "App:1.0", "localhost:5000", "", true},
+		{"empty prefix", "nginx:1.27", "", "", true},
+		{"invalid prefix", "nginx:1.27", "Local Host", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := RetargetReference(test.imageRef, test.prefix)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("RetargetReference(%q, %q) error = %v, want error %v", test.imageRef, test.prefix, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("RetargetReference(%q, %q) = %q, want %q", test.imageRef, test.prefix, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsDigestReference(t *testing.T) {
+	tests := map[string]bool{
+		"nginx:1.27": false,
+		"nginx":      false,
+		"app@sha256:4d0d1ba1ec5d4e1c5f53e9b9f9c2b7a3f0a8b9c1d2e3f4a5b6c7d8e9f0a1b2c3":     true,
+		"app:1.0@sha256:4d0d1ba1ec5d4e1c5f53e9b9f9c2b7a3f0a8b9c1d2e3f4a5b6c7d8e9f0a1b2c3": true,
+		"not a reference": false,
+	}
+	for imageRef, want := range tests {
+		if got := IsDigestReference(imageRef); got != want {
+			t.Errorf("IsDigestReference(%q) = %v, want %v", imageRef, got, want)
+		}
+	}
+}
+
+// fakeCredentialHelper 凭据助手 'docker-credential-fake'，按 registry 地址返回固定凭据
+const fakeCredentialHelper = `#!/bin/sh
+read server
+case "$server" in
+helper.example.com) echo '{"ServerURL":"helper.example.com","Username":"helper-user","Secret":"helper-secret"}' ;;
+token.example.com) echo '{"ServerURL":"token.example.com","Username":"<token>","Secret":"refresh-token"}' ;;
+missing.example.com) echo "credentials not found in native keychain"; exit 1 ;;
+*) echo "helper is broken" >&2; exit 1 ;;
+esac
+`
+
+func TestRegistryAuth(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+	helperDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(helperDir, "docker-credential-fake"), []byte(fakeCredentialHelper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", helperDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	encoded := base64.StdEncoding.EncodeToString([]byte("auth-user:auth-pass"))
+	config := `{
+		"auths": {
+			"https://registry.example.com": {"auth": "` + encoded + `"},
+			"plain.example.com:5000": {"username": "plain-user", "password": "plain-pass"},
+			"https://index.docker.io/v1/": {"auth": "` + encoded + `"}
+		},
+		"credHelpers": {
+			"helper.example.com": "fake",
+			"token.example.com": "fake",
+			"missing.example.com": "fake",
+			"broken.example.com": "fake"
+		}
+	}`
+	tests := []struct {
+		name     string
+		config   string // config.json 的内容，为空时不创建配置文件
+		imageRef string
+		want     registry.AuthConfig
+		wantErr  bool
+	}{
+		{name: "credential helper", config: config, imageRef: "helper.example.com/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "helper.example.com", Username: "helper-user", Password: "helper-secret"}},
+		{name: "credential helper token", config: config, imageRef: "token.example.com/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "token.example.com", IdentityToken: "refresh-token"}},
+		{name: "credential helper without credentials", config: config, imageRef: "missing.example.com/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "missing.example.com"}},
+		{name: "broken credential helper", config: config, imageRef: "broken.example.com/app:1.0", wantErr: true},
+		{name: "default credential store", config: `{"credsStore": "fake"}`, imageRef: "helper.example.com/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "helper.example.com", Username: "helper-user", Password: "helper-secret"}},
+		{name: "auths entry with scheme", config: config, imageRef: "registry.example.com/team/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "registry.example.com", Username: "auth-user", Password: "auth-pass"}},
+		{name: "auths entry with password", config: config, imageRef: "plain.example.com:5000/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "plain.example.com:5000", Username: "plain-user", Password: "plain-pass"}},
+		{name: "docker hub", config: config, imageRef: "nginx:1.27",
+			want: registry.AuthConfig{ServerAddress: "https://index.docker.io/v1/", Username: "auth-user", Password: "auth-pass"}},
+		{name: "no match", config: config, imageRef: "other.example.com/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "other.example.com"}},
+		{name: "no config file", imageRef: "registry.example.com/app:1.0",
+			want: registry.AuthConfig{ServerAddress: "registry.example.com"}},
+		{name: "invalid config file", config: "{", imageRef: "registry.example.com/app:1.0", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configDir := t.TempDir()
+			if test.config != "" {
+				if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(test.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("DOCKER_CONFIG", configDir)
+
+			encodedAuth, err := RegistryAuth(test.imageRef)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("RegistryAuth(%q) should fail", test.imageRef)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := registry.DecodeAuthConfig(encodedAuth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != test.want {
+				t.Errorf("RegistryAuth(%q) = %+v, want %+v", test.imageRef, *got, test.want)
+			}
+		})
+	}
+}
+
+func TestDisplayJSONMessagesReturnsStreamError(t *testing.T) {
+	tests := map[string]bool{
+		`{"status":"Pushing","progressDetail":{},"id":"0123456789ab"}` + "\n" + `{"status":"Pushed","id":"0123456789ab"}`:                                         false,
+		`{"status":"Preparing","id":"0123456789ab"}` + "\n" + `{"errorDetail":{"message":"denied: requested access to the resource is denied"},"error":"denied"}`: true,
+	}
+	for stream, wantErr := range tests {
+		if err := displayJSONMessages(strings.NewReader(stream)); (err != nil) != wantErr {
+			t.Errorf("displayJSONMessages(%q) = %v, want error %v", stream, err, wantErr)
+		}
+	}
+}
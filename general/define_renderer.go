@@ -13,6 +13,7 @@ import (
 	"os"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
 )
 
 var (
@@ -20,3 +21,31 @@ var (
 	HeaderStyle = renderer.NewStyle().Align(lipgloss.Center).Padding(0, 1).Bold(true).Foreground(HeaderColor) // 表头样式
 	BorderStyle = renderer.NewStyle().Foreground(BorderColor)                                                 // 边框样式
 )
+
+// NewTable 创建一个使用统一样式的表格
+//
+// 参数：
+//   - header: 表头
+//   - data: 表数据
+//
+// 返回：
+//   - 表格
+func NewTable(header []string, data [][]string) *table.Table {
+	dataTable := table.New()                                // 创建一个表格
+	dataTable.Border(lipgloss.RoundedBorder())              // 设置表格边框
+	dataTable.BorderStyle(BorderStyle)                      // 设置表格边框样式
+	dataTable.StyleFunc(func(row, col int) lipgloss.Style { // 按位置设置单元格样式
+		var style lipgloss.Style
+
+		if row == 0 {
+			return HeaderStyle // 第一行为表头
+		}
+
+		return style
+	})
+
+	dataTable.Headers(header...) // 设置表头
+	dataTable.Rows(data...)      // 设置单元格
+
+	return dataTable
+}
@@ -0,0 +1,230 @@
+/*
+File: define_retention.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 19:48:51
+
+Description: 管理带时间戳的备份集及其保留策略
+
+Notice:
+	- 每次运行的存档保存在输出位置下的 '<kind>-<时间戳>-<随机后缀>' 文件夹中，一个文件夹即一个备份集，随机后缀使同一秒内开始的运行互不影响
+	- 运行过程中文件夹名带有 '.partial' 后缀，全部保存成功后才去掉，保留策略只处理完整且包含存档的备份集
+*/
+
+package general
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupSetTimeFormat = "20060102150405" // 备份集文件夹名中的时间戳格式
+	partialSetSuffix    = ".partial"       // 未完成的备份集文件夹后缀
+	setTokenLength      = 4                // 备份集文件夹名中随机后缀的字节数
+)
+
+// BackupSet 一个完整的备份集
+type BackupSet struct {
+	Name  string    // 文件夹名，即存储中的路径
+	Path  string    // 文件夹的完整位置
+	Time  time.Time // 创建时间
+	Empty bool      // 是否不包含任何存档
+}
+
+// RetentionPolicy 备份集的保留策略，各规则保留的备份集取并集
+type RetentionPolicy struct {
+	Last    int // 保留最新的 N 个备份集
+	Daily   int // 保留最近 N 天中每天最新的备份集
+	Weekly  int // 保留最近 N 周中每周最新的备份集
+	Monthly int // 保留最近 N 个月中每月最新的备份集
+}
+
+// Enabled 判断是否设置了保留策略
+func (policy RetentionPolicy) Enabled() bool {
+	return policy.Last > 0 || policy.Daily > 0 || policy.Weekly > 0 || policy.Monthly > 0
+}
+
+// NewBackupSet 在存储中创建本次运行的备份集文件夹
+//
+// 参数：
+//   - storage: 保存存档的存储
+//   - kind: 备份集类型，'image' 或 'volume'
+//
+// 返回：
+//   - 未完成的备份集文件夹在存储中的路径
+//   - 错误信息
+func NewBackupSet(storage Storage, kind string) (string, error) {
+	token := make([]byte, setTokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s-%s", kind, GetCurrentTimestamp(backupSetTimeFormat), hex.EncodeToString(token))
+	if _, err := storage.Stat(name + partialSetSuffix); err == nil {
+		return "", fmt.Errorf("backup set %s already exists", name)
+	} else if !isNotExist(err) {
+		return "", err
+	}
+	if _, err := storage.Stat(name); err == nil {
+		return "", fmt.Errorf("backup set %s already exists", name)
+	} else if !isNotExist(err) {
+		return "", err
+	}
+	setName := name + partialSetSuffix
+	if err := storage.MakeFolder(setName); err != nil {
+		return "", err
+	}
+	return setName, nil
+}
+
+// CompleteBackupSet 将备份集标记为完整
+//
+// 参数：
+//   - storage: 保存存档的存储
+//   - setName: 未完成的备份集文件夹在存储中的路径
+//
+// 返回：
+//   - 完整的备份集文件夹的完整位置
+//   - 错误信息
+func CompleteBackupSet(storage Storage, setName string) (string, error) {
+	completeName := CompletedSetPath(setName)
+	return storage.Location(completeName), storage.Rename(setName, completeName)
+}
+
+// CompletedSetPath 返回未完成的备份集完成后的文件夹路径
+//
+// 参数：
+//   - setPath: 未完成的备份集文件夹路径
+//
+// 返回：
+//   - 完整的备份集文件夹路径
+func CompletedSetPath(setPath string) string {
+	return strings.TrimSuffix(setPath, partialSetSuffix)
+}
+
+// ListBackupSets 列出存储中指定类型的完整备份集
+//
+// 参数：
+//   - storage: 保存存档的存储
+//   - kind: 备份集类型，'image' 或 'volume'
+//
+// 返回：
+//   - 备份集，按时间从新到旧排序
+//   - 错误信息
+func ListBackupSets(storage Storage, kind string) ([]BackupSet, error) {
+	entries, err := storage.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []BackupSet
+	for _, entry := range entries {
+		setTime, ok := parseBackupSetName(entry.Name, kind)
+		if !entry.IsDir || !ok {
+			continue
+		}
+		archives, err := storage.List(entry.Name)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, BackupSet{Name: entry.Name, Path: storage.Location(entry.Name), Time: setTime, Empty: len(archives) == 0})
+	}
+
+	sort.SliceStable(sets, func(i, j int) bool { return sets[i].Time.After(sets[j].Time) })
+	return sets, nil
+}
+
+// parseBackupSetName 解析完整的备份集文件夹名
+//
+//   - 文件夹名为 '<kind>-<时间戳>' 或 '<kind>-<时间戳>-<随机后缀>'
+//
+// 参数：
+//   - name: 文件夹名
+//   - kind: 备份集类型
+//
+// 返回：
+//   - 备份集的创建时间
+//   - 是否是指定类型的完整备份集
+func parseBackupSetName(name string, kind string) (time.Time, bool) {
+	rest, found := strings.CutPrefix(name, kind+"-")
+	if !found || len(rest) < len(backupSetTimeFormat) {
+		return time.Time{}, false
+	}
+	timestamp, token := rest[:len(backupSetTimeFormat)], rest[len(backupSetTimeFormat):]
+	if token != "" {
+		if token, found = strings.CutPrefix(token, "-"); !found {
+			return time.Time{}, false
+		}
+		if decoded, err := hex.DecodeString(token); err != nil || len(decoded) != setTokenLength {
+			return time.Time{}, false
+		}
+	}
+	setTime, err := time.ParseInLocation(backupSetTimeFormat, timestamp, time.Local)
+	return setTime, err == nil
+}
+
+// ExpiredBackupSets 按保留策略找出需要删除的备份集
+//
+//   - 未设置保留策略时不删除任何备份集
+//   - 不包含存档的备份集既不计入保留数量也不会被删除，不会挤掉包含存档的备份集
+//
+// 参数：
+//   - sets: 备份集，按时间从新到旧排序
+//   - policy: 保留策略
+//
+// 返回：
+//   - 需要删除的备份集
+func ExpiredBackupSets(sets []BackupSet, policy RetentionPolicy) []BackupSet {
+	if !policy.Enabled() {
+		return nil
+	}
+
+	// 只处理包含存档的备份集
+	var filled []BackupSet
+	for _, set := range sets {
+		if !set.Empty {
+			filled = append(filled, set)
+		}
+	}
+	sets = filled
+
+	keep := make(map[string]bool)
+	for index, set := range sets {
+		if index < policy.Last {
+			keep[set.Name] = true
+		}
+	}
+
+	// 每个时间段保留最新的备份集，直到保留了 count 个时间段
+	keepPeriods := func(count int, period func(time.Time) string) {
+		seen := make(map[string]bool)
+		for _, set := range sets {
+			if len(seen) >= count {
+				break
+			}
+			key := period(set.Time)
+			if !seen[key] {
+				seen[key] = true
+				keep[set.Name] = true
+			}
+		}
+	}
+	keepPeriods(policy.Daily, func(setTime time.Time) string { return setTime.Format("2006-01-02") })
+	keepPeriods(policy.Weekly, func(setTime time.Time) string {
+		year, week := setTime.ISOWeek()
+		return fmt.Sprintf("%d-%02d", year, week)
+	})
+	keepPeriods(policy.Monthly, func(setTime time.Time) string { return setTime.Format("2006-01") })
+
+	var expired []BackupSet
+	for _, set := range sets {
+		if !keep[set.Name] {
+			expired = append(expired, set)
+		}
+	}
+	return expired
+}
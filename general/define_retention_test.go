@@ -0,0 +1,128 @@
+/*
+File: define_retention_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 12:41:09
+
+Description: 备份集保留策略测试
+*/
+
+package general
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// setNames 返回备份集的文件夹名
+func setNames(sets []BackupSet) []string {
+	var names []string
+	for _, set := range sets {
+		names = append(names, set.Name)
+	}
+	return names
+}
+
+func TestExpiredBackupSetsIgnoresEmptySets(t *testing.T) {
+	day := func(days int, hour int) time.Time {
+		return time.Date(2026, 10, 20-days, hour, 0, 0, 0, time.Local)
+	}
+	// 按时间从新到旧排序，最新的几个是没有选中任何对象的运行留下的空备份集
+	sets := []BackupSet{
+		{Name: "empty-1", Time: day(0, 12), Empty: true},
+		{Name: "empty-2", Time: day(0, 11), Empty: true},
+		{Name: "empty-3", Time: day(1, 12), Empty: true},
+		{Name: "full-1", Time: day(1, 10)},
+		{Name: "full-2", Time: day(2, 10)},
+		{Name: "full-3", Time: day(3, 10)},
+	}
+
+	tests := []struct {
+		name    string
+		policy  RetentionPolicy
+		expired []string
+	}{
+		{"keep last", RetentionPolicy{Last: 2}, []string{"full-3"}},
+		{"keep daily", RetentionPolicy{Daily: 2}, []string{"full-3"}},
+		{"keep monthly", RetentionPolicy{Monthly: 1}, []string{"full-2", "full-3"}},
+		{"keep last one", RetentionPolicy{Last: 1}, []string{"full-2", "full-3"}},
+		{"no policy", RetentionPolicy{}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expired := setNames(ExpiredBackupSets(sets, test.policy))
+			if len(expired) != len(test.expired) {
+				t.Fatalf("ExpiredBackupSets(%+v) = %v, want %v", test.policy, expired, test.expired)
+			}
+			for index := range expired {
+				if expired[index] != test.expired[index] {
+					t.Fatalf("ExpiredBackupSets(%+v) = %v, want %v", test.policy, expired, test.expired)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBackupSetName(t *testing.T) {
+	tests := map[string]bool{
+		"image-20261020120000":                  true,
+		"image-20261020120000-3f9a0c1e":         true,
+		"image-20261020120000-3f9a0c1e.partial": false,
+		"image-20261020120000.partial":          false,
+		"image-20261020120000-other":            false,
+		"volume-20261020120000":                 false,
+		"image-2026102012":                      false,
+	}
+	for name, want := range tests {
+		if _, ok := parseBackupSetName(name, "image"); ok != want {
+			t.Errorf("parseBackupSetName(%q) = %v, want %v", name, ok, want)
+		}
+	}
+}
+
+func TestNewBackupSetIsUnique(t *testing.T) {
+	storage, err := OpenStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+
+	// 同一秒内开始的运行使用不同的文件夹
+	first, err := NewBackupSet(storage, "image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewBackupSet(storage, "image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatalf("NewBackupSet returned %s twice", first)
+	}
+	if _, ok := parseBackupSetName(CompletedSetPath(first), "image"); !ok {
+		t.Errorf("completed name of %s is not a backup set name", first)
+	}
+
+	// 完成后列出，只有写入了存档的备份集不是空的
+	if err := storage.Put(second+"/app.dockerimage", strings.NewReader("archive")); err != nil {
+		t.Fatal(err)
+	}
+	for _, setName := range []string{first, second} {
+		if _, err := CompleteBackupSet(storage, setName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sets, err := ListBackupSets(storage, "image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("ListBackupSets = %v, want 2 sets", setNames(sets))
+	}
+	for _, set := range sets {
+		if want := set.Name == CompletedSetPath(first); set.Empty != want {
+			t.Errorf("set %s Empty = %v, want %v", set.Name, set.Empty, want)
+		}
+	}
+}
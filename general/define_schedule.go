@@ -0,0 +1,231 @@
+/*
+File: define_schedule.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 21:02:45
+
+Description: 定时任务的定义、锁和运行记录
+
+Notice:
+	- 任务定义在配置文件的 '[jobs.<name>]' 中
+	- 锁文件、状态文件和历史记录保存在 $XDG_STATE_HOME/wocker 中，默认 ~/.local/state/wocker
+*/
+
+package general
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	statusFileName  = "status.json" // 每个任务最近一次运行结果
+	historyFileName = "history.log" // 所有运行结果，每行一个 JSON
+	statusLockName  = "status.lock" // 状态文件和历史记录的锁
+	lockFolderName  = "locks"       // 锁文件夹
+)
+
+// 任务运行结果
+const (
+	JobSucceeded = "succeeded" // 运行成功
+	JobFailed    = "failed"    // 运行失败
+	JobSkipped   = "skipped"   // 上次运行尚未结束，跳过
+)
+
+// ErrJobLocked 任务正在运行
+var ErrJobLocked = errors.New("job is already running")
+
+// errLockBusy 锁被其他持有者占用
+var errLockBusy = errors.New("lock is held by another process")
+
+// Job 定时任务，保存选中的 image 或 volume 到带时间戳的备份集
+type Job struct {
+	Kind         string   `toml:"kind" comment:"'image' or 'volume'"`
+	Names        []string `toml:"names" comment:"names or patterns to save, 'all' for everything"`
+	Exclude      []string `toml:"exclude,omitempty"`
+	Regex        bool     `toml:"regex,omitempty"`
+	Schedule     string   `toml:"schedule" comment:"cron expression, for example: '30 3 * * *' or '@daily'"`
+	Context      string   `toml:"context,omitempty" comment:"docker context to back up, empty for the current one"`
+	OutputDir    string   `toml:"output_dir,omitempty" comment:"folder of the backup sets, empty for the 'output_dir' setting"`
+	NameTemplate string   `toml:"name_template,omitempty"`
+	Format       string   `toml:"format,omitempty" comment:"archive format of images, 'docker', 'oci' or 'oci-archive'"`
+	Platform     string   `toml:"platform,omitempty"`
+	KeepLast     int      `toml:"keep_last,omitempty"`
+	KeepDaily    int      `toml:"keep_daily,omitempty"`
+	KeepWeekly   int      `toml:"keep_weekly,omitempty"`
+	KeepMonthly  int      `toml:"keep_monthly,omitempty"`
+}
+
+// JobStatus 任务的一次运行结果
+type JobStatus struct {
+	Job      string    `json:"job"`               // 任务名
+	Start    time.Time `json:"start"`             // 开始时间
+	Duration string    `json:"duration"`          // 耗时
+	Result   string    `json:"result"`            // 运行结果
+	Message  string    `json:"message,omitempty"` // 附加信息
+}
+
+// Validate 检查任务定义
+//
+// 返回：
+//   - 错误信息
+func (job Job) Validate() error {
+	if job.Kind != "image" && job.Kind != "volume" {
+		return fmt.Errorf("kind must be 'image' or 'volume', got %q", job.Kind)
+	}
+	if len(job.Names) == 0 {
+		return errors.New("names must not be empty")
+	}
+	if _, err := ParseSchedule(job.Schedule); err != nil {
+		return err
+	}
+	if job.NameTemplate != "" && job.Kind == "image" {
+		sample := ImageNameData{Repo: "repo", Tag: "tag", ID: "0123456789ab", Date: "20060102", Time: "150405"}
+		if _, err := RenderName(job.NameTemplate, sample); err != nil {
+			return err
+		}
+	}
+	if job.NameTemplate != "" && job.Kind == "volume" {
+		if err := CheckVolumeTemplate(job.NameTemplate, "volume"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Retention 返回任务的保留策略
+func (job Job) Retention() RetentionPolicy {
+	return RetentionPolicy{Last: job.KeepLast, Daily: job.KeepDaily, Weekly: job.KeepWeekly, Monthly: job.KeepMonthly}
+}
+
+// StateDir 返回保存运行状态的文件夹
+//
+// 返回：
+//   - 文件夹路径
+func StateDir() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, _ := os.UserHomeDir()
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, strings.ToLower(Name))
+}
+
+// LockJob 获取任务的锁，同一任务的多次运行不会重叠
+//
+//   - 锁由持有的文件描述符上的文件锁表示，进程退出时由系统释放，锁文件本身不删除
+//
+// 参数：
+//   - jobName: 任务名
+//
+// 返回：
+//   - 释放锁的函数
+//   - 错误信息，任务正在运行时返回 ErrJobLocked
+func LockJob(jobName string) (func(), error) {
+	lockFolder := filepath.Join(StateDir(), lockFolderName)
+	if err := CreateFolder(lockFolder); err != nil {
+		return nil, err
+	}
+	unlock, err := acquireLock(filepath.Join(lockFolder, SanitizeFileName(jobName)+".lock"), false)
+	if errors.Is(err, errLockBusy) {
+		return nil, ErrJobLocked
+	}
+	return unlock, err
+}
+
+// acquireLock 打开锁文件并加排它锁
+//
+// 参数：
+//   - lockPath: 锁文件路径
+//   - wait: 是否等待其他持有者释放锁
+//
+// 返回：
+//   - 释放锁的函数
+//   - 错误信息，不等待且锁被占用时返回 errLockBusy
+func acquireLock(lockPath string, wait bool) (func(), error) {
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(file, wait); err != nil {
+		file.Close()
+		return nil, err
+	}
+	// 记录持有者的进程 ID，仅供排查
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	return func() { file.Close() }, nil
+}
+
+// RecordJobStatus 记录任务的运行结果
+//
+//   - 更新状态文件中该任务的最近一次结果，并追加到历史记录
+//   - 读取、修改和写入期间持有状态锁，不同任务同时结束时不会丢失结果
+//
+// 参数：
+//   - status: 运行结果
+//
+// 返回：
+//   - 错误信息
+func RecordJobStatus(status JobStatus) error {
+	stateDir := StateDir()
+	if err := CreateFolder(stateDir); err != nil {
+		return err
+	}
+	unlock, err := acquireLock(filepath.Join(stateDir, statusLockName), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	statuses, err := ReadJobStatus()
+	if err != nil {
+		return err
+	}
+	statuses[status.Job] = status
+	content, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 先写入临时文件再替换，避免读取到不完整的状态文件
+	statusFile := filepath.Join(stateDir, statusFileName)
+	if err := os.WriteFile(statusFile+".tmp", content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(statusFile+".tmp", statusFile); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return WriteFileWithNewLine(filepath.Join(stateDir, historyFileName), string(line), "a")
+}
+
+// ReadJobStatus 读取每个任务最近一次的运行结果
+//
+// 返回：
+//   - 任务名对应的运行结果
+//   - 错误信息
+func ReadJobStatus() (map[string]JobStatus, error) {
+	statuses := make(map[string]JobStatus)
+	content, err := os.ReadFile(filepath.Join(StateDir(), statusFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return statuses, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
@@ -0,0 +1,70 @@
+/*
+File: define_schedule_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 13:31:52
+
+Description: 定时任务锁和运行记录测试
+*/
+
+package general
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockJob(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	unlock, err := LockJob("nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LockJob("nightly"); !errors.Is(err, ErrJobLocked) {
+		t.Fatalf("second LockJob error = %v, want ErrJobLocked", err)
+	}
+	// 其他任务不受影响
+	unlockOther, err := LockJob("weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockOther()
+
+	// 释放后留下的锁文件不会阻止再次获取
+	unlock()
+	unlock, err = LockJob("nightly")
+	if err != nil {
+		t.Fatalf("LockJob after unlock: %v", err)
+	}
+	unlock()
+}
+
+func TestRecordJobStatusConcurrently(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	const jobs = 8
+	var wait sync.WaitGroup
+	for index := 0; index < jobs; index++ {
+		wait.Add(1)
+		go func(index int) {
+			defer wait.Done()
+			status := JobStatus{Job: fmt.Sprintf("job-%d", index), Start: time.Now(), Result: JobSucceeded}
+			if err := RecordJobStatus(status); err != nil {
+				t.Error(err)
+			}
+		}(index)
+	}
+	wait.Wait()
+
+	statuses, err := ReadJobStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != jobs {
+		t.Fatalf("ReadJobStatus returned %d jobs, want %d", len(statuses), jobs)
+	}
+}
@@ -0,0 +1,264 @@
+/*
+File: define_split.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 02:03:18
+
+Description: 将存档拆分为大小受限的分卷
+
+Notice:
+	- 分卷与存档位于同一文件夹，命名为 '<存档名>.part001'、'<存档名>.part002' ...，另有索引文件 '<存档名>.index'
+	- 加载时指定第一个分卷即可，其余分卷按索引依次读取并校验
+*/
+
+package general
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	firstPartSuffix  = ".part001" // 第一个分卷的后缀
+	splitIndexSuffix = ".index"   // 索引文件的后缀
+)
+
+// partSuffixPattern 分卷后缀
+var partSuffixPattern = regexp.MustCompile(`\.part\d{3,}$`)
+
+// SplitPart 一个分卷
+type SplitPart struct {
+	Name   string `json:"name"`   // 文件名，与索引文件位于同一文件夹
+	Size   int64  `json:"size"`   // 大小
+	Digest string `json:"digest"` // sha256 摘要，格式为 'sha256:<hex>'
+}
+
+// SplitIndex 分卷索引
+type SplitIndex struct {
+	PartSize int64       `json:"part_size"` // 分卷的最大大小
+	Size     int64       `json:"size"`      // 存档的总大小
+	Digest   string      `json:"digest"`    // 存档的 sha256 摘要
+	Parts    []SplitPart `json:"parts"`     // 分卷，按顺序排列
+}
+
+// splitPartName 返回分卷在存储中的路径
+func splitPartName(name string, number int) string {
+	return fmt.Sprintf("%s.part%03d", name, number)
+}
+
+// IsSplitArchive 判断路径是否是分卷存档的第一个分卷
+//
+// 参数：
+//   - location: 存档路径或 URL
+//
+// 返回：
+//   - 是否是第一个分卷
+func IsSplitArchive(location string) bool {
+	return strings.HasSuffix(location, firstPartSuffix)
+}
+
+// TrimPartSuffix 去掉分卷后缀，返回存档名
+//
+// 参数：
+//   - name: 分卷路径或存档路径
+//
+// 返回：
+//   - 存档路径
+func TrimPartSuffix(name string) string {
+	return partSuffixPattern.ReplaceAllString(name, "")
+}
+
+// StoredArchiveName 返回保存后用于加载存档的路径，设置了 split_size 时为第一个分卷
+//
+// 参数：
+//   - name: 存档在存储中的路径
+//
+// 返回：
+//   - 加载时使用的路径
+func StoredArchiveName(name string) string {
+	if CurrentSettings.SplitSize == "" {
+		return name
+	}
+	return name + firstPartSuffix
+}
+
+// byteCounter 统计写入的字节数
+type byteCounter int64
+
+func (counter *byteCounter) Write(data []byte) (int, error) {
+	*counter += byteCounter(len(data))
+	return len(data), nil
+}
+
+// PutSplit 将 content 拆分为分卷写入存储，最后写入索引
+//
+//   - 删除同一存档之前保存时留下的多余分卷
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存档在存储中的路径
+//   - content: 存档内容
+//   - partSize: 分卷的最大大小
+//
+// 返回：
+//   - 分卷索引
+//   - 错误信息
+func PutSplit(storage Storage, name string, content io.Reader, partSize int64) (SplitIndex, error) {
+	if partSize <= 0 {
+		return SplitIndex{}, fmt.Errorf("invalid part size: %d", partSize)
+	}
+	var (
+		index       = SplitIndex{PartSize: partSize}
+		reader      = bufio.NewReaderSize(content, 1<<20)
+		archiveHash = sha256.New()
+	)
+	for number := 1; ; number++ {
+		var (
+			partHash = sha256.New()
+			counter  byteCounter
+			partName = splitPartName(name, number)
+		)
+		partContent := io.TeeReader(io.LimitReader(reader, partSize), io.MultiWriter(partHash, archiveHash, &counter))
+		if err := storage.Put(partName, partContent); err != nil {
+			return index, err
+		}
+		index.Parts = append(index.Parts, SplitPart{Name: path.Base(partName), Size: int64(counter), Digest: fmt.Sprintf("sha256:%x", partHash.Sum(nil))})
+		index.Size += int64(counter)
+
+		// 没有剩余数据时结束
+		if _, err := reader.Peek(1); err == io.EOF {
+			break
+		} else if err != nil {
+			return index, err
+		}
+	}
+	index.Digest = fmt.Sprintf("sha256:%x", archiveHash.Sum(nil))
+
+	// 删除之前保存时留下的多余分卷
+	for number := len(index.Parts) + 1; ; number++ {
+		if _, err := storage.Stat(splitPartName(name, number)); err != nil {
+			break
+		}
+		storage.Remove(splitPartName(name, number))
+	}
+
+	indexContent, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return index, err
+	}
+	return index, storage.Put(name+splitIndexSuffix, bytes.NewReader(indexContent))
+}
+
+// readSplitIndex 读取分卷索引
+//
+// 参数：
+//   - storage: 存储
+//   - firstPart: 第一个分卷在存储中的路径
+//
+// 返回：
+//   - 分卷索引
+//   - 错误信息
+func readSplitIndex(storage Storage, firstPart string) (SplitIndex, error) {
+	var index SplitIndex
+	indexName := TrimPartSuffix(firstPart) + splitIndexSuffix
+	reader, err := storage.Get(indexName)
+	if err != nil {
+		return index, err
+	}
+	defer reader.Close()
+
+	if err := json.NewDecoder(reader).Decode(&index); err != nil {
+		return index, fmt.Errorf("%s: %w", storage.Location(indexName), err)
+	}
+	return index, nil
+}
+
+// OpenSplit 按索引依次读取分卷
+//
+// 参数：
+//   - storage: 存储
+//   - firstPart: 第一个分卷在存储中的路径
+//
+// 返回：
+//   - 存档内容
+//   - 错误信息
+func OpenSplit(storage Storage, firstPart string) (io.ReadCloser, error) {
+	index, err := readSplitIndex(storage, firstPart)
+	if err != nil {
+		return nil, err
+	}
+	return &splitReader{storage: storage, folder: path.Dir(firstPart), index: index, archiveHash: sha256.New()}, nil
+}
+
+// splitReader 按索引依次读取分卷并校验
+type splitReader struct {
+	storage     Storage
+	folder      string        // 分卷所在文件夹在存储中的路径
+	index       SplitIndex    // 分卷索引
+	next        int           // 下一个打开的分卷在索引中的位置
+	current     io.ReadCloser // 正在读取的分卷
+	partHash    hash.Hash     // 正在读取的分卷的摘要
+	partSize    int64         // 正在读取的分卷已读取的大小
+	archiveHash hash.Hash     // 已读取数据的摘要
+}
+
+func (reader *splitReader) Read(data []byte) (int, error) {
+	for {
+		if reader.current == nil {
+			if reader.next == len(reader.index.Parts) {
+				if digest := fmt.Sprintf("sha256:%x", reader.archiveHash.Sum(nil)); digest != reader.index.Digest {
+					return 0, fmt.Errorf("split archive digest %s does not match %s", digest, reader.index.Digest)
+				}
+				return 0, io.EOF
+			}
+			part, err := reader.storage.Get(path.Join(reader.folder, reader.index.Parts[reader.next].Name))
+			if err != nil {
+				return 0, err
+			}
+			reader.current, reader.partHash, reader.partSize = part, sha256.New(), 0
+		}
+
+		count, err := reader.current.Read(data)
+		reader.partHash.Write(data[:count])
+		reader.archiveHash.Write(data[:count])
+		reader.partSize += int64(count)
+		if err != io.EOF {
+			return count, err
+		}
+
+		// 当前分卷读取完毕，校验后切换到下一个分卷
+		part := reader.index.Parts[reader.next]
+		reader.current.Close()
+		reader.current = nil
+		reader.next++
+		if digest := fmt.Sprintf("sha256:%x", reader.partHash.Sum(nil)); reader.partSize != part.Size || digest != part.Digest {
+			return count, fmt.Errorf("%s: part is corrupted", reader.storage.Location(path.Join(reader.folder, part.Name)))
+		}
+		if count > 0 {
+			return count, nil
+		}
+	}
+}
+
+func (reader *splitReader) Close() error {
+	if reader.current != nil {
+		return reader.current.Close()
+	}
+	return nil
+}
+
+// splitSize 返回 split_size 设置的分卷大小，未设置时为 0
+func splitSize() (int64, error) {
+	if CurrentSettings.SplitSize == "" {
+		return 0, nil
+	}
+	return ParseSize(CurrentSettings.SplitSize)
+}
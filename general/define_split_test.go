@@ -0,0 +1,176 @@
+/*
+File: define_split_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 17:42:08
+
+Description: 分卷存档测试
+*/
+
+package general
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// putTestSplit 将 data 按 16 字节拆分为分卷保存到临时文件夹中的本地存储
+func putTestSplit(t *testing.T, data []byte) (Storage, string) {
+	t.Helper()
+	folder := t.TempDir()
+	storage, err := OpenStorage(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Close() })
+	if _, err := PutSplit(storage, "app.tar.gz", bytes.NewReader(data), 16); err != nil {
+		t.Fatal(err)
+	}
+	return storage, folder
+}
+
+// readTestSplit 从第一个分卷读取整个存档
+func readTestSplit(storage Storage) ([]byte, error) {
+	reader, err := OpenSplit(storage, "app.tar.gz"+firstPartSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func TestPutSplitRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("0123456789abcdef", 4)[:50])
+	storage, folder := putTestSplit(t, data)
+
+	got, err := readTestSplit(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("OpenSplit read %q, want %q", got, data)
+	}
+	index, err := readSplitIndex(storage, "app.tar.gz"+firstPartSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Parts) != 4 || index.Size != int64(len(data)) || index.Parts[3].Size != 2 {
+		t.Errorf("index = %+v, want 4 parts of 50 bytes", index)
+	}
+
+	// 再次保存时分卷变少，多余的分卷被删除
+	if _, err := PutSplit(storage, "app.tar.gz", bytes.NewReader(data[:20]), 16); err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{"app.tar.gz.part003", "app.tar.gz.part004"} {
+		if _, err := os.Stat(filepath.Join(folder, part)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed, got %v", part, err)
+		}
+	}
+	if got, err := readTestSplit(storage); err != nil || !bytes.Equal(got, data[:20]) {
+		t.Errorf("OpenSplit read %q, %v, want %q", got, err, data[:20])
+	}
+}
+
+func TestOpenSplitDetectsCorruption(t *testing.T) {
+	data := []byte(strings.Repeat("0123456789abcdef", 4)[:50])
+	tests := map[string]func(folder string) error{
+		"missing part": func(folder string) error {
+			return os.Remove(filepath.Join(folder, "app.tar.gz.part002"))
+		},
+		"truncated part": func(folder string) error {
+			return os.WriteFile(filepath.Join(folder, "app.tar.gz.part002"), data[16:24], 0644)
+		},
+		"part checksum mismatch": func(folder string) error {
+			return os.WriteFile(filepath.Join(folder, "app.tar.gz.part002"), bytes.ToUpper(data[16:32]), 0644)
+		},
+		"archive checksum mismatch": func(folder string) error {
+			indexFile := filepath.Join(folder, "app.tar.gz"+splitIndexSuffix)
+			content, err := os.ReadFile(indexFile)
+			if err != nil {
+				return err
+			}
+			// 只修改整个存档的摘要（索引中的第一个摘要），各分卷仍然有效
+			marker := `"digest": "sha256:`
+			start := bytes.Index(content, []byte(marker)) + len(marker)
+			copy(content[start:], strings.Repeat("0", 64))
+			return os.WriteFile(indexFile, content, 0644)
+		},
+		"missing index": func(folder string) error {
+			return os.Remove(filepath.Join(folder, "app.tar.gz"+splitIndexSuffix))
+		},
+	}
+	for name, corrupt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage, folder := putTestSplit(t, data)
+			if err := corrupt(folder); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := readTestSplit(storage); err == nil {
+				t.Errorf("OpenSplit read %q without error", got)
+			}
+		})
+	}
+}
+
+func TestPutSplitRejectsInvalidPartSize(t *testing.T) {
+	storage, err := OpenStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+	for _, partSize := range []int64{0, -1} {
+		if _, err := PutSplit(storage, "app.tar.gz", strings.NewReader("data"), partSize); err == nil {
+			t.Errorf("PutSplit with part size %d should fail", partSize)
+		}
+	}
+}
+
+func TestTrimPartSuffix(t *testing.T) {
+	tests := map[string]string{
+		"app.tar.gz.part001":              "app.tar.gz",
+		"s3://bucket/app.tar.gz.part1234": "s3://bucket/app.tar.gz",
+		"app.tar.gz":                      "app.tar.gz",
+		"app.part001.tar.gz":              "app.part001.tar.gz",
+		"app.tar.gz.part01":               "app.tar.gz.part01",
+	}
+	for name, want := range tests {
+		if got := TrimPartSuffix(name); got != want {
+			t.Errorf("TrimPartSuffix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"700":    700,
+		"1b":     1,
+		"4.7G":   4700000000,
+		"700MiB": 700 << 20,
+		" 2 kb ": 2000,
+		"0":      -1,
+		"0.5":    -1,
+		"0.1b":   -1,
+		"-1":     -1,
+		"-2GB":   -1,
+		"":       -1,
+		"10xb":   -1,
+		"1e30tb": -1,
+	}
+	for size, want := range tests {
+		got, err := ParseSize(size)
+		if want < 0 {
+			if err == nil {
+				t.Errorf("ParseSize(%q) = %d, want error", size, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", size, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+/*
+File: define_storage.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 23:12:40
+
+Description: 保存存档的存储位置
+
+Notice:
+	- 存储位置可以是本地文件夹，也可以是以下 URL：
+		- 's3://bucket/prefix'：S3 兼容的对象存储，地址由配置项 s3_endpoint 设置，凭据读取自 AWS_ACCESS_KEY_ID 等环境变量或 ~/.aws/credentials
+		- 'sftp://user@host:port/path'：SFTP 服务，使用 ssh-agent 或 ~/.ssh 中的密钥和 known_hosts，'/~/path' 表示用户主目录下的路径
+		- 'webdav://host/path' 或 'webdavs://host/path'：WebDAV 服务，使用 https，配置项 webdav_insecure 开启时 'webdav://' 使用 http，密码读取自 URL 或环境变量 WOCKER_WEBDAV_PASSWORD
+	- 存储中的路径使用 '/' 分隔，相对于存储位置
+*/
+
+package general
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StorageEntry 存储中的文件或文件夹
+type StorageEntry struct {
+	Name    string    // 相对于存储位置的路径
+	Size    int64     // 文件大小
+	IsDir   bool      // 是否是文件夹
+	ModTime time.Time // 修改时间
+	ETag    string    // 后端提供的内容标识，内容改变时随之改变，不提供时为空
+}
+
+// Storage 保存存档的存储
+//
+//   - 路径不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)
+type Storage interface {
+	// Put 将 content 写入文件，自动创建上级文件夹，写入失败时不留下不完整的文件
+	Put(name string, content io.Reader) error
+	// Get 读取文件
+	Get(name string) (io.ReadCloser, error)
+	// Stat 获取文件或文件夹的信息
+	Stat(name string) (StorageEntry, error)
+	// List 列出文件夹的直接子条目
+	List(folder string) ([]StorageEntry, error)
+	// MakeFolder 创建文件夹及其上级文件夹
+	MakeFolder(name string) error
+	// Rename 重命名文件或文件夹
+	Rename(oldName string, newName string) error
+	// Remove 删除文件，或递归删除文件夹
+	Remove(name string) error
+	// Location 返回路径的完整位置，用于显示和记录
+	Location(name string) string
+	// Close 断开连接
+	Close() error
+}
+
+// IsRemoteLocation 判断存储位置是否是远程存储的 URL
+//
+// 参数：
+//   - location: 存储位置
+//
+// 返回：
+//   - 是否是远程存储
+func IsRemoteLocation(location string) bool {
+	scheme, _, found := strings.Cut(location, "://")
+	if !found {
+		return false
+	}
+	switch scheme {
+	case "s3", "sftp", "webdav", "webdavs":
+		return true
+	}
+	return false
+}
+
+// OpenStorage 打开存储位置
+//
+// 参数：
+//   - location: 本地文件夹或远程存储的 URL
+//
+// 返回：
+//   - 存储
+//   - 错误信息
+func OpenStorage(location string) (Storage, error) {
+	if !IsRemoteLocation(location) {
+		return newLocalStorage(location)
+	}
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	switch locationURL.Scheme {
+	case "s3":
+		return newS3Storage(locationURL)
+	case "sftp":
+		return newSFTPStorage(locationURL)
+	default:
+		return newWebDAVStorage(locationURL)
+	}
+}
+
+// splitLocation 将文件的完整位置拆分为存储位置和存储中的路径
+//
+//   - 本地文件的存储位置为其所在文件夹
+//   - S3 的存储位置为 bucket，其他远程存储为服务的根路径
+//
+// 参数：
+//   - location: 文件的完整位置
+//
+// 返回：
+//   - 存储位置
+//   - 存储中的路径
+//   - 错误信息
+func splitLocation(location string) (string, string, error) {
+	if !IsRemoteLocation(location) {
+		return filepath.Dir(location), filepath.Base(location), nil
+	}
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return "", "", err
+	}
+	name := strings.TrimPrefix(locationURL.Path, "/")
+	root := &url.URL{Scheme: locationURL.Scheme, User: locationURL.User, Host: locationURL.Host, Path: "/"}
+	if locationURL.Scheme == "s3" {
+		root.Path = ""
+	}
+	// sftp 的 '/~/' 表示用户主目录，需要保留在存储位置中
+	if locationURL.Scheme == "sftp" && strings.HasPrefix(name, "~/") {
+		root.Path, name = "/~/", strings.TrimPrefix(name, "~/")
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("%s: missing file path", location)
+	}
+	return root.String(), name, nil
+}
+
+// OpenLocation 打开本地文件或远程存储中的文件
+//
+//   - 分块保存的存档和分卷存档在读取时自动拼接，分卷存档指定第一个分卷
+//
+// 参数：
+//   - location: 本地文件路径或远程文件的 URL
+//
+// 返回：
+//   - 文件内容，关闭时同时断开远程存储
+//   - 错误信息
+func OpenLocation(location string) (io.ReadCloser, error) {
+	if !IsRemoteLocation(location) && !IsChunkedArchive(location) && !IsSplitArchive(location) {
+		return os.Open(location)
+	}
+	root, name, err := splitLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := OpenStorage(root)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := openStorageArchive(storage, name)
+	if err != nil {
+		storage.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, closerFunc(func() error {
+		err := reader.Close()
+		storage.Close()
+		return err
+	})}, nil
+}
+
+// FetchLocation 返回可在本地访问的存档文件路径，远程文件、分块保存的存档和分卷存档先拼接到临时文件夹
+//
+// 参数：
+//   - location: 本地文件路径或远程文件的 URL
+//
+// 返回：
+//   - 本地文件的绝对路径，远程文件保留原文件名，分卷存档使用去掉分卷后缀的文件名
+//   - 清理临时文件的函数
+//   - 错误信息
+func FetchLocation(location string) (string, func(), error) {
+	if !IsRemoteLocation(location) && !IsChunkedArchive(location) && !IsSplitArchive(location) {
+		filePath, err := filepath.Abs(location)
+		return filePath, func() {}, err
+	}
+	reader, err := OpenLocation(location)
+	if err != nil {
+		return "", nil, err
+	}
+	defer reader.Close()
+
+	tempDir, err := os.MkdirTemp("", "wocker-fetch-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+	filePath := filepath.Join(tempDir, TrimPartSuffix(path.Base(location)))
+	file, err := os.Create(filePath)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return filePath, cleanup, nil
+}
+
+// LocalPath 返回本地存储中路径对应的本地文件路径
+//
+// 参数：
+//   - storage: 存储
+//   - name: 存储中的路径
+//
+// 返回：
+//   - 本地文件路径
+//   - 是否是本地存储
+func LocalPath(storage Storage, name string) (string, bool) {
+	local, ok := storage.(*localStorage)
+	if !ok {
+		return "", false
+	}
+	return local.path(name), true
+}
+
+// ArchiveChecker 检查存档是否存在，按存储位置复用连接
+type ArchiveChecker struct {
+	storages map[string]Storage
+}
+
+// NewArchiveChecker 创建存档检查器，使用完毕后需要关闭
+func NewArchiveChecker() *ArchiveChecker {
+	return &ArchiveChecker{storages: make(map[string]Storage)}
+}
+
+// Exists 判断存档是否存在，无法连接远程存储时视为存在
+//
+// 参数：
+//   - location: 存档的完整位置
+//
+// 返回：
+//   - 是否存在
+func (checker *ArchiveChecker) Exists(location string) bool {
+	if !IsRemoteLocation(location) {
+		return FileExist(location)
+	}
+	root, name, err := splitLocation(location)
+	if err != nil {
+		return false
+	}
+	storage, ok := checker.storages[root]
+	if !ok {
+		if storage, err = OpenStorage(root); err != nil {
+			return true
+		}
+		checker.storages[root] = storage
+	}
+	_, err = storage.Stat(name)
+	return !isNotExist(err)
+}
+
+// Close 断开所有远程存储
+func (checker *ArchiveChecker) Close() {
+	for _, storage := range checker.storages {
+		storage.Close()
+	}
+}
+
+// isNotExist 判断错误是否表示路径不存在
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// storagePath 规范化存储中的路径，不允许跳出存储位置
+//
+// 参数：
+//   - name: 存储中的路径
+//
+// 返回：
+//   - 规范化后的路径，存储位置本身为空
+func storagePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
+
+// joinStoragePath 拼接远程存储的根路径和存储中的路径
+func joinStoragePath(root string, name string) string {
+	return path.Join(root, storagePath(name))
+}
+
+// localStorage 本地文件夹
+type localStorage struct {
+	root string // 文件夹绝对路径
+}
+
+// newLocalStorage 打开本地文件夹，不存在时创建
+func newLocalStorage(folderPath string) (*localStorage, error) {
+	root, err := filepath.Abs(folderPath)
+	if err != nil {
+		return nil, err
+	}
+	return &localStorage{root: root}, CreateFolder(root)
+}
+
+// path 返回存储中的路径对应的本地路径
+func (storage *localStorage) path(name string) string {
+	return filepath.Join(storage.root, filepath.FromSlash(storagePath(name)))
+}
+
+func (storage *localStorage) Put(name string, content io.Reader) error {
+	filePath := storage.path(name)
+	if err := CreateFolder(filepath.Dir(filePath)); err != nil {
+		return err
+	}
+	// 先写入同一文件夹中的临时文件，完成后再替换
+	file, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), filePath); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+func (storage *localStorage) Get(name string) (io.ReadCloser, error) {
+	return os.Open(storage.path(name))
+}
+
+func (storage *localStorage) Stat(name string) (StorageEntry, error) {
+	info, err := os.Stat(storage.path(name))
+	if err != nil {
+		return StorageEntry{}, err
+	}
+	return StorageEntry{Name: storagePath(name), Size: info.Size(), IsDir: info.IsDir(), ModTime: info.ModTime()}, nil
+}
+
+func (storage *localStorage) List(folder string) ([]StorageEntry, error) {
+	entries, err := os.ReadDir(storage.path(folder))
+	if err != nil {
+		return nil, err
+	}
+	var storageEntries []StorageEntry
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		storageEntries = append(storageEntries, StorageEntry{
+			Name:    path.Join(storagePath(folder), entry.Name()),
+			Size:    info.Size(),
+			IsDir:   entry.IsDir(),
+			ModTime: info.ModTime(),
+		})
+	}
+	return storageEntries, nil
+}
+
+func (storage *localStorage) MakeFolder(name string) error {
+	return CreateFolder(storage.path(name))
+}
+
+func (storage *localStorage) Rename(oldName string, newName string) error {
+	return os.Rename(storage.path(oldName), storage.path(newName))
+}
+
+func (storage *localStorage) Remove(name string) error {
+	filePath := storage.path(name)
+	if _, err := os.Stat(filePath); err != nil {
+		return err
+	}
+	return os.RemoveAll(filePath)
+}
+
+func (storage *localStorage) Location(name string) string {
+	return storage.path(name)
+}
+
+func (storage *localStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+File: define_storage_s3.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 23:36:05
+
+Description: S3 兼容的对象存储
+
+Notice:
+	- 对象存储没有文件夹，文件夹即对象名的前缀，重命名文件夹需要逐个复制对象
+*/
+
+package general
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3PartSize 分段上传的分段大小，上传时每个并发分段占用一份内存
+const s3PartSize = 64 << 20
+
+// s3Storage S3 兼容的对象存储
+type s3Storage struct {
+	client *minio.Client
+	bucket string // bucket 名
+	prefix string // 存储位置在 bucket 中的前缀，不以 '/' 结尾
+}
+
+// newS3Storage 连接 S3 兼容的对象存储
+//
+// 参数：
+//   - locationURL: 's3://bucket/prefix'
+//
+// 返回：
+//   - 存储
+//   - 错误信息
+func newS3Storage(locationURL *url.URL) (*s3Storage, error) {
+	if locationURL.Host == "" {
+		return nil, fmt.Errorf("%s: missing bucket name", locationURL)
+	}
+	// 凭据依次读取自 AWS 和 MinIO 的环境变量、~/.aws/credentials
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.EnvMinio{},
+		&credentials.FileAWSCredentials{},
+	})
+	client, err := minio.New(CurrentSettings.S3Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !CurrentSettings.S3Insecure,
+		Region: CurrentSettings.S3Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, locationURL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: bucket %s: %w", CurrentSettings.S3Endpoint, locationURL.Host, fs.ErrNotExist)
+	}
+	return &s3Storage{client: client, bucket: locationURL.Host, prefix: storagePath(locationURL.Path)}, nil
+}
+
+// key 返回存储中的路径对应的对象名
+func (storage *s3Storage) key(name string) string {
+	return strings.TrimPrefix(joinStoragePath(storage.prefix, name), "/")
+}
+
+// convertError 将对象不存在的错误转换为 fs.ErrNotExist
+func (storage *s3Storage) convertError(name string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return &fs.PathError{Op: "s3", Path: storage.Location(name), Err: fs.ErrNotExist}
+	}
+	return err
+}
+
+func (storage *s3Storage) Put(name string, content io.Reader) error {
+	// 大小未知时分段上传，上传失败时对象不会出现
+	_, err := storage.client.PutObject(ctx, storage.bucket, storage.key(name), content, -1, minio.PutObjectOptions{PartSize: s3PartSize})
+	return err
+}
+
+func (storage *s3Storage) Get(name string) (io.ReadCloser, error) {
+	// GetObject 在第一次读取时才发送请求，先检查对象是否存在
+	if _, err := storage.client.StatObject(ctx, storage.bucket, storage.key(name), minio.StatObjectOptions{}); err != nil {
+		return nil, storage.convertError(name, err)
+	}
+	return storage.client.GetObject(ctx, storage.bucket, storage.key(name), minio.GetObjectOptions{})
+}
+
+func (storage *s3Storage) Stat(name string) (StorageEntry, error) {
+	info, err := storage.client.StatObject(ctx, storage.bucket, storage.key(name), minio.StatObjectOptions{})
+	if err == nil {
+		return StorageEntry{Name: storagePath(name), Size: info.Size, ModTime: info.LastModified, ETag: info.ETag}, nil
+	}
+	if err = storage.convertError(name, err); !isNotExist(err) {
+		return StorageEntry{}, err
+	}
+
+	// 存在以该路径为前缀的对象时视为文件夹
+	objects, err := storage.objects(name, false)
+	if err != nil {
+		return StorageEntry{}, err
+	}
+	if len(objects) == 0 {
+		return StorageEntry{}, &fs.PathError{Op: "stat", Path: storage.Location(name), Err: fs.ErrNotExist}
+	}
+	return StorageEntry{Name: storagePath(name), IsDir: true}, nil
+}
+
+func (storage *s3Storage) List(folder string) ([]StorageEntry, error) {
+	objects, err := storage.objects(folder, false)
+	if err != nil {
+		return nil, err
+	}
+	var entries []StorageEntry
+	for _, object := range objects {
+		entry := StorageEntry{
+			Name:    path.Join(storagePath(folder), path.Base(object.Key)),
+			Size:    object.Size,
+			IsDir:   strings.HasSuffix(object.Key, "/"),
+			ModTime: object.LastModified,
+			ETag:    object.ETag,
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// objects 列出文件夹中的对象
+//
+// 参数：
+//   - folder: 存储中的文件夹路径
+//   - recursive: 是否包括子文件夹中的对象，否则子文件夹作为以 '/' 结尾的条目返回
+//
+// 返回：
+//   - 对象信息
+//   - 错误信息
+func (storage *s3Storage) objects(folder string, recursive bool) ([]minio.ObjectInfo, error) {
+	prefix := storage.key(folder)
+	if prefix != "" {
+		prefix += "/"
+	}
+	var objects []minio.ObjectInfo
+	for object := range storage.client.ListObjects(ctx, storage.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: recursive}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (storage *s3Storage) MakeFolder(name string) error {
+	return nil
+}
+
+func (storage *s3Storage) Rename(oldName string, newName string) error {
+	entry, err := storage.Stat(oldName)
+	if err != nil {
+		return err
+	}
+	if !entry.IsDir {
+		return storage.moveObject(storage.key(oldName), storage.key(newName))
+	}
+
+	// 逐个移动文件夹中的对象
+	objects, err := storage.objects(oldName, true)
+	if err != nil {
+		return err
+	}
+	oldPrefix, newPrefix := storage.key(oldName)+"/", storage.key(newName)+"/"
+	for _, object := range objects {
+		if err := storage.moveObject(object.Key, newPrefix+strings.TrimPrefix(object.Key, oldPrefix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveObject 在服务端复制对象后删除原对象
+func (storage *s3Storage) moveObject(oldKey string, newKey string) error {
+	// ComposeObject 支持复制超过 5 GiB 的对象
+	destination := minio.CopyDestOptions{Bucket: storage.bucket, Object: newKey}
+	source := minio.CopySrcOptions{Bucket: storage.bucket, Object: oldKey}
+	if _, err := storage.client.ComposeObject(ctx, destination, source); err != nil {
+		return err
+	}
+	return storage.client.RemoveObject(ctx, storage.bucket, oldKey, minio.RemoveObjectOptions{})
+}
+
+func (storage *s3Storage) Remove(name string) error {
+	entry, err := storage.Stat(name)
+	if err != nil {
+		return err
+	}
+	if !entry.IsDir {
+		return storage.client.RemoveObject(ctx, storage.bucket, storage.key(name), minio.RemoveObjectOptions{})
+	}
+
+	objects, err := storage.objects(name, true)
+	if err != nil {
+		return err
+	}
+	objectsCh := make(chan minio.ObjectInfo, len(objects))
+	for _, object := range objects {
+		objectsCh <- object
+	}
+	close(objectsCh)
+	// 读完所有结果，只返回第一个错误
+	var firstErr error
+	for removeErr := range storage.client.RemoveObjects(ctx, storage.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		if firstErr == nil {
+			firstErr = removeErr.Err
+		}
+	}
+	return firstErr
+}
+
+func (storage *s3Storage) Location(name string) string {
+	return "s3://" + path.Join(storage.bucket, storage.key(name))
+}
+
+func (storage *s3Storage) Close() error {
+	return nil
+}
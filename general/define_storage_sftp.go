@@ -0,0 +1,333 @@
+/*
+File: define_storage_sftp.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 00:24:47
+
+Description: SFTP 存储
+
+Notice:
+	- 通过 ssh 连接远程主机的 SFTP 子系统，依次尝试 ssh-agent、~/.ssh 中未加密的默认私钥和 URL 中的密码
+	- 主机密钥必须已记录在 ~/.ssh/known_hosts 中
+*/
+
+package general
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sftpDefaultKeys 未使用 ssh-agent 时尝试的私钥文件，位于 ~/.ssh 中
+var sftpDefaultKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// sftpStorage SFTP 存储
+type sftpStorage struct {
+	client  *sftp.Client
+	conn    io.Closer // ssh 连接，关闭存储时一并断开
+	root    string    // 存储位置在远程主机上的绝对路径
+	display url.URL   // 用于显示的存储位置
+}
+
+// newSFTPStorage 连接 SFTP 服务
+//
+// 参数：
+//   - locationURL: 'sftp://user@host:port/path'，'/~/path' 表示用户主目录下的路径
+//
+// 返回：
+//   - 存储
+//   - 错误信息
+func newSFTPStorage(locationURL *url.URL) (*sftpStorage, error) {
+	config, err := sftpClientConfig(locationURL)
+	if err != nil {
+		return nil, fmt.Errorf("sftp %s: %w", locationURL.Host, err)
+	}
+	port := locationURL.Port()
+	if port == "" {
+		port = "22"
+	}
+	address := net.JoinHostPort(locationURL.Hostname(), port)
+	config.HostKeyAlgorithms = knownHostKeyAlgorithms(config.HostKeyCallback, address)
+
+	conn, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return nil, fmt.Errorf("sftp %s: %w", locationURL.Host, err)
+	}
+	client, err := sftp.NewClient(conn, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("sftp %s: %w", locationURL.Host, err)
+	}
+	return openSFTPStorage(client, conn, locationURL)
+}
+
+// openSFTPStorage 在已建立的 SFTP 会话上打开存储位置
+//
+// 参数：
+//   - client: SFTP 客户端
+//   - conn: 承载会话的连接，关闭存储时一并断开，可以为 nil
+//   - locationURL: 存储位置
+//
+// 返回：
+//   - 存储
+//   - 错误信息
+func openSFTPStorage(client *sftp.Client, conn io.Closer, locationURL *url.URL) (*sftpStorage, error) {
+	storage := &sftpStorage{
+		client:  client,
+		conn:    conn,
+		display: url.URL{Scheme: locationURL.Scheme, User: locationURL.User, Host: locationURL.Host, Path: locationURL.Path},
+	}
+	// 不显示 URL 中的密码
+	if locationURL.User != nil {
+		storage.display.User = url.User(locationURL.User.Username())
+	}
+
+	// 解析存储位置，'~' 开头的路径相对于用户主目录
+	root := locationURL.Path
+	if relative, found := strings.CutPrefix(strings.TrimPrefix(root, "/"), "~"); found || root == "" {
+		home, err := client.Getwd()
+		if err != nil {
+			storage.Close()
+			return nil, err
+		}
+		root = path.Join(home, relative)
+	}
+	storage.root = path.Clean(root)
+	if err := storage.MakeFolder(""); err != nil {
+		storage.Close()
+		return nil, err
+	}
+	return storage, nil
+}
+
+// sftpClientConfig 生成 ssh 客户端配置
+//
+// 参数：
+//   - locationURL: 存储位置，提供用户名和密码
+//
+// 返回：
+//   - ssh 客户端配置
+//   - 错误信息
+func sftpClientConfig(locationURL *url.URL) (*ssh.ClientConfig, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, err
+	}
+
+	userName := ""
+	if locationURL.User != nil {
+		userName = locationURL.User.Username()
+	}
+	if userName == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		userName = current.Username
+	}
+
+	var methods []ssh.AuthMethod
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	var signers []ssh.Signer
+	for _, keyName := range sftpDefaultKeys {
+		content, err := os.ReadFile(filepath.Join(home, ".ssh", keyName))
+		if err != nil {
+			continue
+		}
+		// 有密码的私钥需要先加入 ssh-agent
+		if signer, err := ssh.ParsePrivateKey(content); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	if locationURL.User != nil {
+		if password, ok := locationURL.User.Password(); ok {
+			methods = append(methods, ssh.Password(password))
+		}
+	}
+
+	return &ssh.ClientConfig{User: userName, Auth: methods, HostKeyCallback: hostKeyCallback}, nil
+}
+
+// knownHostKeyAlgorithms 返回 known_hosts 中已记录的主机密钥对应的算法
+//
+//   - 不指定算法时服务端可能选择未记录的密钥类型，导致主机密钥校验失败
+//
+// 参数：
+//   - callback: 校验 known_hosts 的函数
+//   - address: 'host:port'
+//
+// 返回：
+//   - 算法列表，没有记录时为空，由 ssh 使用默认算法
+func knownHostKeyAlgorithms(callback ssh.HostKeyCallback, address string) []string {
+	// 使用一个不可能已记录的密钥查询，KeyError 中列出已记录的密钥
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if !errors.As(callback(address, &net.TCPAddr{IP: net.IPv4zero}, signer.PublicKey()), &keyErr) {
+		return nil
+	}
+
+	var algorithms []string
+	for _, known := range keyErr.Want {
+		switch keyType := known.Key.Type(); keyType {
+		case ssh.KeyAlgoRSA:
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		default:
+			algorithms = append(algorithms, keyType)
+		}
+	}
+	return algorithms
+}
+
+// remotePath 返回存储中的路径对应的远程绝对路径
+func (storage *sftpStorage) remotePath(name string) string {
+	return joinStoragePath(storage.root, name)
+}
+
+// pathError 为错误加上显示位置，文件不存在时满足 errors.Is(err, fs.ErrNotExist)
+func (storage *sftpStorage) pathError(operation string, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &fs.PathError{Op: "sftp " + operation, Path: storage.Location(name), Err: err}
+}
+
+func (storage *sftpStorage) Put(name string, content io.Reader) error {
+	if parent := path.Dir(storagePath(name)); parent != "." {
+		if err := storage.MakeFolder(parent); err != nil {
+			return err
+		}
+	}
+	target := storage.remotePath(name)
+	temp := path.Join(path.Dir(target), "."+path.Base(target)+".tmp")
+
+	// 先写入临时文件，完成后再替换
+	file, err := storage.client.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return storage.pathError("create", name, err)
+	}
+	if _, err := file.ReadFrom(content); err != nil {
+		file.Close()
+		storage.client.Remove(temp)
+		return storage.pathError("write", name, err)
+	}
+	if err := file.Close(); err != nil {
+		storage.client.Remove(temp)
+		return storage.pathError("write", name, err)
+	}
+	if err := storage.replace(temp, target); err != nil {
+		storage.client.Remove(temp)
+		return storage.pathError("rename", name, err)
+	}
+	return nil
+}
+
+// replace 用 oldPath 替换 newPath，服务端支持 posix-rename 扩展时原子替换，否则先删除目标
+func (storage *sftpStorage) replace(oldPath string, newPath string) error {
+	if _, ok := storage.client.HasExtension("posix-rename@openssh.com"); ok {
+		return storage.client.PosixRename(oldPath, newPath)
+	}
+	if err := storage.client.Remove(newPath); err != nil && !isNotExist(err) {
+		return err
+	}
+	return storage.client.Rename(oldPath, newPath)
+}
+
+func (storage *sftpStorage) Get(name string) (io.ReadCloser, error) {
+	entry, err := storage.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir {
+		return nil, fmt.Errorf("sftp %s: is a folder", storage.Location(name))
+	}
+	file, err := storage.client.Open(storage.remotePath(name))
+	if err != nil {
+		return nil, storage.pathError("open", name, err)
+	}
+	return file, nil
+}
+
+func (storage *sftpStorage) Stat(name string) (StorageEntry, error) {
+	info, err := storage.client.Stat(storage.remotePath(name))
+	if err != nil {
+		return StorageEntry{}, storage.pathError("stat", name, err)
+	}
+	return StorageEntry{Name: storagePath(name), Size: info.Size(), IsDir: info.IsDir(), ModTime: info.ModTime()}, nil
+}
+
+func (storage *sftpStorage) List(folder string) ([]StorageEntry, error) {
+	infos, err := storage.client.ReadDir(storage.remotePath(folder))
+	if err != nil {
+		return nil, storage.pathError("list", folder, err)
+	}
+	var entries []StorageEntry
+	for _, info := range infos {
+		entries = append(entries, StorageEntry{
+			Name:    path.Join(storagePath(folder), info.Name()),
+			Size:    info.Size(),
+			IsDir:   info.IsDir(),
+			ModTime: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+func (storage *sftpStorage) MakeFolder(name string) error {
+	return storage.pathError("mkdir", name, storage.client.MkdirAll(storage.remotePath(name)))
+}
+
+func (storage *sftpStorage) Rename(oldName string, newName string) error {
+	return storage.pathError("rename", oldName, storage.client.Rename(storage.remotePath(oldName), storage.remotePath(newName)))
+}
+
+func (storage *sftpStorage) Remove(name string) error {
+	return storage.pathError("remove", name, storage.client.RemoveAll(storage.remotePath(name)))
+}
+
+func (storage *sftpStorage) Location(name string) string {
+	location := storage.display
+	location.Path = path.Join("/", storage.display.Path, storagePath(name))
+	return location.String()
+}
+
+func (storage *sftpStorage) Close() error {
+	err := storage.client.Close()
+	if storage.conn != nil {
+		storage.conn.Close()
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+/*
+File: define_storage_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 14:16:05
+
+Description: 存储测试，所有后端共用同一组检查
+*/
+
+package general
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readStorageFile 读取存储中的文件
+func readStorageFile(t *testing.T, storage Storage, name string) string {
+	t.Helper()
+	reader, err := storage.Get(name)
+	if err != nil {
+		t.Fatalf("Get(%q): %v", name, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Get(%q): %v", name, err)
+	}
+	return string(content)
+}
+
+// listStorageNames 列出存储中文件夹的直接子条目名
+func listStorageNames(t *testing.T, storage Storage, folder string) []string {
+	t.Helper()
+	entries, err := storage.List(folder)
+	if err != nil {
+		t.Fatalf("List(%q): %v", folder, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// testStorage 检查存储是否满足 Storage 接口的约定
+func testStorage(t *testing.T, storage Storage) {
+	// 写入时自动创建上级文件夹，覆盖已有文件，不留下临时文件
+	content := strings.Repeat("wocker", 20000)
+	if err := storage.Put("set/app.dockerimage", strings.NewReader("old")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := storage.Put("set/app.dockerimage", strings.NewReader(content)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := readStorageFile(t, storage, "set/app.dockerimage"); got != content {
+		t.Fatalf("Get returned %d bytes, want %d", len(got), len(content))
+	}
+	if names := listStorageNames(t, storage, "set"); len(names) != 1 || names[0] != "set/app.dockerimage" {
+		t.Fatalf("List(set) = %v, want [set/app.dockerimage]", names)
+	}
+
+	entry, err := storage.Stat("set/app.dockerimage")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if entry.IsDir || entry.Size != int64(len(content)) || entry.Name != "set/app.dockerimage" {
+		t.Errorf("Stat(set/app.dockerimage) = %+v", entry)
+	}
+	if entry, err := storage.Stat("set"); err != nil || !entry.IsDir {
+		t.Errorf("Stat(set) = %+v, %v, want a folder", entry, err)
+	}
+
+	// 路径不能跳出存储位置
+	if entry, err := storage.Stat("../set/app.dockerimage"); err != nil || entry.Name != "set/app.dockerimage" {
+		t.Errorf("Stat(../set/app.dockerimage) = %+v, %v", entry, err)
+	}
+
+	// 重命名文件夹
+	if err := storage.MakeFolder("archive/2026"); err != nil {
+		t.Fatalf("MakeFolder: %v", err)
+	}
+	if err := storage.Rename("set", "archive/2026/set"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if got := readStorageFile(t, storage, "archive/2026/set/app.dockerimage"); got != content {
+		t.Errorf("Get after Rename returned %d bytes, want %d", len(got), len(content))
+	}
+
+	// 不存在的路径
+	for _, name := range []string{"set", "set/app.dockerimage", "missing"} {
+		if _, err := storage.Stat(name); !isNotExist(err) {
+			t.Errorf("Stat(%q) error = %v, want not exist", name, err)
+		}
+	}
+	if _, err := storage.Get("missing"); !isNotExist(err) {
+		t.Errorf("Get(missing) error = %v, want not exist", err)
+	}
+	if _, err := storage.List("missing"); !isNotExist(err) {
+		t.Errorf("List(missing) error = %v, want not exist", err)
+	}
+
+	// 递归删除文件夹
+	if err := storage.Remove("archive"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := storage.Stat("archive"); !isNotExist(err) {
+		t.Errorf("Stat(archive) after Remove error = %v, want not exist", err)
+	}
+	if names := listStorageNames(t, storage, ""); len(names) != 0 {
+		t.Errorf("List() after Remove = %v, want nothing", names)
+	}
+
+	if location := storage.Location("set/app.dockerimage"); !strings.HasSuffix(filepath.ToSlash(location), "/set/app.dockerimage") {
+		t.Errorf("Location = %s", location)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	storage, err := OpenStorage(filepath.Join(t.TempDir(), "backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+	testStorage(t, storage)
+}
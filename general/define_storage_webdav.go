@@ -0,0 +1,305 @@
+/*
+File: define_storage_webdav.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 23:58:21
+
+Description: WebDAV 存储
+*/
+
+package general
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// webdavPasswordEnv 未在 URL 中指定密码时读取的环境变量
+const webdavPasswordEnv = "WOCKER_WEBDAV_PASSWORD"
+
+// webdavStorage WebDAV 存储
+type webdavStorage struct {
+	client   *http.Client
+	base     *url.URL  // 存储位置对应的 http(s) 地址，路径以 '/' 结尾
+	user     *url.URL  // 用于显示的 URL，不包含密码
+	username string    // 用户名
+	password string    // 密码
+	now      time.Time // 打开存储的时间，无法解析修改时间时使用
+}
+
+// newWebDAVStorage 连接 WebDAV 服务
+//
+// 参数：
+//   - locationURL: 'webdav://user@host/path' 或 'webdavs://user@host/path'，都使用 https，'webdav://' 在配置项 webdav_insecure 开启时使用 http
+//
+// 返回：
+//   - 存储
+//   - 错误信息
+func newWebDAVStorage(locationURL *url.URL) (*webdavStorage, error) {
+	// 密码以 Basic 认证发送，只有明确允许时才使用 http
+	base := &url.URL{Scheme: "https", Host: locationURL.Host, Path: strings.TrimSuffix(locationURL.Path, "/") + "/"}
+	if locationURL.Scheme == "webdav" && CurrentSettings.WebDAVInsecure {
+		base.Scheme = "http"
+	}
+	storage := &webdavStorage{
+		// 不自动跟随重定向，否则 PROPFIND 等请求会被改为 GET
+		client: &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }},
+		base:   base,
+		user:   &url.URL{Scheme: locationURL.Scheme, Host: locationURL.Host, Path: base.Path},
+		now:    time.Now(),
+	}
+	if locationURL.User != nil {
+		storage.username = locationURL.User.Username()
+		storage.user.User = url.User(storage.username)
+		storage.password, _ = locationURL.User.Password()
+	}
+	if storage.password == "" {
+		storage.password = os.Getenv(webdavPasswordEnv)
+	}
+
+	// 确认存储位置可以访问
+	if err := storage.MakeFolder(""); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+// url 返回存储中的路径对应的 http(s) 地址
+func (storage *webdavStorage) url(name string, folder bool) string {
+	target := *storage.base
+	target.Path = path.Join(storage.base.Path, storagePath(name))
+	if folder && !strings.HasSuffix(target.Path, "/") {
+		target.Path += "/"
+	}
+	return target.String()
+}
+
+// request 发送请求，状态码不在 expected 中时返回错误并关闭响应
+//
+// 参数：
+//   - method: 请求方法
+//   - name: 存储中的路径
+//   - folder: 路径是否是文件夹
+//   - body: 请求体
+//   - headers: 请求头
+//   - expected: 表示成功的状态码
+//
+// 返回：
+//   - 响应，使用完毕后需要关闭
+//   - 错误信息，路径不存在时满足 errors.Is(err, fs.ErrNotExist)
+func (storage *webdavStorage) request(method string, name string, folder bool, body io.Reader, headers map[string]string, expected ...int) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, storage.url(name, folder), body)
+	if err != nil {
+		return nil, err
+	}
+	if storage.username != "" || storage.password != "" {
+		request.SetBasicAuth(storage.username, storage.password)
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := storage.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	// 访问文件夹时缺少结尾的 '/' 会被重定向，加上 '/' 后重试，只有无请求体和 PROPFIND 请求可以重新发送
+	redirected := response.StatusCode >= 300 && response.StatusCode < 400 && response.StatusCode != http.StatusNotModified
+	if redirected && !folder && (body == nil || method == "PROPFIND") {
+		response.Body.Close()
+		if method == "PROPFIND" {
+			body = strings.NewReader(propfindBody)
+		}
+		return storage.request(method, name, true, body, headers, expected...)
+	}
+	for _, code := range expected {
+		if response.StatusCode == code {
+			return response, nil
+		}
+	}
+	response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, &fs.PathError{Op: strings.ToLower(method), Path: storage.Location(name), Err: fs.ErrNotExist}
+	}
+	return nil, fmt.Errorf("%s %s: %s", method, storage.Location(name), response.Status)
+}
+
+func (storage *webdavStorage) Put(name string, content io.Reader) error {
+	if parent := path.Dir(storagePath(name)); parent != "." {
+		if err := storage.MakeFolder(parent); err != nil {
+			return err
+		}
+	}
+	// 先上传到临时文件，完成后再移动到目标路径
+	tempName := path.Join(path.Dir(storagePath(name)), "."+path.Base(storagePath(name))+".tmp")
+	response, err := storage.request(http.MethodPut, tempName, false, content, nil, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		storage.Remove(tempName)
+		return err
+	}
+	response.Body.Close()
+
+	headers := map[string]string{"Destination": storage.url(name, false), "Overwrite": "T"}
+	response, err = storage.request("MOVE", tempName, false, nil, headers, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		storage.Remove(tempName)
+		return err
+	}
+	return response.Body.Close()
+}
+
+func (storage *webdavStorage) Get(name string) (io.ReadCloser, error) {
+	response, err := storage.request(http.MethodGet, name, false, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// propfindBody PROPFIND 请求的属性
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><getcontentlength/><getlastmodified/><getetag/><resourcetype/></prop></propfind>`
+
+// webdavMultistatus PROPFIND 的响应
+type webdavMultistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+		Prop struct {
+			ContentLength int64     `xml:"getcontentlength"`
+			LastModified  string    `xml:"getlastmodified"`
+			ETag          string    `xml:"getetag"`
+			Collection    *xml.Name `xml:"resourcetype>collection"`
+		} `xml:"propstat>prop"`
+	} `xml:"response"`
+}
+
+// propfind 获取路径及其直接子条目的属性
+//
+// 参数：
+//   - name: 存储中的路径
+//   - depth: '0' 只获取路径本身，'1' 同时获取直接子条目
+//
+// 返回：
+//   - 条目，第一个为路径本身
+//   - 错误信息
+func (storage *webdavStorage) propfind(name string, depth string) ([]StorageEntry, error) {
+	headers := map[string]string{"Depth": depth, "Content-Type": "application/xml"}
+	response, err := storage.request("PROPFIND", name, false, strings.NewReader(propfindBody), headers, http.StatusMultiStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var multistatus webdavMultistatus
+	if err := xml.NewDecoder(response.Body).Decode(&multistatus); err != nil {
+		return nil, err
+	}
+
+	var entries []StorageEntry
+	for _, item := range multistatus.Responses {
+		href, err := url.Parse(item.Href)
+		if err != nil {
+			return nil, err
+		}
+		// href 可能是完整地址，也可能只有路径
+		relative, found := strings.CutPrefix(strings.TrimSuffix(href.Path, "/")+"/", storage.base.Path)
+		if !found {
+			continue
+		}
+		modTime, err := http.ParseTime(item.Prop.LastModified)
+		if err != nil {
+			modTime = storage.now
+		}
+		entries = append(entries, StorageEntry{
+			Name:    storagePath(relative),
+			Size:    item.Prop.ContentLength,
+			IsDir:   item.Prop.Collection != nil,
+			ModTime: modTime,
+			ETag:    item.Prop.ETag,
+		})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("PROPFIND %s: empty response", storage.Location(name))
+	}
+	return entries, nil
+}
+
+func (storage *webdavStorage) Stat(name string) (StorageEntry, error) {
+	entries, err := storage.propfind(name, "0")
+	if err != nil {
+		return StorageEntry{}, err
+	}
+	return entries[0], nil
+}
+
+func (storage *webdavStorage) List(folder string) ([]StorageEntry, error) {
+	entries, err := storage.propfind(folder, "1")
+	if err != nil {
+		return nil, err
+	}
+	// 跳过文件夹本身
+	var children []StorageEntry
+	for _, entry := range entries {
+		if entry.Name != storagePath(folder) {
+			children = append(children, entry)
+		}
+	}
+	return children, nil
+}
+
+func (storage *webdavStorage) MakeFolder(name string) error {
+	// 逐级创建，已存在的文件夹返回 405
+	current := ""
+	for _, part := range strings.Split(storagePath(name), "/") {
+		current = path.Join(current, part)
+		response, err := storage.request("MKCOL", current, true, nil, nil, http.StatusCreated, http.StatusMethodNotAllowed)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+	}
+	return nil
+}
+
+func (storage *webdavStorage) Rename(oldName string, newName string) error {
+	entry, err := storage.Stat(oldName)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{"Destination": storage.url(newName, entry.IsDir), "Overwrite": "F"}
+	response, err := storage.request("MOVE", oldName, entry.IsDir, nil, headers, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+func (storage *webdavStorage) Remove(name string) error {
+	entry, err := storage.Stat(name)
+	if err != nil {
+		return err
+	}
+	response, err := storage.request(http.MethodDelete, name, entry.IsDir, nil, nil, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+func (storage *webdavStorage) Location(name string) string {
+	location := *storage.user
+	location.Path = path.Join(storage.user.Path, storagePath(name))
+	return location.String()
+}
+
+func (storage *webdavStorage) Close() error {
+	storage.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+File: define_storage_webdav_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 14:48:30
+
+Description: WebDAV 存储测试，连接进程内的 WebDAV 服务
+*/
+
+package general
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+// startWebDAVServer 启动进程内的 http WebDAV 服务，只接受指定的用户名和密码
+//
+// 返回：
+//   - 服务地址，'host:port'
+//   - 收到的带认证信息的请求数
+func startWebDAVServer(t *testing.T, username string, password string) (string, *atomic.Int64) {
+	t.Helper()
+	handler := &webdav.Handler{FileSystem: webdav.Dir(t.TempDir()), LockSystem: webdav.NewMemLS()}
+	authorized := new(atomic.Int64)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		user, pass, ok := request.BasicAuth()
+		if ok {
+			authorized.Add(1)
+		}
+		if !ok || user != username || pass != password {
+			writer.Header().Set("WWW-Authenticate", `Basic realm="wocker"`)
+			http.Error(writer, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://"), authorized
+}
+
+// setWebDAVInsecure 临时修改配置项 webdav_insecure
+func setWebDAVInsecure(t *testing.T, insecure bool) {
+	previous := CurrentSettings.WebDAVInsecure
+	CurrentSettings.WebDAVInsecure = insecure
+	t.Cleanup(func() { CurrentSettings.WebDAVInsecure = previous })
+}
+
+func TestWebDAVStorage(t *testing.T) {
+	setWebDAVInsecure(t, true)
+	host, _ := startWebDAVServer(t, "backup", "secret")
+
+	storage, err := OpenStorage("webdav://backup:secret@" + host + "/backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+	testStorage(t, storage)
+
+	// 文件带有服务端返回的 ETag，分块续传时用于判断分块是否改变
+	if err := storage.Put("etag.txt", strings.NewReader("etag")); err != nil {
+		t.Fatal(err)
+	}
+	if entry, err := storage.Stat("etag.txt"); err != nil || entry.ETag == "" {
+		t.Errorf("Stat = %+v, %v, want an ETag", entry, err)
+	}
+
+	// 显示的位置不包含密码
+	if location := storage.Location("app.dockerimage"); strings.Contains(location, "secret") {
+		t.Errorf("Location = %s, want no password", location)
+	}
+
+	if _, err := OpenStorage("webdav://backup:wrong@" + host + "/backup"); err == nil {
+		t.Error("OpenStorage with a wrong password should fail")
+	}
+}
+
+func TestWebDAVStorageRefusesPlainHTTP(t *testing.T) {
+	setWebDAVInsecure(t, false)
+	host, authorized := startWebDAVServer(t, "backup", "secret")
+
+	// 未开启 webdav_insecure 时使用 https 连接，http 服务无法完成握手，密码不会发出
+	for _, scheme := range []string{"webdav", "webdavs"} {
+		if _, err := OpenStorage(scheme + "://backup:secret@" + host + "/backup"); err == nil {
+			t.Errorf("%s storage connected over plain http", scheme)
+		}
+	}
+	if count := authorized.Load(); count != 0 {
+		t.Errorf("server received %d requests with credentials over plain http", count)
+	}
+}
@@ -0,0 +1,394 @@
+/*
+File: define_transfer.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:02:36
+
+Description: 在两个 docker service 之间直接传输 image 和 volume
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+)
+
+// transferProgressInterval 传输进度的最小报告间隔
+const transferProgressInterval = 500 * time.Millisecond
+
+// HostTransfer 将 image 或 volume 从当前 docker service 传输到目标 docker service
+type HostTransfer struct {
+	target       *client.Client         // 目标 docker 客户端
+	chains       map[digest.Digest]bool // 目标已有的层链 ID，为 nil 时不跳过层
+	chainsLoaded bool                   // 是否已读取目标已有的层链 ID
+}
+
+// TransferResult 单个 image 的传输结果
+type TransferResult struct {
+	Loaded      bool     // 目标 docker service 是否加载成功
+	Layers      int      // image 的层数
+	Skipped     int      // 目标已有而跳过的层数
+	SkippedSize int64    // 跳过的层的大小
+	Sent        int64    // 实际发送的字节数，压缩时为压缩后的大小
+	Messages    []string // 目标 docker service 的返回信息
+}
+
+// NewHostTransfer 连接目标 docker service，准备传输 image 或 volume
+//
+// 参数：
+//   - contextName: 目标 docker context 名
+//   - host: 目标 docker service 地址，不为空时优先于 contextName
+//
+// 返回：
+//   - HostTransfer
+//   - 错误信息
+func NewHostTransfer(contextName string, host string) (*HostTransfer, error) {
+	target, err := newDockerClientFor(contextName, host)
+	if err != nil {
+		return nil, err
+	}
+	return &HostTransfer{target: target}, nil
+}
+
+// Close 关闭与目标 docker service 的连接
+func (transfer *HostTransfer) Close() error {
+	return transfer.target.Close()
+}
+
+// loadChains 读取目标已有 image 的层链 ID
+//
+//   - 目标使用经典存储时，传输 image 时跳过这些层
+//   - 目标使用 containerd 存储时，docker load 依赖存档中的全部 blob，不跳过层
+//
+// 返回：
+//   - 错误信息
+func (transfer *HostTransfer) loadChains() error {
+	if transfer.chainsLoaded {
+		return nil
+	}
+
+	info, err := transfer.target.Info(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range info.DriverStatus {
+		if status[0] == "driver-type" && strings.HasPrefix(status[1], "io.containerd.snapshotter") {
+			transfer.chainsLoaded = true
+			return nil
+		}
+	}
+
+	images, err := transfer.target.ImageList(ctx, image.ListOptions{All: true})
+	if err != nil {
+		return err
+	}
+	transfer.chains = make(map[digest.Digest]bool)
+	for _, summary := range images {
+		inspect, _, err := transfer.target.ImageInspectWithRaw(ctx, summary.ID)
+		if err != nil {
+			continue
+		}
+		transfer.addChains(inspect.RootFS.Layers)
+	}
+	transfer.chainsLoaded = true
+
+	return nil
+}
+
+// addChains 记录一组层对应的层链 ID
+//
+// 参数：
+//   - layers: 按顺序排列的层 diff ID
+func (transfer *HostTransfer) addChains(layers []string) {
+	if transfer.chains == nil {
+		return
+	}
+	diffIDs := make([]digest.Digest, 0, len(layers))
+	for _, layer := range layers {
+		diffIDs = append(diffIDs, digest.Digest(layer))
+	}
+	for _, chainID := range identity.ChainIDs(diffIDs) {
+		transfer.chains[chainID] = true
+	}
+}
+
+// skippableLayers 计算目标已有、可以不传输内容的层
+//
+//   - docker load 在层链已存在时不会读取层文件，因此只有每次出现时层链都已存在的层才能跳过
+//
+// 参数：
+//   - layers: 按顺序排列的层 diff ID
+//
+// 返回：
+//   - 可跳过的层在存档中的路径
+func (transfer *HostTransfer) skippableLayers(layers []string) map[string]bool {
+	skippable := make(map[string]bool)
+	if transfer.chains == nil {
+		return skippable
+	}
+	diffIDs := make([]digest.Digest, 0, len(layers))
+	for _, layer := range layers {
+		diffIDs = append(diffIDs, digest.Digest(layer))
+	}
+	needed := make(map[string]bool)
+	for index, chainID := range identity.ChainIDs(diffIDs) {
+		layerPath := blobPath(diffIDs[index])
+		if transfer.chains[chainID] && !needed[layerPath] {
+			skippable[layerPath] = true
+		} else {
+			needed[layerPath] = true
+			delete(skippable, layerPath)
+		}
+	}
+	return skippable
+}
+
+// skipResult 替换已有的层的结果
+type skipResult struct {
+	files int   // 替换的层数
+	size  int64 // 替换的层的总大小
+	err   error
+}
+
+// TransferImage 将指定 image 从当前 docker service 传输到目标 docker service
+//
+//   - 功能与命令 `docker save <imageName> | docker --context <target> load` 一样，不产生中间文件
+//   - 目标已有的层在存档流中替换为空文件
+//
+// 参数：
+//   - imageName: image 的 Repository(:Tag) 或 ID
+//   - compress: 是否使用 gzip 压缩传输的数据
+//   - progress: 传输进度回调，参数为已发送的字节数，允许为 nil
+//
+// 返回：
+//   - 传输结果
+//   - 错误信息
+func (transfer *HostTransfer) TransferImage(imageName string, compress bool, progress func(sent int64)) (TransferResult, error) {
+	var result TransferResult
+
+	if err := transfer.loadChains(); err != nil {
+		return result, err
+	}
+	inspect, _, err := docker.ImageInspectWithRaw(ctx, imageName)
+	if err != nil {
+		return result, err
+	}
+	result.Layers = len(inspect.RootFS.Layers)
+	skippable := transfer.skippableLayers(inspect.RootFS.Layers)
+
+	source, err := saveImage(docker, imageName, "")
+	if err != nil {
+		return result, err
+	}
+	defer source.Close()
+
+	// 替换已有的层的 goroutine 通过 skipDone 返回结果，没有可跳过的层时为 nil
+	var (
+		stream     io.Reader = source
+		pipeReader *io.PipeReader
+		skipDone   chan skipResult
+	)
+	if len(skippable) > 0 {
+		var pipeWriter *io.PipeWriter
+		pipeReader, pipeWriter = io.Pipe()
+		skipDone = make(chan skipResult, 1)
+		go func() {
+			var result skipResult
+			result.files, result.size, result.err = skipTarFiles(source, pipeWriter, skippable)
+			pipeWriter.CloseWithError(result.err)
+			skipDone <- result
+		}()
+		stream = pipeReader
+	}
+	if compress {
+		compressed := gzipStream(stream)
+		defer compressed.Close()
+		stream = compressed
+	}
+
+	counter := &progressReader{reader: stream, report: progress}
+	loaded, messages, err := loadImage(transfer.target, counter)
+	result.Loaded, result.Sent, result.Messages = loaded, counter.count, messages
+
+	// 等待替换已有的层的 goroutine 结束，目标提前结束读取时关闭管道使其退出
+	if skipDone != nil {
+		pipeReader.Close()
+		skip := <-skipDone
+		if err == nil && skip.err != nil {
+			err = skip.err
+		}
+		result.Skipped, result.SkippedSize = skip.files, skip.size
+	}
+	if err != nil {
+		return result, err
+	}
+	if loaded {
+		transfer.addChains(inspect.RootFS.Layers)
+	}
+
+	return result, nil
+}
+
+// MigrateVolume 将指定 volume 从当前 docker service 迁移到目标 docker service
+//
+//   - 在目标上按源 volume 的驱动和标签创建新 volume，驱动选项通常与主机相关，不复制
+//   - 数据从源的临时容器经由 docker API 直接写入目标的临时容器，不产生中间文件
+//   - 完成后分别在两端计算清单并比较每个条目的类型、大小、权限和 sha256
+//
+// 参数：
+//   - sourceVolume: 源 volume 名
+//   - targetVolume: 目标 volume 名，不能已存在
+//   - compress: 是否使用 gzip 压缩传输的数据
+//   - progress: 传输进度回调，参数为已发送的字节数，允许为 nil
+//
+// 返回：
+//   - 已发送的字节数
+//   - 错误信息，校验不一致时同样返回错误
+func (transfer *HostTransfer) MigrateVolume(sourceVolume string, targetVolume string, compress bool, progress func(sent int64)) (int64, error) {
+	template, err := docker.VolumeInspect(ctx, sourceVolume)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := transfer.target.VolumeInspect(ctx, targetVolume); err == nil {
+		return 0, errors.New(VolumeExistMessage)
+	}
+	if _, err := transfer.target.VolumeCreate(ctx, volume.CreateOptions{
+		Name:   targetVolume,
+		Driver: template.Driver,
+		Labels: template.Labels,
+	}); err != nil {
+		return 0, err
+	}
+
+	// 传输失败时删除不完整的目标 volume
+	source, err := copyFromVolume(docker, sourceVolume)
+	if err != nil {
+		transfer.target.VolumeRemove(ctx, targetVolume, true)
+		return 0, err
+	}
+	defer source.Close()
+
+	var stream io.Reader = source
+	if compress {
+		compressed := gzipStream(stream)
+		defer compressed.Close()
+		stream = compressed
+	}
+	counter := &progressReader{reader: stream, report: progress}
+	if err := copyToVolume(transfer.target, targetVolume, counter); err != nil {
+		transfer.target.VolumeRemove(ctx, targetVolume, true)
+		return counter.count, err
+	}
+
+	// 校验两端的内容
+	sourceFiles, err := volumeManifest(docker, sourceVolume)
+	if err != nil {
+		return counter.count, err
+	}
+	targetFiles, err := volumeManifest(transfer.target, targetVolume)
+	if err != nil {
+		return counter.count, err
+	}
+	if changes := DiffFiles(sourceFiles, targetFiles); len(changes) > 0 {
+		return counter.count, fmt.Errorf("%s: %d files, first %s", VerifyFailedMessage, len(changes), changes[0].Path)
+	}
+
+	return counter.count, nil
+}
+
+// gzipStream 在传输过程中使用 gzip 压缩数据流
+//
+//   - docker load 和 docker cp 都能识别压缩后的 tar 流
+//
+// 参数：
+//   - reader: 原数据流
+//
+// 返回：
+//   - 压缩后的数据流，使用完毕后需要关闭
+func gzipStream(reader io.Reader) io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		gzipWriter, _ := gzip.NewWriterLevel(pipeWriter, gzip.BestSpeed)
+		if _, err := io.Copy(gzipWriter, reader); err != nil {
+			pipeWriter.CloseWithError(err)
+			return
+		}
+		pipeWriter.CloseWithError(gzipWriter.Close())
+	}()
+	return pipeReader
+}
+
+// skipTarFiles 复制 tar 流，将指定文件替换为空文件
+//
+// 参数：
+//   - reader: 原 tar 流
+//   - writer: 新 tar 流
+//   - skip: 需要替换的文件路径
+//
+// 返回：
+//   - 替换的文件数
+//   - 替换的文件的原大小
+//   - 错误信息
+func skipTarFiles(reader io.Reader, writer io.Writer, skip map[string]bool) (int, int64, error) {
+	var (
+		count int
+		size  int64
+	)
+	tarReader := tar.NewReader(reader)
+	tarWriter := tar.NewWriter(writer)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, size, err
+		}
+		if header.Typeflag == tar.TypeReg && skip[CleanArchivePath(header.Name)] {
+			count++
+			size += header.Size
+			header.Size = 0
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return count, size, err
+			}
+			continue
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return count, size, err
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return count, size, err
+		}
+	}
+	return count, size, tarWriter.Close()
+}
+
+// progressReader 统计读取的字节数并定期报告
+type progressReader struct {
+	reader io.Reader        // 原数据流
+	report func(sent int64) // 进度回调
+	count  int64            // 已读取的字节数
+	last   time.Time        // 上次报告的时间
+}
+
+func (reader *progressReader) Read(data []byte) (int, error) {
+	length, err := reader.reader.Read(data)
+	reader.count += int64(length)
+	if reader.report != nil && (err != nil || time.Since(reader.last) >= transferProgressInterval) {
+		reader.last = time.Now()
+		reader.report(reader.count)
+	}
+	return length, err
+}
@@ -0,0 +1,216 @@
+/*
+File: define_volume.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 14:41:26
+
+Description: 在 volume 之间复制数据
+*/
+
+package general
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/gookit/color"
+)
+
+const (
+	sourcePathInContainer = "/source" // 源 volume 在容器中的挂载路径
+	targetPathInContainer = "/target" // 目标 volume 在容器中的挂载路径
+)
+
+// manifestScript 输出 "$1" 下所有条目的清单，按路径排序
+//
+//   - 每行一个条目，以制表符分隔：类型、路径、大小、权限、sha256（符号链接为链接目标）
+//   - 类型为 file、dir、link 或 other，只有普通文件计算 sha256
+const manifestScript = `manifest() {
+	cd "$1" && find . -print | sort | while IFS= read -r name; do
+		if [ -L "$name" ]; then
+			printf 'link\t%s\t0\t777\t%s\n' "$name" "$(readlink "$name")"
+		elif [ -d "$name" ]; then
+			printf 'dir\t%s\t0\t%s\t\n' "$name" "$(stat -c %a "$name")"
+		elif [ -f "$name" ]; then
+			printf 'file\t%s\t%s\t%s\n' "$name" "$(stat -c '%s %a' "$name" | tr ' ' '\t')" "$(sha256sum "$name" | cut -d ' ' -f 1)"
+		else
+			printf 'other\t%s\t0\t%s\t\n' "$name" "$(stat -c %a "$name")"
+		fi
+	done
+}`
+
+// InspectVolume 获取 volume 的详细信息
+//
+//   - 功能与命令 `docker volume inspect <volumeName>` 一样
+//
+// 参数：
+//   - volumeName: volume 名
+//
+// 返回：
+//   - volume 信息
+//   - 错误信息
+func InspectVolume(volumeName string) (volume.Volume, error) {
+	return docker.VolumeInspect(ctx, volumeName)
+}
+
+// CreateVolumeLike 按已有 volume 的驱动、标签和选项创建新 volume
+//
+// 参数：
+//   - newVolumeName: 要创建的 volume 名
+//   - template: 作为模板的 volume 信息
+//
+// 返回：
+//   - 错误信息
+func CreateVolumeLike(newVolumeName string, template volume.Volume) error {
+	_, err := docker.VolumeCreate(ctx, volume.CreateOptions{
+		Name:       newVolumeName,
+		Driver:     template.Driver,
+		DriverOpts: template.Options,
+		Labels:     template.Labels,
+	})
+	return err
+}
+
+// CopyVolume 将源 volume 的全部内容复制到目标 volume，数据在临时容器中直接传递，不产生中间存档
+//
+//   - 功能与命令 `docker run --rm -v <sourceVolume>:/source:ro -v <targetVolume>:/target busybox cp -a /source/. /target/` 一样
+//
+// 参数：
+//   - sourceVolume: 源 volume 名
+//   - targetVolume: 目标 volume 名
+//
+// 返回：
+//   - 错误信息
+func CopyVolume(sourceVolume string, targetVolume string) error {
+	containerConfig := &container.Config{
+		Image: CurrentSettings.HelperImage,
+		// 保留所有者、权限、时间戳和符号链接
+		Cmd: []string{"cp", "-a", sourcePathInContainer + "/.", targetPathInContainer + "/"},
+	}
+	hostConfig := &container.HostConfig{
+		AutoRemove: true,
+		Binds: []string{
+			color.Sprintf("%s:%s:ro", sourceVolume, sourcePathInContainer),
+			color.Sprintf("%s:%s", targetVolume, targetPathInContainer),
+		},
+	}
+
+	return runHelperContainer(containerConfig, hostConfig)
+}
+
+// VerifyVolumeCopy 校验两个 volume 的内容是否一致
+//
+//   - 比较每个条目的路径、类型、大小、权限和 sha256
+//
+// 参数：
+//   - sourceVolume: 源 volume 名
+//   - targetVolume: 目标 volume 名
+//
+// 返回：
+//   - 错误信息，内容不一致时同样返回错误
+func VerifyVolumeCopy(sourceVolume string, targetVolume string) error {
+	script := manifestScript + `
+manifest "$0" > /tmp/source && manifest "$1" > /tmp/target && cmp /tmp/source /tmp/target`
+
+	containerConfig := &container.Config{
+		Image: CurrentSettings.HelperImage,
+		Cmd:   []string{"sh", "-c", script, sourcePathInContainer, targetPathInContainer},
+	}
+	hostConfig := &container.HostConfig{
+		AutoRemove: true,
+		Binds: []string{
+			color.Sprintf("%s:%s:ro", sourceVolume, sourcePathInContainer),
+			color.Sprintf("%s:%s:ro", targetVolume, targetPathInContainer),
+		},
+	}
+
+	if err := runHelperContainer(containerConfig, hostConfig); err != nil {
+		return fmt.Errorf("%s: %w", VerifyFailedMessage, err)
+	}
+	return nil
+}
+
+// VolumeManifest 在临时容器中遍历 volume，获取所有条目的清单
+//
+//   - 在 docker service 所在主机上计算 sha256，不传输文件内容
+//
+// 参数：
+//   - volumeName: volume 名
+//
+// 返回：
+//   - 文件系统，键为规范化后的路径
+//   - 错误信息
+func VolumeManifest(volumeName string) (map[string]FileEntry, error) {
+	return volumeManifest(docker, volumeName)
+}
+
+// volumeManifest 使用指定客户端获取 volume 所有条目的清单
+//
+// 参数：
+//   - dockerClient: docker 客户端
+//   - volumeName: volume 名
+//
+// 返回：
+//   - 文件系统，键为规范化后的路径
+//   - 错误信息
+func volumeManifest(dockerClient *client.Client, volumeName string) (map[string]FileEntry, error) {
+	script := manifestScript + `
+manifest "$0"`
+
+	containerConfig := &container.Config{
+		Image: CurrentSettings.HelperImage,
+		Cmd:   []string{"sh", "-c", script, sourcePathInContainer},
+	}
+	hostConfig := &container.HostConfig{
+		Binds: []string{color.Sprintf("%s:%s:ro", volumeName, sourcePathInContainer)},
+	}
+
+	output, err := runHelperContainerOutput(dockerClient, containerConfig, hostConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseManifest(output), nil
+}
+
+// parseManifest 解析 manifestScript 输出的清单
+//
+// 参数：
+//   - output: 清单内容
+//
+// 返回：
+//   - 文件系统，键为规范化后的路径
+func parseManifest(output []byte) map[string]FileEntry {
+	files := make(map[string]FileEntry)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			continue
+		}
+		name := CleanArchivePath(fields[1])
+		if name == "" {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[2], 10, 64)
+		mode, _ := strconv.ParseUint(fields[3], 8, 32)
+		entry := FileEntry{Size: size, Mode: os.FileMode(mode).Perm()}
+		switch fields[0] {
+		case "file":
+			entry.Type, entry.Checksum = tar.TypeReg, fields[4]
+		case "dir":
+			entry.Type = tar.TypeDir
+		case "link":
+			entry.Type, entry.Linkname = tar.TypeSymlink, fields[4]
+		default:
+			entry.Type = specialFileType
+		}
+		files[name] = entry
+	}
+	return files
+}